@@ -9,20 +9,46 @@ type Material struct {
 	Ambient  mgl32.Vec4
 	Diffuse  mgl32.Vec4
 	Specular mgl32.Vec4
-
-	AmbientMap  *Texture
-	DiffuseMap  *Texture
-	SpecularMap *Texture
+	Emissive mgl32.Vec4
+
+	Shininess      float32
+	Opacity        float32
+	BumpMultiplier float32
+
+	AmbientMap           *Texture
+	DiffuseMap           *Texture
+	SpecularMap          *Texture
+	BumpMap              *Texture
+	SpecularHighlightMap *Texture
+	AlphaMap             *Texture
+	EmissiveMap          *Texture
 }
 
 type MaterialData struct {
 	Ambient  mgl32.Vec4
 	Diffuse  mgl32.Vec4
 	Specular mgl32.Vec4
-
-	AmbientMap  string
-	DiffuseMap  string
-	SpecularMap string
+	Emissive mgl32.Vec4
+
+	Shininess      float32
+	Opacity        float32
+	BumpMultiplier float32
+
+	AmbientMap           string
+	DiffuseMap           string
+	SpecularMap          string
+	BumpMap              string
+	SpecularHighlightMap string
+	AlphaMap             string
+	EmissiveMap          string
+
+	AmbientMapOptions           TextureOptions
+	DiffuseMapOptions           TextureOptions
+	SpecularMapOptions          TextureOptions
+	BumpMapOptions              TextureOptions
+	SpecularHighlightMapOptions TextureOptions
+	AlphaMapOptions             TextureOptions
+	EmissiveMapOptions          TextureOptions
 }
 
 const (
@@ -32,35 +58,62 @@ const (
 	NormalAttrID uint32 = 1
 	// TexCoordAttrID is the attribute ID of _TexCoord in GLSL
 	TexCoordAttrID uint32 = 2
+	// TangentAttrID is the attribute ID of _Tangent in GLSL
+	TangentAttrID uint32 = 3
+	// BitangentAttrID is the attribute ID of _Bitangent in GLSL
+	BitangentAttrID uint32 = 4
+)
+
+// Texture units used by Material.Bind
+const (
+	ambientMapUnit int32 = iota
+	diffuseMapUnit
+	specularMapUnit
+	bumpMapUnit
+	specularHighlightMapUnit
+	alphaMapUnit
+	emissiveMapUnit
+
+	materialMapUnits
 )
 
 func NewMaterial(data *MaterialData) (*Material, error) {
-	var err error
 	m := &Material{
-		Ambient:  data.Ambient,
-		Diffuse:  data.Diffuse,
-		Specular: data.Specular,
+		Ambient:        data.Ambient,
+		Diffuse:        data.Diffuse,
+		Specular:       data.Specular,
+		Emissive:       data.Emissive,
+		Shininess:      data.Shininess,
+		Opacity:        data.Opacity,
+		BumpMultiplier: data.BumpMultiplier,
 	}
 
-	if data.AmbientMap != "" {
-		m.AmbientMap, err = NewTextureFromFile(data.AmbientMap)
-		if err != nil {
-			return nil, err
-		}
+	maps := []struct {
+		filename string
+		options  TextureOptions
+		texture  **Texture
+	}{
+		{data.AmbientMap, data.AmbientMapOptions, &m.AmbientMap},
+		{data.DiffuseMap, data.DiffuseMapOptions, &m.DiffuseMap},
+		{data.SpecularMap, data.SpecularMapOptions, &m.SpecularMap},
+		{data.BumpMap, data.BumpMapOptions, &m.BumpMap},
+		{data.SpecularHighlightMap, data.SpecularHighlightMapOptions, &m.SpecularHighlightMap},
+		{data.AlphaMap, data.AlphaMapOptions, &m.AlphaMap},
+		{data.EmissiveMap, data.EmissiveMapOptions, &m.EmissiveMap},
 	}
 
-	if data.DiffuseMap != "" {
-		m.DiffuseMap, err = NewTextureFromFile(data.DiffuseMap)
-		if err != nil {
-			return nil, err
+	for _, tm := range maps {
+		if tm.filename == "" {
+			continue
 		}
-	}
 
-	if data.SpecularMap != "" {
-		m.SpecularMap, err = NewTextureFromFile(data.SpecularMap)
+		t, err := NewTextureFromFile(tm.filename)
 		if err != nil {
+			m.Delete()
 			return nil, err
 		}
+		t.Options = tm.options
+		*tm.texture = t
 	}
 
 	return m, nil
@@ -68,54 +121,104 @@ func NewMaterial(data *MaterialData) (*Material, error) {
 
 // Delete frees all resources owned by the Material
 func (m *Material) Delete() {
-	if m.AmbientMap != nil {
-		m.AmbientMap.Delete()
-		m.AmbientMap = nil
-	}
-	if m.DiffuseMap != nil {
-		m.DiffuseMap.Delete()
-		m.DiffuseMap = nil
-	}
-	if m.SpecularMap != nil {
-		m.SpecularMap.Delete()
-		m.SpecularMap = nil
+	for _, t := range []**Texture{
+		&m.AmbientMap,
+		&m.DiffuseMap,
+		&m.SpecularMap,
+		&m.BumpMap,
+		&m.SpecularHighlightMap,
+		&m.AlphaMap,
+		&m.EmissiveMap,
+	} {
+		if *t != nil {
+			(*t).Delete()
+			*t = nil
+		}
 	}
 }
 
 func (m *Material) Bind(s *Shader) {
-	gl.Uniform1i(s.GetUniformLocation("uAmbientMap"), 0)
+	gl.Uniform1i(s.GetUniformLocation("uAmbientMap"), ambientMapUnit)
 	if m.AmbientMap != nil {
-		gl.ActiveTexture(gl.TEXTURE0)
+		gl.ActiveTexture(gl.TEXTURE0 + uint32(ambientMapUnit))
 		m.AmbientMap.Bind()
 		gl.Uniform4fv(s.GetUniformLocation("uAmbient"), 1, &[]float32{0, 0, 0, 0}[0])
 	} else {
 		gl.Uniform4fv(s.GetUniformLocation("uAmbient"), 1, &m.Ambient[0])
 	}
 
-	gl.Uniform1i(s.GetUniformLocation("uDiffuseMap"), 1)
+	gl.Uniform1i(s.GetUniformLocation("uDiffuseMap"), diffuseMapUnit)
 	if m.DiffuseMap != nil {
-		gl.ActiveTexture(gl.TEXTURE1)
+		gl.ActiveTexture(gl.TEXTURE0 + uint32(diffuseMapUnit))
 		m.DiffuseMap.Bind()
 		gl.Uniform4fv(s.GetUniformLocation("uDiffuse"), 1, &[]float32{0, 0, 0, 0}[0])
 	} else {
 		gl.Uniform4fv(s.GetUniformLocation("uDiffuse"), 1, &m.Diffuse[0])
 	}
 
-	gl.Uniform1i(s.GetUniformLocation("uSpecularMap"), 2)
+	gl.Uniform1i(s.GetUniformLocation("uSpecularMap"), specularMapUnit)
 	if m.SpecularMap != nil {
-		gl.ActiveTexture(gl.TEXTURE2)
+		gl.ActiveTexture(gl.TEXTURE0 + uint32(specularMapUnit))
 		m.SpecularMap.Bind()
 		gl.Uniform4fv(s.GetUniformLocation("uSpecular"), 1, &[]float32{0, 0, 0, 0}[0])
 	} else {
 		gl.Uniform4fv(s.GetUniformLocation("uSpecular"), 1, &m.Specular[0])
 	}
+
+	gl.Uniform1i(s.GetUniformLocation("uEmissiveMap"), emissiveMapUnit)
+	if m.EmissiveMap != nil {
+		gl.ActiveTexture(gl.TEXTURE0 + uint32(emissiveMapUnit))
+		m.EmissiveMap.Bind()
+		gl.Uniform4fv(s.GetUniformLocation("uEmissive"), 1, &[]float32{0, 0, 0, 0}[0])
+	} else {
+		gl.Uniform4fv(s.GetUniformLocation("uEmissive"), 1, &m.Emissive[0])
+	}
+
+	gl.Uniform1f(s.GetUniformLocation("uShininess"), m.Shininess)
+	gl.Uniform1f(s.GetUniformLocation("uOpacity"), m.Opacity)
+	gl.Uniform1f(s.GetUniformLocation("uBumpMultiplier"), m.BumpMultiplier)
+
+	for _, tm := range []struct {
+		name    string
+		texture *Texture
+	}{
+		{"uAmbientMapTransform", m.AmbientMap},
+		{"uDiffuseMapTransform", m.DiffuseMap},
+		{"uSpecularMapTransform", m.SpecularMap},
+		{"uBumpMapTransform", m.BumpMap},
+		{"uSpecularHighlightMapTransform", m.SpecularHighlightMap},
+		{"uAlphaMapTransform", m.AlphaMap},
+		{"uEmissiveMapTransform", m.EmissiveMap},
+	} {
+		transform := mgl32.Vec4{1, 1, 0, 0}
+		if tm.texture != nil {
+			transform = tm.texture.Options.Transform()
+		}
+		gl.Uniform4fv(s.GetUniformLocation(tm.name), 1, &transform[0])
+	}
+
+	bindOptionalMap(s, "BumpMap", bumpMapUnit, m.BumpMap)
+	bindOptionalMap(s, "SpecularHighlightMap", specularHighlightMapUnit, m.SpecularHighlightMap)
+	bindOptionalMap(s, "AlphaMap", alphaMapUnit, m.AlphaMap)
+}
+
+// bindOptionalMap binds a map that modulates a value instead of replacing a color,
+// setting `uHas<name>` so the shader knows whether to sample `u<name>`
+func bindOptionalMap(s *Shader, name string, unit int32, t *Texture) {
+	gl.Uniform1i(s.GetUniformLocation("u"+name), unit)
+	if t != nil {
+		gl.ActiveTexture(gl.TEXTURE0 + uint32(unit))
+		t.Bind()
+		gl.Uniform1i(s.GetUniformLocation("uHas"+name), 1)
+	} else {
+		gl.Uniform1i(s.GetUniformLocation("uHas"+name), 0)
+	}
 }
 
 func (m *Material) UnBind() {
+	for unit := int32(0); unit < materialMapUnits; unit++ {
+		gl.ActiveTexture(gl.TEXTURE0 + uint32(unit))
+		gl.BindTexture(gl.TEXTURE_2D, 0)
+	}
 	gl.ActiveTexture(gl.TEXTURE0)
-	gl.BindTexture(gl.TEXTURE_2D, 0)
-	gl.ActiveTexture(gl.TEXTURE1)
-	gl.BindTexture(gl.TEXTURE_2D, 0)
-	gl.ActiveTexture(gl.TEXTURE2)
-	gl.BindTexture(gl.TEXTURE_2D, 0)
 }
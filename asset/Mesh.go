@@ -12,8 +12,18 @@ type Mesh struct {
 	Material *Material
 	VAO      uint32
 	VBO      uint32
+	EBO      uint32
 	Size     int
 	Count    int32
+
+	layout vertexLayout
+}
+
+// vertexLayout is which attributes are interleaved with the positions of a Mesh
+type vertexLayout struct {
+	Normals   bool
+	TexCoords bool
+	Tangents  bool
 }
 
 // MeshData is the intermediate data format for loading Meshes from Memory
@@ -23,6 +33,13 @@ type MeshData struct {
 	Vertices  []mgl32.Vec3
 	Normals   []mgl32.Vec3
 	TexCoords []mgl32.Vec2
+
+	// Tangents and Bitangents are generated from TexCoords when left empty
+	Tangents   []mgl32.Vec3
+	Bitangents []mgl32.Vec3
+
+	// Indices is optional, when set the Mesh is drawn with glDrawElements
+	Indices []uint32
 }
 
 // NewMesh returns a new Mesh from the given MeshData
@@ -49,6 +66,10 @@ func (m *Mesh) Delete() {
 		gl.DeleteBuffers(1, &m.VBO)
 		m.VBO = InvalidID
 	}
+	if m.EBO != InvalidID {
+		gl.DeleteBuffers(1, &m.EBO)
+		m.EBO = InvalidID
+	}
 	if m.VAO != InvalidID {
 		gl.DeleteVertexArrays(1, &m.VAO)
 		m.VAO = InvalidID
@@ -59,33 +80,13 @@ func (m *Mesh) Delete() {
 func (m *Mesh) LoadFromData(data *MeshData) error {
 	const F = C.sizeof_float
 
-	m.Count = int32(len(data.Vertices))
-	hasNorms := len(data.Normals) > 0
-	hasTxcds := len(data.TexCoords) > 0
-
-	buf := make([]float32, 0, (len(data.Vertices)*3)+(len(data.Normals)*3)+(len(data.TexCoords)*2))
-	for i := range data.Vertices {
-		buf = append(buf, data.Vertices[i][0], data.Vertices[i][1], data.Vertices[i][2])
-		if hasNorms {
-			buf = append(buf, data.Normals[i][0], data.Normals[i][1], data.Normals[i][2])
-		}
-		if hasTxcds {
-			buf = append(buf, data.TexCoords[i][0], data.TexCoords[i][1])
-		}
+	if len(data.Normals) > 0 && len(data.TexCoords) > 0 && len(data.Tangents) == 0 {
+		data.GenerateTangents()
 	}
 
+	buf := data.interleave()
 	m.Size = len(buf)
 
-	stride := int32(3 * F)
-	if hasNorms {
-		stride += int32(3 * F)
-	}
-	if hasTxcds {
-		stride += int32(2 * F)
-	}
-
-	offset := 0
-
 	gl.GenVertexArrays(1, &m.VAO)
 	gl.BindVertexArray(m.VAO)
 
@@ -93,21 +94,20 @@ func (m *Mesh) LoadFromData(data *MeshData) error {
 	gl.BindBuffer(gl.ARRAY_BUFFER, m.VBO)
 	gl.BufferData(gl.ARRAY_BUFFER, len(buf)*F, gl.Ptr(buf), gl.STATIC_DRAW)
 
-	gl.EnableVertexAttribArray(PositionAttrID)
-	gl.VertexAttribPointer(PositionAttrID, 3, gl.FLOAT, false, stride, gl.PtrOffset(offset))
-	offset += 3 * F
+	m.setLayout(data.getLayout())
 
-	if hasNorms {
-		gl.EnableVertexAttribArray(NormalAttrID)
-		gl.VertexAttribPointer(NormalAttrID, 3, gl.FLOAT, false, stride, gl.PtrOffset(offset))
-		offset += 3 * F
-	}
+	if len(data.Indices) > 0 {
+		m.Count = int32(len(data.Indices))
 
-	if hasTxcds {
-		gl.EnableVertexAttribArray(TexCoordAttrID)
-		gl.VertexAttribPointer(TexCoordAttrID, 2, gl.FLOAT, false, stride, gl.PtrOffset(offset))
+		// The element buffer binding is stored in the VAO, so it stays bound
+		gl.GenBuffers(1, &m.EBO)
+		gl.BindBuffer(gl.ELEMENT_ARRAY_BUFFER, m.EBO)
+		gl.BufferData(gl.ELEMENT_ARRAY_BUFFER, len(data.Indices)*C.sizeof_uint, gl.Ptr(data.Indices), gl.STATIC_DRAW)
+	} else {
+		m.Count = int32(len(data.Vertices))
 	}
 
+	gl.BindVertexArray(0)
 	gl.BindBuffer(gl.ARRAY_BUFFER, 0)
 	return nil
 }
@@ -116,21 +116,12 @@ func (m *Mesh) LoadFromData(data *MeshData) error {
 func (m *Mesh) UpdateData(data *MeshData) error {
 	const F = C.sizeof_float
 
-	m.Count = int32(len(data.Vertices))
-	hasNorms := len(data.Normals) > 0
-	hasTxcds := len(data.TexCoords) > 0
-
-	buf := make([]float32, 0, (len(data.Vertices)*3)+(len(data.Normals)*3)+(len(data.TexCoords)*2))
-	for i := range data.Vertices {
-		buf = append(buf, data.Vertices[i][0], data.Vertices[i][1], data.Vertices[i][2])
-		if hasNorms {
-			buf = append(buf, data.Normals[i][0], data.Normals[i][1], data.Normals[i][2])
-		}
-		if hasTxcds {
-			buf = append(buf, data.TexCoords[i][0], data.TexCoords[i][1])
-		}
+	if len(data.Normals) > 0 && len(data.TexCoords) > 0 && len(data.Tangents) == 0 {
+		data.GenerateTangents()
 	}
 
+	buf := data.interleave()
+
 	gl.BindBuffer(gl.ARRAY_BUFFER, m.VBO)
 
 	if m.Size == len(buf) {
@@ -140,11 +131,128 @@ func (m *Mesh) UpdateData(data *MeshData) error {
 		gl.BufferData(gl.ARRAY_BUFFER, len(buf)*F, gl.Ptr(buf), gl.STATIC_DRAW)
 	}
 
+	// The attribute pointers are stored in the VAO, and must be set again if
+	// the data now has different attributes, like generated tangents
+	if layout := data.getLayout(); layout != m.layout {
+		gl.BindVertexArray(m.VAO)
+		m.setLayout(layout)
+		gl.BindVertexArray(0)
+	}
+
 	gl.BindBuffer(gl.ARRAY_BUFFER, 0)
 
+	if len(data.Indices) > 0 {
+		gl.BindVertexArray(m.VAO)
+		if m.EBO == InvalidID {
+			gl.GenBuffers(1, &m.EBO)
+		}
+		gl.BindBuffer(gl.ELEMENT_ARRAY_BUFFER, m.EBO)
+		gl.BufferData(gl.ELEMENT_ARRAY_BUFFER, len(data.Indices)*C.sizeof_uint, gl.Ptr(data.Indices), gl.STATIC_DRAW)
+		gl.BindVertexArray(0)
+
+		m.Count = int32(len(data.Indices))
+	} else {
+		if m.EBO != InvalidID {
+			gl.DeleteBuffers(1, &m.EBO)
+			m.EBO = InvalidID
+		}
+
+		m.Count = int32(len(data.Vertices))
+	}
+
 	return nil
 }
 
+// setLayout points the vertex attributes of the bound VAO into the bound VBO,
+// disabling those the layout doesn't have
+func (m *Mesh) setLayout(layout vertexLayout) {
+	const F = C.sizeof_float
+
+	stride := layout.getStride()
+	offset := 0
+
+	gl.EnableVertexAttribArray(PositionAttrID)
+	gl.VertexAttribPointer(PositionAttrID, 3, gl.FLOAT, false, stride, gl.PtrOffset(offset))
+	offset += 3 * F
+
+	if layout.Normals {
+		gl.EnableVertexAttribArray(NormalAttrID)
+		gl.VertexAttribPointer(NormalAttrID, 3, gl.FLOAT, false, stride, gl.PtrOffset(offset))
+		offset += 3 * F
+	} else {
+		gl.DisableVertexAttribArray(NormalAttrID)
+	}
+
+	if layout.TexCoords {
+		gl.EnableVertexAttribArray(TexCoordAttrID)
+		gl.VertexAttribPointer(TexCoordAttrID, 2, gl.FLOAT, false, stride, gl.PtrOffset(offset))
+		offset += 2 * F
+	} else {
+		gl.DisableVertexAttribArray(TexCoordAttrID)
+	}
+
+	if layout.Tangents {
+		gl.EnableVertexAttribArray(TangentAttrID)
+		gl.VertexAttribPointer(TangentAttrID, 3, gl.FLOAT, false, stride, gl.PtrOffset(offset))
+		offset += 3 * F
+
+		gl.EnableVertexAttribArray(BitangentAttrID)
+		gl.VertexAttribPointer(BitangentAttrID, 3, gl.FLOAT, false, stride, gl.PtrOffset(offset))
+	} else {
+		gl.DisableVertexAttribArray(TangentAttrID)
+		gl.DisableVertexAttribArray(BitangentAttrID)
+	}
+
+	m.layout = layout
+}
+
+// getStride returns the size in bytes of one interleaved vertex
+func (layout vertexLayout) getStride() int32 {
+	const F = C.sizeof_float
+
+	stride := int32(3 * F)
+	if layout.Normals {
+		stride += int32(3 * F)
+	}
+	if layout.TexCoords {
+		stride += int32(2 * F)
+	}
+	if layout.Tangents {
+		stride += int32(6 * F)
+	}
+	return stride
+}
+
+// getLayout returns which attributes interleave packs
+func (data *MeshData) getLayout() vertexLayout {
+	return vertexLayout{
+		Normals:   len(data.Normals) > 0,
+		TexCoords: len(data.TexCoords) > 0,
+		Tangents:  len(data.Tangents) > 0 && len(data.Bitangents) > 0,
+	}
+}
+
+// interleave packs the vertex attributes into a single buffer
+func (data *MeshData) interleave() []float32 {
+	layout := data.getLayout()
+
+	buf := make([]float32, 0, (len(data.Vertices)*3)+(len(data.Normals)*3)+(len(data.TexCoords)*2)+(len(data.Tangents)*6))
+	for i := range data.Vertices {
+		buf = append(buf, data.Vertices[i][0], data.Vertices[i][1], data.Vertices[i][2])
+		if layout.Normals {
+			buf = append(buf, data.Normals[i][0], data.Normals[i][1], data.Normals[i][2])
+		}
+		if layout.TexCoords {
+			buf = append(buf, data.TexCoords[i][0], data.TexCoords[i][1])
+		}
+		if layout.Tangents {
+			buf = append(buf, data.Tangents[i][0], data.Tangents[i][1], data.Tangents[i][2])
+			buf = append(buf, data.Bitangents[i][0], data.Bitangents[i][1], data.Bitangents[i][2])
+		}
+	}
+	return buf
+}
+
 // Draw renders a Mesh to the screen
 func (m *Mesh) Draw(ctx renderContext) {
 	if m.Material != nil {
@@ -152,7 +260,11 @@ func (m *Mesh) Draw(ctx renderContext) {
 	}
 
 	gl.BindVertexArray(m.VAO)
-	gl.DrawArrays(gl.TRIANGLES, 0, m.Count)
+	if m.EBO != InvalidID {
+		gl.DrawElements(gl.TRIANGLES, m.Count, gl.UNSIGNED_INT, nil)
+	} else {
+		gl.DrawArrays(gl.TRIANGLES, 0, m.Count)
+	}
 
 	if m.Material != nil {
 		m.Material.UnBind()
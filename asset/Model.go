@@ -7,6 +7,7 @@ import (
 	"github.com/go-gl/gl/v4.1-core/gl"
 
 	"github.com/WhoBrokeTheBuild/TelcomSim/data"
+	"github.com/WhoBrokeTheBuild/TelcomSim/light"
 	"github.com/WhoBrokeTheBuild/TelcomSim/log"
 	"github.com/WhoBrokeTheBuild/TelcomSim/obj"
 	"github.com/go-gl/mathgl/mgl32"
@@ -47,24 +48,49 @@ func (m *Model) LoadFromFile(filename string) error {
 	m.Delete()
 
 	log.Loadf("asset.Model [%v]", filename)
-	r := obj.NewReaderEx(filename, obj.LoadFunc(data.Asset))
+	r := obj.NewReaderEx(filename, obj.LoadFunc(data.Asset), obj.Lenient(), obj.GenerateNormals(obj.NormalsAuto))
 	objs, err := r.Read()
 	if err != nil {
 		return err
 	}
 
+	for _, w := range r.Warnings() {
+		log.Warnf("%v", w)
+	}
+
 	if len(objs) == 0 {
 		return fmt.Errorf("No objects loaded from [%v]", filename)
 	}
 
 	for _, o := range objs {
+		if o.Material == nil {
+			o.Material = obj.NewMaterial("default")
+			o.Material.Diffuse = mgl32.Vec3{1, 1, 1}
+		}
+
 		mat, err := NewMaterial(&MaterialData{
-			Ambient:     mgl32.Vec4{o.Material.Ambient[0], o.Material.Ambient[1], o.Material.Ambient[2], 1},
-			Diffuse:     mgl32.Vec4{o.Material.Diffuse[0], o.Material.Diffuse[1], o.Material.Diffuse[2], 1},
-			Specular:    mgl32.Vec4{o.Material.Specular[0], o.Material.Specular[1], o.Material.Specular[2], 1},
-			AmbientMap:  o.Material.AmbientMap,
-			DiffuseMap:  o.Material.DiffuseMap,
-			SpecularMap: o.Material.SpecularMap,
+			Ambient:              mgl32.Vec4{o.Material.Ambient[0], o.Material.Ambient[1], o.Material.Ambient[2], 1},
+			Diffuse:              mgl32.Vec4{o.Material.Diffuse[0], o.Material.Diffuse[1], o.Material.Diffuse[2], 1},
+			Specular:             mgl32.Vec4{o.Material.Specular[0], o.Material.Specular[1], o.Material.Specular[2], 1},
+			Emissive:             mgl32.Vec4{o.Material.Emissive[0], o.Material.Emissive[1], o.Material.Emissive[2], 1},
+			Shininess:            o.Material.Shininess,
+			Opacity:              o.Material.Dissolve,
+			BumpMultiplier:       o.Material.BumpMapOptions.BumpMultiplier,
+			AmbientMap:           o.Material.AmbientMap,
+			DiffuseMap:           o.Material.DiffuseMap,
+			SpecularMap:          o.Material.SpecularMap,
+			BumpMap:              o.Material.BumpMap,
+			SpecularHighlightMap: o.Material.SpecularHighlightMap,
+			AlphaMap:             o.Material.AlphaMap,
+			EmissiveMap:          o.Material.EmissiveMap,
+
+			AmbientMapOptions:           newTextureOptions(o.Material.AmbientMapOptions),
+			DiffuseMapOptions:           newTextureOptions(o.Material.DiffuseMapOptions),
+			SpecularMapOptions:          newTextureOptions(o.Material.SpecularMapOptions),
+			BumpMapOptions:              newTextureOptions(o.Material.BumpMapOptions),
+			SpecularHighlightMapOptions: newTextureOptions(o.Material.SpecularHighlightMapOptions),
+			AlphaMapOptions:             newTextureOptions(o.Material.AlphaMapOptions),
+			EmissiveMapOptions:          newTextureOptions(o.Material.EmissiveMapOptions),
 		})
 		if err != nil {
 			return err
@@ -74,6 +100,7 @@ func (m *Model) LoadFromFile(filename string) error {
 			Vertices:  o.Vertices,
 			Normals:   o.Normals,
 			TexCoords: o.TexCoords,
+			Indices:   o.Indices,
 		})
 		if err != nil {
 			return err
@@ -84,6 +111,15 @@ func (m *Model) LoadFromFile(filename string) error {
 	return nil
 }
 
+// newTextureOptions converts the options of an MTL texture map
+func newTextureOptions(opts obj.TextureOptions) TextureOptions {
+	return TextureOptions{
+		Scale:  mgl32.Vec2{opts.Scale[0], opts.Scale[1]},
+		Offset: mgl32.Vec2{opts.Offset[0], opts.Offset[1]},
+		Clamp:  opts.Clamp,
+	}
+}
+
 // Draw renders a Model to the screen
 func (m *Model) Draw(ctx renderContext) {
 	ctx.GetShader().Bind()
@@ -91,6 +127,9 @@ func (m *Model) Draw(ctx renderContext) {
 	gl.UniformMatrix4fv(ctx.GetShader().GetUniformLocation("uProjection"), 1, false, ctx.GetProjectionPtr())
 	gl.UniformMatrix4fv(ctx.GetShader().GetUniformLocation("uView"), 1, false, ctx.GetViewPtr())
 	gl.UniformMatrix4fv(ctx.GetShader().GetUniformLocation("uModel"), 1, false, &m.Transform[0])
+	gl.Uniform3fv(ctx.GetShader().GetUniformLocation("uCamera"), 1, ctx.GetCameraPositionPtr())
+
+	light.Bind(ctx.GetShader().GetUniformLocation, ctx.GetLights())
 
 	for _, mesh := range m.Meshes {
 		mesh.Draw(ctx)
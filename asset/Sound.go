@@ -16,6 +16,9 @@ import (
 	"github.com/faiface/beep/wav"
 )
 
+// _speakerRate is the sample rate the speaker was initialized with, 0 before the first Sound is played
+var _speakerRate beep.SampleRate
+
 func init() {
 }
 
@@ -69,8 +72,33 @@ func (s *Sound) LoadFromFile(filename string) error {
 	return nil
 }
 
-// Play plays the sound on the default speaker
+// Delete frees the resources owned by the Sound
+func (s *Sound) Delete() {
+	if s.Stream != nil {
+		s.Stream.Close()
+		s.Stream = nil
+	}
+}
+
+// Play plays the sound from the start on the default speaker
 func (s *Sound) Play() {
-	speaker.Init(s.Format.SampleRate, s.Format.SampleRate.N(time.Second/10))
+	if s.Stream == nil {
+		return
+	}
+
+	// Initializing the speaker again stops everything playing, so it is only done when the rate changes
+	if _speakerRate != s.Format.SampleRate {
+		err := speaker.Init(s.Format.SampleRate, s.Format.SampleRate.N(time.Second/10))
+		if err != nil {
+			log.Errorf("%v", err)
+			return
+		}
+		_speakerRate = s.Format.SampleRate
+	}
+
+	err := s.Stream.Seek(0)
+	if err != nil {
+		log.Warnf("%v", err)
+	}
 	speaker.Play(s.Stream)
 }
@@ -0,0 +1,79 @@
+package asset
+
+import (
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// GenerateTangents computes per-vertex Tangents and Bitangents from the
+// Vertices, Normals and TexCoords of the MeshData
+func (data *MeshData) GenerateTangents() {
+	n := len(data.Vertices)
+	if len(data.Normals) != n || len(data.TexCoords) != n {
+		return
+	}
+
+	tan := make([]mgl32.Vec3, n)
+	bitan := make([]mgl32.Vec3, n)
+
+	indices := data.Indices
+	if len(indices) == 0 {
+		indices = make([]uint32, n)
+		for i := range indices {
+			indices[i] = uint32(i)
+		}
+	}
+
+	for k := 0; k+2 < len(indices); k += 3 {
+		i0, i1, i2 := indices[k], indices[k+1], indices[k+2]
+
+		e1 := data.Vertices[i1].Sub(data.Vertices[i0])
+		e2 := data.Vertices[i2].Sub(data.Vertices[i0])
+		d1 := data.TexCoords[i1].Sub(data.TexCoords[i0])
+		d2 := data.TexCoords[i2].Sub(data.TexCoords[i0])
+
+		det := d1[0]*d2[1] - d2[0]*d1[1]
+		if det == 0 {
+			// Degenerate UVs
+			continue
+		}
+		r := 1 / det
+
+		t := e1.Mul(d2[1]).Sub(e2.Mul(d1[1])).Mul(r)
+		b := e2.Mul(d1[0]).Sub(e1.Mul(d2[0])).Mul(r)
+
+		for _, i := range []uint32{i0, i1, i2} {
+			tan[i] = tan[i].Add(t)
+			bitan[i] = bitan[i].Add(b)
+		}
+	}
+
+	data.Tangents = make([]mgl32.Vec3, n)
+	data.Bitangents = make([]mgl32.Vec3, n)
+	for i := range data.Vertices {
+		normal := data.Normals[i]
+
+		// Gram-Schmidt orthogonalize against the normal
+		t := tan[i].Sub(normal.Mul(normal.Dot(tan[i])))
+		if t.Len() < 1e-6 {
+			t = perpendicular(normal)
+		}
+		t = t.Normalize()
+
+		// Keep the handedness of the UV mapping, mirrored UVs flip the bitangent
+		b := normal.Cross(t)
+		if b.Dot(bitan[i]) < 0 {
+			b = b.Mul(-1)
+		}
+
+		data.Tangents[i] = t
+		data.Bitangents[i] = b
+	}
+}
+
+// perpendicular returns any vector perpendicular to v
+func perpendicular(v mgl32.Vec3) mgl32.Vec3 {
+	if v[0] < 0.9 && v[0] > -0.9 {
+		return v.Cross(mgl32.Vec3{1, 0, 0})
+	}
+	return v.Cross(mgl32.Vec3{0, 1, 0})
+}
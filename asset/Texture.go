@@ -13,8 +13,27 @@ import (
 
 // Texture represents an OpenGL Texture
 type Texture struct {
-	ID   uint32
-	Size mgl32.Vec2
+	ID      uint32
+	Size    mgl32.Vec2
+	Options TextureOptions
+}
+
+// TextureOptions controls how a Texture is sampled
+type TextureOptions struct {
+	// Scale and Offset are applied to the texture coordinates, a zero Scale is treated as 1
+	Scale  mgl32.Vec2
+	Offset mgl32.Vec2
+	// Clamp uses GL_CLAMP_TO_EDGE instead of GL_REPEAT
+	Clamp bool
+}
+
+// Transform returns the Scale and Offset packed as (scale.x, scale.y, offset.x, offset.y)
+func (o TextureOptions) Transform() mgl32.Vec4 {
+	scale := o.Scale
+	if scale[0] == 0 && scale[1] == 0 {
+		scale = mgl32.Vec2{1, 1}
+	}
+	return mgl32.Vec4{scale[0], scale[1], o.Offset[0], o.Offset[1]}
 }
 
 type glTexture struct {
@@ -156,7 +175,16 @@ func (t *Texture) LoadFromData(data []uint8, intFormat uint32, format int32, wid
 	return nil
 }
 
-// Bind calls glBindTexture with the Texture's ID
+// Bind calls glBindTexture with the Texture's ID and applies its wrap mode
 func (t *Texture) Bind() {
 	gl.BindTexture(gl.TEXTURE_2D, t.ID)
+
+	// The GL texture may be shared with other Textures of the same file, so the
+	// wrap mode is set on every bind
+	wrap := int32(gl.REPEAT)
+	if t.Options.Clamp {
+		wrap = gl.CLAMP_TO_EDGE
+	}
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_S, wrap)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_T, wrap)
 }
@@ -1,5 +1,9 @@
 package asset
 
+import (
+	"github.com/WhoBrokeTheBuild/TelcomSim/light"
+)
+
 const (
 	// InvalidID is an invalid OpenGL ID
 	InvalidID uint32 = 0
@@ -9,5 +13,7 @@ const (
 type renderContext interface {
 	GetProjectionPtr() *float32
 	GetViewPtr() *float32
+	GetCameraPositionPtr() *float32
 	GetShader() *Shader
+	GetLights() []*light.Light
 }
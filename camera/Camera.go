@@ -0,0 +1,137 @@
+package camera
+
+import (
+	"github.com/WhoBrokeTheBuild/TelcomSim/context"
+	"github.com/WhoBrokeTheBuild/TelcomSim/save"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// Controller moves a Camera in response to input
+type Controller interface {
+	Update(*Camera, *context.Update)
+}
+
+// Camera owns the view and projection matrices used to render a scene
+type Camera struct {
+	View       mgl32.Mat4
+	Projection mgl32.Mat4
+	Position   mgl32.Vec3
+
+	// FOV is the vertical field of view in radians
+	FOV    float32
+	Aspect float32
+	Near   float32
+	Far    float32
+
+	// Controller is optional, without one the Camera follows its scene.Node
+	Controller Controller
+}
+
+// NewPerspective returns a new Camera with a perspective projection
+func NewPerspective(fov, aspect, near, far float32) *Camera {
+	c := &Camera{
+		View: mgl32.Ident4(),
+		FOV:  fov,
+		Near: near,
+		Far:  far,
+	}
+	c.SetAspect(aspect)
+	return c
+}
+
+// SetAspect sets the aspect ratio and rebuilds the projection matrix
+func (c *Camera) SetAspect(aspect float32) {
+	c.Aspect = aspect
+	c.Projection = mgl32.Perspective(c.FOV, c.Aspect, c.Near, c.Far)
+}
+
+// SetTransform sets the view matrix from the Camera's world transform
+func (c *Camera) SetTransform(world mgl32.Mat4) {
+	c.View = world.Inv()
+	c.Position = world.Col(3).Vec3()
+}
+
+// LookAt places the Camera at eye, facing target
+func (c *Camera) LookAt(eye, target, up mgl32.Vec3) {
+	c.View = mgl32.LookAtV(eye, target, up)
+	c.Position = eye
+}
+
+// GetForward returns the direction the Camera is facing
+func (c *Camera) GetForward() mgl32.Vec3 {
+	return c.View.Inv().Col(2).Vec3().Mul(-1).Normalize()
+}
+
+// GetRight returns the direction to the Camera's right
+func (c *Camera) GetRight() mgl32.Vec3 {
+	return c.View.Inv().Col(0).Vec3().Normalize()
+}
+
+// Update runs the Controller, if any
+func (c *Camera) Update(ctx *context.Update) {
+	if c.Controller != nil {
+		c.Controller.Update(c, ctx)
+	}
+}
+
+// Bind sets the view, projection, and camera position of the render context
+func (c *Camera) Bind(ctx *context.Render) {
+	ctx.View = c.View
+	ctx.Projection = c.Projection
+	ctx.CameraPosition = c.Position
+}
+
+// Save returns the state of the Camera and its Controller for a save file
+func (c *Camera) Save() save.Camera {
+	s := save.Camera{
+		Position: c.Position,
+	}
+
+	switch ctrl := c.Controller.(type) {
+	case *Orbit:
+		s.Controller = "orbit"
+		s.Target = ctrl.Target
+		s.Distance = ctrl.Distance
+		s.Yaw = ctrl.Yaw
+		s.Pitch = ctrl.Pitch
+	case *Fly:
+		s.Controller = "fly"
+		s.Position = ctrl.Position
+		s.Yaw = ctrl.Yaw
+		s.Pitch = ctrl.Pitch
+	case *TopDown:
+		s.Controller = "top_down"
+		s.Target = mgl32.Vec3{ctrl.Target[0], 0, ctrl.Target[1]}
+		s.Height = ctrl.Height
+		s.Yaw = ctrl.Yaw
+	}
+	return s
+}
+
+// Load restores the state of the Camera and its Controller from a save file,
+// if the Controller is the same kind as the one saved
+func (c *Camera) Load(s save.Camera) {
+	c.Position = s.Position
+
+	switch ctrl := c.Controller.(type) {
+	case *Orbit:
+		if s.Controller == "orbit" {
+			ctrl.Target = s.Target
+			ctrl.Distance = s.Distance
+			ctrl.Yaw = s.Yaw
+			ctrl.Pitch = s.Pitch
+		}
+	case *Fly:
+		if s.Controller == "fly" {
+			ctrl.Position = s.Position
+			ctrl.Yaw = s.Yaw
+			ctrl.Pitch = s.Pitch
+		}
+	case *TopDown:
+		if s.Controller == "top_down" {
+			ctrl.Target = mgl32.Vec2{s.Target[0], s.Target[2]}
+			ctrl.Height = s.Height
+			ctrl.Yaw = s.Yaw
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package camera
+
+import (
+	"math"
+
+	"github.com/WhoBrokeTheBuild/TelcomSim/context"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// Fly is a free-look Controller, moving with the pan and move actions, and looking
+// around while the "look" action is held
+type Fly struct {
+	Position mgl32.Vec3
+	// Yaw and Pitch are in radians, a Yaw of 0 faces -Z
+	Yaw   float32
+	Pitch float32
+
+	// Speed is in units per second, Sensitivity in radians per pixel, and
+	// FastMultiplier applies while "move_fast" is held
+	Speed          float32
+	Sensitivity    float32
+	FastMultiplier float32
+}
+
+// NewFly returns a new Fly Controller at position
+func NewFly(position mgl32.Vec3) *Fly {
+	return &Fly{
+		Position:       position,
+		Speed:          5,
+		Sensitivity:    0.003,
+		FastMultiplier: 4,
+	}
+}
+
+// Update processes input and moves the Camera
+func (f *Fly) Update(c *Camera, ctx *context.Update) {
+	dt := ctx.DeltaTime
+
+	in := ctx.Input
+
+	if in.IsActionDown("look") && !in.IsActionPressed("look") {
+		f.Yaw -= in.CursorDelta[0] * f.Sensitivity
+		f.Pitch -= in.CursorDelta[1] * f.Sensitivity
+	}
+
+	limit := float32(math.Pi/2 - 0.01)
+	f.Pitch = mgl32.Clamp(f.Pitch, -limit, limit)
+
+	forward := f.getForward()
+	right := forward.Cross(mgl32.Vec3{0, 1, 0}).Normalize()
+	up := mgl32.Vec3{0, 1, 0}
+
+	move := mgl32.Vec3{}
+	if in.IsActionDown("pan_forward") {
+		move = move.Add(forward)
+	}
+	if in.IsActionDown("pan_back") {
+		move = move.Sub(forward)
+	}
+	if in.IsActionDown("pan_right") {
+		move = move.Add(right)
+	}
+	if in.IsActionDown("pan_left") {
+		move = move.Sub(right)
+	}
+	if in.IsActionDown("move_up") {
+		move = move.Add(up)
+	}
+	if in.IsActionDown("move_down") {
+		move = move.Sub(up)
+	}
+
+	if move.Len() > 0 {
+		speed := f.Speed
+		if in.IsActionDown("move_fast") {
+			speed *= f.FastMultiplier
+		}
+		f.Position = f.Position.Add(move.Normalize().Mul(speed * dt))
+	}
+
+	c.LookAt(f.Position, f.Position.Add(f.getForward()), mgl32.Vec3{0, 1, 0})
+}
+
+func (f *Fly) getForward() mgl32.Vec3 {
+	// A Yaw of 0 faces -Z, like an unrotated camera
+	return sphericalToCartesian(f.Yaw+math.Pi, f.Pitch)
+}
@@ -0,0 +1,68 @@
+package camera
+
+import (
+	"math"
+
+	"github.com/WhoBrokeTheBuild/TelcomSim/context"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// Orbit is a Controller that circles a target point, rotating while the "orbit"
+// action is dragged and zooming with the scroll wheel
+type Orbit struct {
+	Target   mgl32.Vec3
+	Distance float32
+	// Yaw and Pitch are in radians, a Pitch of 0 is level with the Target
+	Yaw   float32
+	Pitch float32
+
+	MinDistance float32
+	MaxDistance float32
+
+	// Sensitivity is radians per pixel dragged, ZoomSpeed is the fraction of the distance per scroll step
+	Sensitivity float32
+	ZoomSpeed   float32
+}
+
+// NewOrbit returns a new Orbit Controller around target
+func NewOrbit(target mgl32.Vec3, distance float32) *Orbit {
+	return &Orbit{
+		Target:      target,
+		Distance:    distance,
+		Yaw:         math.Pi / 4,
+		Pitch:       math.Pi / 6,
+		MinDistance: 1,
+		MaxDistance: 500,
+		Sensitivity: 0.005,
+		ZoomSpeed:   0.1,
+	}
+}
+
+// Update processes input and moves the Camera
+func (o *Orbit) Update(c *Camera, ctx *context.Update) {
+	in := ctx.Input
+	if in.IsActionDown("orbit") && !in.IsActionPressed("orbit") {
+		o.Yaw -= in.CursorDelta[0] * o.Sensitivity
+		o.Pitch += in.CursorDelta[1] * o.Sensitivity
+	}
+
+	o.Distance *= 1 - in.Scroll[1]*o.ZoomSpeed
+	o.Distance = mgl32.Clamp(o.Distance, o.MinDistance, o.MaxDistance)
+
+	// Stay off the poles, where the up vector is undefined
+	limit := float32(math.Pi/2 - 0.01)
+	o.Pitch = mgl32.Clamp(o.Pitch, -limit, limit)
+
+	eye := o.Target.Add(sphericalToCartesian(o.Yaw, o.Pitch).Mul(o.Distance))
+	c.LookAt(eye, o.Target, mgl32.Vec3{0, 1, 0})
+}
+
+// sphericalToCartesian returns the unit vector for the given yaw around Y and pitch above the XZ plane
+func sphericalToCartesian(yaw, pitch float32) mgl32.Vec3 {
+	cp := float32(math.Cos(float64(pitch)))
+	return mgl32.Vec3{
+		cp * float32(math.Sin(float64(yaw))),
+		float32(math.Sin(float64(pitch))),
+		cp * float32(math.Cos(float64(yaw))),
+	}
+}
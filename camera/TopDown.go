@@ -0,0 +1,117 @@
+package camera
+
+import (
+	"math"
+
+	"github.com/WhoBrokeTheBuild/TelcomSim/context"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// TopDown is a strategy game Controller looking down at a point on the ground.
+// It pans with the pan actions or by moving the cursor to the edge of the window,
+// rotates with the rotate actions, and zooms with the scroll wheel.
+type TopDown struct {
+	// Target is the point on the ground (Y = 0) at the center of the view
+	Target mgl32.Vec2
+	Height float32
+	// Yaw is the rotation around Y in radians, Tilt is the angle below the horizon
+	Yaw  float32
+	Tilt float32
+
+	MinHeight float32
+	MaxHeight float32
+
+	// Bounds limits the Target to (minX, minZ, maxX, maxZ), unless it is all zero
+	Bounds mgl32.Vec4
+
+	// PanSpeed is in heights per second, so panning feels the same at every zoom level
+	PanSpeed    float32
+	RotateSpeed float32
+	ZoomSpeed   float32
+	// EdgeSize is how close to the window edge, in pixels, the cursor starts panning
+	EdgeSize float32
+}
+
+// NewTopDown returns a new TopDown Controller looking down at target
+func NewTopDown(target mgl32.Vec2, height float32) *TopDown {
+	return &TopDown{
+		Target:      target,
+		Height:      height,
+		Tilt:        mgl32.DegToRad(60),
+		MinHeight:   2,
+		MaxHeight:   1000,
+		PanSpeed:    1,
+		RotateSpeed: math.Pi / 2,
+		ZoomSpeed:   0.1,
+		EdgeSize:    8,
+	}
+}
+
+// Update processes input and moves the Camera
+func (t *TopDown) Update(c *Camera, ctx *context.Update) {
+	dt := ctx.DeltaTime
+
+	// Pan relative to the direction the camera faces
+	sin, cos := float32(math.Sin(float64(t.Yaw))), float32(math.Cos(float64(t.Yaw)))
+	forward := mgl32.Vec2{-sin, -cos}
+	right := mgl32.Vec2{cos, -sin}
+
+	in := ctx.Input
+	pan := mgl32.Vec2{}
+
+	if in.IsActionDown("pan_forward") {
+		pan = pan.Add(forward)
+	}
+	if in.IsActionDown("pan_back") {
+		pan = pan.Sub(forward)
+	}
+	if in.IsActionDown("pan_right") {
+		pan = pan.Add(right)
+	}
+	if in.IsActionDown("pan_left") {
+		pan = pan.Sub(right)
+	}
+
+	// Only edge-pan while the cursor is inside the window
+	if in.Focused {
+		cx, cy := in.Cursor[0], in.Cursor[1]
+		width, height := in.WindowSize[0], in.WindowSize[1]
+		if cx >= 0 && cy >= 0 && cx < width && cy < height {
+			if cx < t.EdgeSize {
+				pan = pan.Sub(right)
+			} else if cx >= width-t.EdgeSize {
+				pan = pan.Add(right)
+			}
+			if cy < t.EdgeSize {
+				pan = pan.Add(forward)
+			} else if cy >= height-t.EdgeSize {
+				pan = pan.Sub(forward)
+			}
+		}
+	}
+
+	if pan.Len() > 0 {
+		t.Target = t.Target.Add(pan.Normalize().Mul(t.PanSpeed * t.Height * dt))
+	}
+
+	if in.IsActionDown("rotate_left") {
+		t.Yaw += t.RotateSpeed * dt
+	}
+	if in.IsActionDown("rotate_right") {
+		t.Yaw -= t.RotateSpeed * dt
+	}
+
+	t.Height *= 1 - in.Scroll[1]*t.ZoomSpeed
+	t.Height = mgl32.Clamp(t.Height, t.MinHeight, t.MaxHeight)
+
+	if t.Bounds != (mgl32.Vec4{}) {
+		t.Target[0] = mgl32.Clamp(t.Target[0], t.Bounds[0], t.Bounds[2])
+		t.Target[1] = mgl32.Clamp(t.Target[1], t.Bounds[1], t.Bounds[3])
+	}
+
+	// Back away from the target along the tilt, so the target stays centered
+	back := t.Height / float32(math.Tan(float64(t.Tilt)))
+	target := mgl32.Vec3{t.Target[0], 0, t.Target[1]}
+	eye := target.Add(mgl32.Vec3{sin * back, t.Height, cos * back})
+	c.LookAt(eye, target, mgl32.Vec3{0, 1, 0})
+}
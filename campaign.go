@@ -0,0 +1,204 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"path"
+
+	"github.com/WhoBrokeTheBuild/TelcomSim/context"
+	"github.com/WhoBrokeTheBuild/TelcomSim/data"
+	"github.com/WhoBrokeTheBuild/TelcomSim/log"
+	"github.com/WhoBrokeTheBuild/TelcomSim/scenario"
+	"github.com/WhoBrokeTheBuild/TelcomSim/ui"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// loadScenario reads the named scenario from scenarios/<name>.json
+func loadScenario(name string) (*scenario.Scenario, error) {
+	file := path.Join("scenarios", name+".json")
+	log.Loadf("scenario.Scenario [%v]", file)
+	b, err := data.Asset(file)
+	if err != nil {
+		return nil, err
+	}
+	return scenario.Parse(b)
+}
+
+// loadCampaign reads the named campaign from campaigns/<name>.json
+func loadCampaign(name string) (*scenario.Campaign, error) {
+	file := path.Join("campaigns", name+".json")
+	log.Loadf("scenario.Campaign [%v]", file)
+	b, err := data.Asset(file)
+	if err != nil {
+		return nil, err
+	}
+	return scenario.ParseCampaign(b)
+}
+
+// newGame replaces the game with a new one of the named scenario, as the given
+// stage of campaign if it isn't nil
+func (g *game) newGame(name string, campaign *scenario.Campaign, stage int) error {
+	s, err := loadScenario(name)
+	if err != nil {
+		return err
+	}
+	m, spawn, err := loadMap(s.Map)
+	if err != nil {
+		return err
+	}
+	tree, err := loadTechTree()
+	if err != nil {
+		log.Warnf("Everything is unlocked, %v", err)
+	}
+
+	world, err := s.NewWorld(m, spawn, tree)
+	if err != nil {
+		return err
+	}
+
+	run := scenario.NewRun(name, s)
+	run.Campaign = campaign
+	run.Stage = stage
+	run.Events = g.Events
+
+	// The World is replaced in place, as everything holding it keeps the pointer
+	if g.World == nil {
+		g.World = world
+	} else {
+		*g.World = *world
+	}
+	g.World.OnCommand = logCommand
+	g.World.SetEvents(g.Events)
+	g.Scenario = run
+	g.Loop.SetTick(0)
+	g.setTerrain(g.World.Map)
+	log.Infof("Started [%v]", s.Name)
+	g.notify(s.Description)
+
+	// A replay can't span a new game, so it starts over from it
+	if g.Recorder != nil {
+		g.startRecording()
+	}
+	return nil
+}
+
+// nextGame starts the next scenario of the campaign once one is won, or the
+// same one again once it is lost
+func (g *game) nextGame() {
+	r := g.Scenario
+	if r == nil || r.Status == scenario.Playing {
+		return
+	}
+
+	name, stage := r.ID, r.Stage
+	if next := r.GetNext(); next != "" {
+		name, stage = next, r.Stage+1
+	} else if r.Status == scenario.Won {
+		return
+	}
+
+	err := g.newGame(name, r.Campaign, stage)
+	if err != nil {
+		log.Errorf("%v", err)
+		return
+	}
+	g.Loop.SetPaused(false)
+}
+
+// objectiveList shows the name and Objectives of the scenario played, with how each stands
+type objectiveList struct {
+	Game  *game
+	Lines []*ui.Text
+}
+
+// objectiveListOrigin is where the first line of an objectiveList is shown, and
+// objectiveListSpacing how far apart its lines are
+var (
+	objectiveListOrigin  = mgl32.Vec2{float32(windowWidth) - 420, 30}
+	objectiveListSpacing = float32(20)
+)
+
+// newObjectiveList returns an objectiveList of the scenario g is playing
+func newObjectiveList(g *game) *objectiveList {
+	return &objectiveList{Game: g}
+}
+
+// Delete frees all resources owned by the objectiveList
+func (c *objectiveList) Delete() {
+	for _, t := range c.Lines {
+		t.Delete()
+	}
+	c.Lines = nil
+}
+
+// Update keeps the lines in step with the scenario
+func (c *objectiveList) Update(ctx *context.Update) {
+	lines := c.getLines()
+	for len(c.Lines) < len(lines) {
+		t := ui.NewText(" ", "ui/default.ttf", 16.0, color.White)
+		if t == nil {
+			return
+		}
+		t.SetPosition(objectiveListOrigin.Add(mgl32.Vec2{0, float32(len(c.Lines)) * objectiveListSpacing}))
+		c.Lines = append(c.Lines, t)
+	}
+	for len(c.Lines) > len(lines) {
+		c.Lines[len(c.Lines)-1].Delete()
+		c.Lines = c.Lines[:len(c.Lines)-1]
+	}
+
+	for i, line := range lines {
+		if c.Lines[i].Text != line {
+			c.Lines[i].SetText(line)
+		}
+	}
+}
+
+// getLines returns the text of each line to show
+func (c *objectiveList) getLines() []string {
+	r := c.Game.Scenario
+	if r == nil || len(r.Scenario.Objectives) == 0 {
+		return nil
+	}
+
+	title := r.Scenario.Name
+	if r.Campaign != nil {
+		title = fmt.Sprintf("%v %d/%d: %v", r.Campaign.Name, r.Stage+1, len(r.Campaign.Scenarios), title)
+	}
+	lines := []string{title}
+
+	for i, o := range r.Scenario.Objectives {
+		mark := "[ ]"
+		switch {
+		case r.Objectives[i] == scenario.Won:
+			mark = "[x]"
+		case r.Objectives[i] == scenario.Lost:
+			mark = "[!]"
+		case o.Lose:
+			mark = "Lose if"
+		}
+		lines = append(lines, fmt.Sprintf("%v %v", mark, o.GetDescription()))
+	}
+
+	switch {
+	case r.Status == scenario.Won && r.GetNext() != "":
+		lines = append(lines, "Won! Press N for the next scenario")
+	case r.Status == scenario.Won && r.Campaign != nil:
+		lines = append(lines, "Campaign complete!")
+	case r.Status == scenario.Won:
+		lines = append(lines, "Won!")
+	case r.Status == scenario.Lost:
+		lines = append(lines, "Lost. Press N to try again")
+	}
+	return lines
+}
+
+// Draw renders the lines, unless a panel is shown over them
+func (c *objectiveList) Draw(ctx *context.Render) {
+	if c.Game.isPanelShown() {
+		return
+	}
+	for _, t := range c.Lines {
+		t.Draw(ctx)
+	}
+}
@@ -2,14 +2,18 @@ package context
 
 import (
 	"github.com/WhoBrokeTheBuild/TelcomSim/asset"
+	"github.com/WhoBrokeTheBuild/TelcomSim/light"
 	"github.com/go-gl/mathgl/mgl32"
 )
 
-// Render is a context of view and shader data
+// Render is a context of view, shader, and lighting data
 type Render struct {
 	Projection mgl32.Mat4
 	View       mgl32.Mat4
 	Shader     *asset.Shader
+	Lights     []*light.Light
+
+	CameraPosition mgl32.Vec3
 }
 
 // GetProjectionPtr returns the projection matrix as a *float32
@@ -22,7 +26,17 @@ func (r *Render) GetViewPtr() *float32 {
 	return &r.View[0]
 }
 
+// GetCameraPositionPtr returns the camera position as a *float32
+func (r *Render) GetCameraPositionPtr() *float32 {
+	return &r.CameraPosition[0]
+}
+
 // GetShader returns the Shader
 func (r *Render) GetShader() *asset.Shader {
 	return r.Shader
 }
+
+// GetLights returns the Lights
+func (r *Render) GetLights() []*light.Light {
+	return r.Lights
+}
@@ -1,8 +1,20 @@
 package context
 
-// Update is a context of timing data
+import (
+	"github.com/WhoBrokeTheBuild/TelcomSim/input"
+)
+
+// Update is a context of timing and input data
 type Update struct {
+	// DeltaTime and ElapsedTime are the seconds this update covers, a fixed step for
+	// simulation ticks or the real frame time otherwise
 	DeltaTime   float32
 	ElapsedTime float64
-	TotalTime   float64
+	// TotalTime is the seconds simulated before this update
+	TotalTime float64
+	// Tick is the number of simulation ticks run before this update
+	Tick uint64
+
+	// Input is the input received since the last update, never nil
+	Input *input.State
 }
@@ -0,0 +1,257 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+
+	"github.com/WhoBrokeTheBuild/TelcomSim/context"
+	"github.com/WhoBrokeTheBuild/TelcomSim/ecs"
+	"github.com/WhoBrokeTheBuild/TelcomSim/maintenance"
+	"github.com/WhoBrokeTheBuild/TelcomSim/network"
+	"github.com/WhoBrokeTheBuild/TelcomSim/sim"
+	"github.com/WhoBrokeTheBuild/TelcomSim/ui"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+var (
+	crewColor         = color.RGBA{40, 80, 160, 230}
+	crewSelectedColor = color.RGBA{170, 130, 20, 230}
+	crewFaultColor    = color.RGBA{160, 40, 40, 230}
+)
+
+// crewOrigin is where the first row of each column is shown, crewCell the space each row takes
+var (
+	crewOrigin = mgl32.Vec2{20, 50}
+	crewCell   = mgl32.Vec2{320, 34}
+)
+
+// crewRows is the most rows shown in each column
+const crewRows = 18
+
+// crewPanel shows the exchanges, repair Crews and Faults of a World in three
+// columns. Clicking a row selects it. Clicking the selected exchange again or
+// pressing hire_crew hires a Crew there, and clicking the selected Fault again or
+// pressing dispatch_crew sends the selected Crew to it.
+type crewPanel struct {
+	Game  *game
+	Shown bool
+
+	Exchanges buttonColumn
+	Crews     buttonColumn
+	Faults    buttonColumn
+	Info      *ui.Text
+
+	exchange network.NodeID
+	crew     ecs.Entity
+	fault    maintenance.FaultID
+}
+
+// newCrewPanel returns a hidden crewPanel of the World g is playing
+func newCrewPanel(g *game) *crewPanel {
+	c := &crewPanel{Game: g}
+	c.Exchanges = buttonColumn{
+		Origin:  crewOrigin,
+		OnClick: func(id int) { c.clickExchange(network.NodeID(id)) },
+	}
+	c.Crews = buttonColumn{
+		Origin:  crewOrigin.Add(mgl32.Vec2{crewCell.X(), 0}),
+		OnClick: func(id int) { c.crew = ecs.Entity(id) },
+	}
+	c.Faults = buttonColumn{
+		Origin:  crewOrigin.Add(mgl32.Vec2{crewCell.X() * 2, 0}),
+		OnClick: func(id int) { c.clickFault(maintenance.FaultID(id)) },
+	}
+	return c
+}
+
+// Delete frees all resources owned by the crewPanel
+func (c *crewPanel) Delete() {
+	c.Exchanges.Delete()
+	c.Crews.Delete()
+	c.Faults.Delete()
+	if c.Info != nil {
+		c.Info.Delete()
+		c.Info = nil
+	}
+}
+
+// clickExchange selects an exchange, or hires a Crew there if it is already selected
+func (c *crewPanel) clickExchange(id network.NodeID) {
+	if c.exchange == id {
+		c.hire()
+		return
+	}
+	c.exchange = id
+}
+
+// clickFault selects a Fault, or sends the selected Crew to it if it is already selected
+func (c *crewPanel) clickFault(id maintenance.FaultID) {
+	if c.fault == id {
+		c.dispatch()
+		return
+	}
+	c.fault = id
+}
+
+// hire hires a Crew at the selected exchange
+func (c *crewPanel) hire() {
+	if c.Game.World.Network.GetNode(c.exchange) == nil {
+		return
+	}
+	c.Game.World.Queue(sim.Command{Type: sim.HireCrew, Node: c.exchange})
+}
+
+// dispatch sends the selected Crew to the selected Fault
+func (c *crewPanel) dispatch() {
+	m := c.Game.World.Maintenance
+	if m.Crews.Get(c.crew) == nil || m.GetFault(c.fault) == nil {
+		return
+	}
+	c.Game.World.Queue(sim.Command{Type: sim.DispatchCrew, Crew: c.crew, Fault: c.fault})
+}
+
+// Update handles clicks and keys while the crewPanel is shown, and keeps it up to date with the World
+func (c *crewPanel) Update(ctx *context.Update) {
+	if !c.Shown {
+		return
+	}
+	world := c.Game.World
+	m := world.Maintenance
+
+	exchanges := []int{}
+	for _, node := range world.Network.GetNodesOfType(network.Exchange) {
+		exchanges = append(exchanges, int(node.ID))
+	}
+	crews := []int{}
+	for _, e := range m.GetCrews() {
+		crews = append(crews, int(e))
+	}
+	faults := []int{}
+	for _, f := range m.Faults {
+		faults = append(faults, int(f.ID))
+	}
+
+	c.Exchanges.Update(ctx, exchanges, int(c.exchange), func(id int) (string, color.Color) {
+		return world.Network.GetNode(network.NodeID(id)).Name, crewColor
+	})
+	c.Crews.Update(ctx, crews, int(c.crew), func(id int) (string, color.Color) {
+		crew := m.Crews.Get(ecs.Entity(id))
+		return fmt.Sprintf("Crew %d of %v  %v", id, c.Game.getEquipmentName(crew.Base, 0), crew.State), crewColor
+	})
+	c.Faults.Update(ctx, faults, int(c.fault), func(id int) (string, color.Color) {
+		f := m.GetFault(maintenance.FaultID(id))
+		text := fmt.Sprintf("%v %v", c.Game.getEquipmentName(f.Node, f.Link), f.Cause)
+		if f.Crew != 0 {
+			return fmt.Sprintf("%v, crew %d", text, f.Crew), crewColor
+		}
+		return text, crewFaultColor
+	})
+
+	if ctx.Input.IsActionPressed("hire_crew") {
+		c.hire()
+	}
+	if ctx.Input.IsActionPressed("dispatch_crew") {
+		c.dispatch()
+	}
+
+	if c.Info == nil {
+		// Text can't render an empty string
+		c.Info = ui.NewText(" ", "ui/default.ttf", 16.0, color.White)
+		if c.Info == nil {
+			return
+		}
+		c.Info.SetPosition(mgl32.Vec2{crewOrigin.X(), float32(windowHeight) - 60})
+	}
+	if text := c.describe(); text != c.Info.Text {
+		c.Info.SetText(text)
+	}
+}
+
+// describe returns what the selection can be used for
+func (c *crewPanel) describe() string {
+	m := c.Game.World.Maintenance
+	if m.Crews.Get(c.crew) != nil && m.GetFault(c.fault) != nil {
+		return "Click the fault again to send the crew to it"
+	}
+	if c.Game.World.Network.GetNode(c.exchange) != nil {
+		return fmt.Sprintf("Click the exchange again to hire a crew there for $%.0f", m.Config.HireCost)
+	}
+	return "Click an exchange to hire a crew, or a crew and a fault to send it out"
+}
+
+// Draw renders the crewPanel, if it is shown
+func (c *crewPanel) Draw(ctx *context.Render) {
+	if !c.Shown {
+		return
+	}
+	c.Exchanges.Draw(ctx)
+	c.Crews.Draw(ctx)
+	c.Faults.Draw(ctx)
+	if c.Info != nil {
+		c.Info.Draw(ctx)
+	}
+}
+
+// buttonColumn is a column of Buttons, one for each of a list of IDs, up to crewRows
+type buttonColumn struct {
+	Origin  mgl32.Vec2
+	Buttons []*ui.Button
+	OnClick func(id int)
+
+	ids []int
+}
+
+// Delete frees all resources owned by the buttonColumn
+func (c *buttonColumn) Delete() {
+	for _, b := range c.Buttons {
+		b.Delete()
+	}
+	c.Buttons = nil
+	c.ids = nil
+}
+
+// Update makes a Button for each of ids, labelled and colored by label or
+// highlighted if it is selected, and handles their clicks
+func (c *buttonColumn) Update(ctx *context.Update, ids []int, selected int, label func(id int) (string, color.Color)) {
+	if len(ids) > crewRows {
+		ids = ids[:crewRows]
+	}
+	c.ids = ids
+
+	for len(c.Buttons) < len(ids) {
+		b := ui.NewButton(" ", "ui/default.ttf", 16.0, color.White, crewColor)
+		if b == nil {
+			return
+		}
+		row := len(c.Buttons)
+		b.SetPosition(c.Origin.Add(mgl32.Vec2{0, float32(row) * crewCell.Y()}))
+		b.SetSize(mgl32.Vec2{crewCell.X() - 10, b.GetSize().Y()})
+		b.OnClick = func() {
+			if row < len(c.ids) {
+				c.OnClick(c.ids[row])
+			}
+		}
+		c.Buttons = append(c.Buttons, b)
+	}
+	for len(c.Buttons) > len(ids) {
+		c.Buttons[len(c.Buttons)-1].Delete()
+		c.Buttons = c.Buttons[:len(c.Buttons)-1]
+	}
+
+	for i, id := range ids {
+		text, bg := label(id)
+		if id == selected {
+			bg = crewSelectedColor
+		}
+		c.Buttons[i].SetText(text)
+		c.Buttons[i].SetColor(bg)
+		c.Buttons[i].Update(ctx)
+	}
+}
+
+// Draw renders the Buttons
+func (c *buttonColumn) Draw(ctx *context.Render) {
+	for _, b := range c.Buttons {
+		b.Draw(ctx)
+	}
+}
@@ -1,30 +1,149 @@
 uniform vec4 uAmbient;
 uniform vec4 uDiffuse;
 uniform vec4 uSpecular;
+uniform vec4 uEmissive;
+
+uniform float uShininess;
+uniform float uOpacity;
+uniform float uBumpMultiplier;
+
+// (scale.x, scale.y, offset.x, offset.y) from the MTL -s and -o options
+uniform vec4 uAmbientMapTransform;
+uniform vec4 uDiffuseMapTransform;
+uniform vec4 uSpecularMapTransform;
+uniform vec4 uEmissiveMapTransform;
+uniform vec4 uBumpMapTransform;
+uniform vec4 uSpecularHighlightMapTransform;
+uniform vec4 uAlphaMapTransform;
 
 uniform sampler2D uAmbientMap; 
 uniform sampler2D uDiffuseMap; 
 uniform sampler2D uSpecularMap; 
+uniform sampler2D uEmissiveMap;
+uniform sampler2D uBumpMap;
+uniform sampler2D uSpecularHighlightMap;
+uniform sampler2D uAlphaMap;
+
+uniform bool uHasBumpMap;
+uniform bool uHasSpecularHighlightMap;
+uniform bool uHasAlphaMap;
+
+// Must match light.Type
+#define LIGHT_AMBIENT     0
+#define LIGHT_DIRECTIONAL 1
+#define LIGHT_POINT       2
+#define LIGHT_SPOT        3
+
+// Must match light.MaxLights
+#define MAX_LIGHTS 8
+
+struct Light {
+    int Type;
+    vec3 Color;
+    vec3 Position;
+    vec3 Direction;
+    // (constant, linear, quadratic)
+    vec3 Attenuation;
+    // cos() of the (inner, outer) cone angles
+    vec2 Cutoff;
+};
+
+uniform Light uLights[MAX_LIGHTS];
+uniform int uLightCount;
 
 in vec4 p_Position;
 in vec4 p_Normal;
 in vec2 p_TexCoord;
+in mat3 p_TBN;
 
-in vec3 p_LightDir;
 in vec3 p_ViewDir;
 
 out vec4 _Color;
 
+vec2 mapUV(vec4 transform) {
+    vec2 uv = p_TexCoord * transform.xy + transform.zw;
+    return vec2(uv.x, 1.0 - uv.y);
+}
+
+vec3 getNormal() {
+    if (!uHasBumpMap) {
+        return normalize(p_Normal.xyz);
+    }
+
+    // MTL bump maps are height maps, so the normal tilts against the slope of
+    // the heights across a texel, scaled by -bm. V is flipped by mapUV.
+    vec2 uv = mapUV(uBumpMapTransform);
+    vec2 texel = 1.0 / vec2(textureSize(uBumpMap, 0));
+    float dU = texture(uBumpMap, uv + vec2(texel.x, 0.0)).r - texture(uBumpMap, uv - vec2(texel.x, 0.0)).r;
+    float dV = texture(uBumpMap, uv - vec2(0.0, texel.y)).r - texture(uBumpMap, uv + vec2(0.0, texel.y)).r;
+
+    vec3 normal = vec3(-dU, -dV, 2.0) * vec3(uBumpMultiplier, uBumpMultiplier, 1.0);
+    return normalize(p_TBN * normal);
+}
+
+float getShininess() {
+    float shininess = uShininess;
+    if (uHasSpecularHighlightMap) {
+        shininess *= texture(uSpecularHighlightMap, mapUV(uSpecularHighlightMapTransform)).r;
+    }
+    // pow(x, 0) is undefined for x == 0
+    return max(shininess, 1.0);
+}
+
+float getOpacity() {
+    float opacity = uOpacity;
+    if (uHasAlphaMap) {
+        opacity *= texture(uAlphaMap, mapUV(uAlphaMapTransform)).r;
+    }
+    return opacity;
+}
+
 void main() {
-    vec4 ambient = uAmbient + texture(uAmbientMap, p_TexCoord);
-    vec4 diffuse = uDiffuse + texture(uDiffuseMap, p_TexCoord);
-    vec4 specular = uSpecular + texture(uSpecularMap, p_TexCoord);
+    vec3 ambient = uAmbient.rgb + texture(uAmbientMap, mapUV(uAmbientMapTransform)).rgb;
+    vec3 diffuse = uDiffuse.rgb + texture(uDiffuseMap, mapUV(uDiffuseMapTransform)).rgb;
+    vec3 specular = uSpecular.rgb + texture(uSpecularMap, mapUV(uSpecularMapTransform)).rgb;
+    vec3 emissive = uEmissive.rgb + texture(uEmissiveMap, mapUV(uEmissiveMapTransform)).rgb;
+
+    vec3 normal = getNormal();
+    vec3 viewDir = normalize(p_ViewDir);
+    float shininess = getShininess();
+
+    vec3 color = emissive;
+    for (int i = 0; i < uLightCount && i < MAX_LIGHTS; ++i) {
+        Light light = uLights[i];
+
+        if (light.Type == LIGHT_AMBIENT) {
+            // Ka is usually black, so ambient light also lights the diffuse color
+            color += light.Color * (ambient + diffuse);
+            continue;
+        }
+
+        vec3 lightDir;
+        float attenuation = 1.0;
+        if (light.Type == LIGHT_DIRECTIONAL) {
+            lightDir = normalize(-light.Direction);
+        } else {
+            vec3 toLight = light.Position - p_Position.xyz;
+            float dist = length(toLight);
+            lightDir = toLight / dist;
+            attenuation = 1.0 / (light.Attenuation.x + light.Attenuation.y * dist + light.Attenuation.z * dist * dist);
+
+            if (light.Type == LIGHT_SPOT) {
+                float theta = dot(lightDir, normalize(-light.Direction));
+                attenuation *= smoothstep(light.Cutoff.y, light.Cutoff.x, theta);
+            }
+        }
+
+        float lambert = max(dot(normal, lightDir), 0.0);
+        if (lambert <= 0.0) {
+            continue;
+        }
 
-    vec3 normal = normalize(p_Normal.xyz);
-    diffuse *= max(dot(normal, p_LightDir), 0.0);
+        vec3 halfway = normalize(lightDir + viewDir);
+        float highlight = pow(max(dot(normal, halfway), 0.0), shininess);
 
-    vec3 halfway = normalize(p_LightDir + p_ViewDir);
-    specular *= pow(max(dot(normal, halfway), 0.0), 16.0) * 0.5;
+        color += light.Color * attenuation * (diffuse * lambert + specular * highlight);
+    }
 
-    _Color = texture(uDiffuseMap, p_TexCoord);
+    _Color = vec4(color, getOpacity());
 }
@@ -2,27 +2,34 @@ uniform mat4 uProjection;
 uniform mat4 uView;
 uniform mat4 uModel;
 
-uniform vec3 uLight;
 uniform vec3 uCamera;
 
 layout(location = 0) in vec3 _Position;
 layout(location = 1) in vec3 _Normal;
 layout(location = 2) in vec2 _TexCoord;
+layout(location = 3) in vec3 _Tangent;
+layout(location = 4) in vec3 _Bitangent;
 
 out vec4 p_Position;
 out vec4 p_Normal;
 out vec2 p_TexCoord;
+out mat3 p_TBN;
 
-out vec3 p_LightDir;
 out vec3 p_ViewDir;
 
 void main() {
+    mat3 normalMatrix = mat3(transpose(inverse(uModel)));
+
     p_Position = uModel * vec4(_Position, 1.0);
-    p_Normal   = uModel * vec4(_Normal, 1.0);
-    p_TexCoord = vec2(_TexCoord.x, 1.0 - _TexCoord.y);
+    p_Normal   = vec4(normalize(normalMatrix * _Normal), 0.0);
+    // Flipped per map in the fragment shader, after the map's transform
+    p_TexCoord = _TexCoord;
+
+    vec3 T = normalize(normalMatrix * _Tangent);
+    vec3 B = normalize(normalMatrix * _Bitangent);
+    p_TBN = mat3(T, B, p_Normal.xyz);
 
-    p_LightDir = normalize(uLight - p_Position.xyz);
-    p_ViewDir  = normalize(uCamera - p_Position.xyz);
+    p_ViewDir = normalize(uCamera - p_Position.xyz);
 
     gl_Position = uProjection * uView * uModel * vec4(_Position, 1);
 }
@@ -0,0 +1,312 @@
+package economy
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/WhoBrokeTheBuild/TelcomSim/network"
+	"github.com/WhoBrokeTheBuild/TelcomSim/traffic"
+)
+
+// Rates are the prices and costs the Economy runs on
+type Rates struct {
+	// PerMinute is earned for each minute of completed calls
+	PerMinute float64
+	// Subscription is earned per subscriber line each month
+	Subscription float64
+	// NodeOpex is the monthly running cost of each NodeType
+	NodeOpex map[network.NodeType]float64
+	// MaintenanceRate is the share of a Link's cost paid for its upkeep each month
+	MaintenanceRate float64
+	// ChurnFactor is the share of a region's lines lost in a month at a blocking rate of 1
+	ChurnFactor float64
+
+	// LoanRate is the annual interest rate of new loans, repaid over LoanMonths
+	LoanRate   float64
+	LoanMonths int
+	// MaxDebt is the most that can be owed at once
+	MaxDebt float64
+
+	// TrafficScale is how many seconds of real traffic each simulated second stands for
+	TrafficScale float64
+}
+
+// DefaultRates returns the Rates a new game starts with
+func DefaultRates() Rates {
+	return Rates{
+		PerMinute:    0.01,
+		Subscription: 12,
+		NodeOpex: map[network.NodeType]float64{
+			network.Exchange: 4000,
+			network.Tower:    1500,
+		},
+		MaintenanceRate: 0.002,
+		ChurnFactor:     0.5,
+		LoanRate:        0.08,
+		LoanMonths:      60,
+		MaxDebt:         2000000,
+		TrafficScale:    1,
+	}
+}
+
+// Economy is the company's money, charged and paid as the simulation runs
+type Economy struct {
+	Balance float64
+	// Month counts the months since the start, the current one being open
+	Month  int
+	Rates  Rates
+	Ledger Ledger
+	Loans  []*Loan
+
+	opening     float64
+	accrued     float64
+	callSeconds float64
+	regionStats map[network.NodeID]traffic.Stats
+}
+
+// NewEconomy returns a new Economy starting with balance
+func NewEconomy(balance float64) *Economy {
+	return &Economy{
+		Balance:     balance,
+		Rates:       DefaultRates(),
+		opening:     balance,
+		regionStats: map[network.NodeID]traffic.Stats{},
+	}
+}
+
+// SetBalance replaces the Balance, as the opening balance of the current month
+func (e *Economy) SetBalance(balance float64) {
+	e.Balance = balance
+	e.opening = balance
+}
+
+// Record adds an Entry to the current month and applies it to the Balance
+func (e *Economy) Record(c Category, amount float64, memo string) {
+	if amount == 0 {
+		return
+	}
+	e.Balance += amount
+	e.Ledger.Entries = append(e.Ledger.Entries, Entry{
+		Month:    e.Month,
+		Category: c,
+		Amount:   amount,
+		Memo:     memo,
+	})
+}
+
+// CanAfford returns whether amount can be spent without going into the red
+func (e *Economy) CanAfford(amount float64) bool {
+	return e.Balance >= amount
+}
+
+// Spend records capex, failing if it can't be afforded
+func (e *Economy) Spend(amount float64, memo string) error {
+	if !e.CanAfford(amount) {
+		return fmt.Errorf("Insufficient funds for [%v], need %.0f but have %.0f", memo, amount, e.Balance)
+	}
+	e.Record(Capex, -amount, memo)
+	return nil
+}
+
+// BuildNode pays for a Node
+func (e *Economy) BuildNode(node *network.Node) error {
+	return e.Spend(float64(node.Cost), fmt.Sprintf("Built %v %v", node.Type, node.Name))
+}
+
+// BuildLink pays for a Link
+func (e *Economy) BuildLink(link *network.Link) error {
+	return e.Spend(float64(link.Cost), fmt.Sprintf("Built %.1fkm %v link", link.Length, link.Type))
+}
+
+// GetDebt returns the principal owed on every Loan
+func (e *Economy) GetDebt() float64 {
+	total := 0.0
+	for _, l := range e.Loans {
+		total += l.Remaining
+	}
+	return total
+}
+
+// Borrow takes out a new Loan at the current Rates
+func (e *Economy) Borrow(amount float64) (*Loan, error) {
+	if amount <= 0 {
+		return nil, fmt.Errorf("Invalid loan amount [%v]", amount)
+	}
+	if e.GetDebt()+amount > e.Rates.MaxDebt {
+		return nil, fmt.Errorf("Loan of %.0f would exceed the %.0f debt limit", amount, e.Rates.MaxDebt)
+	}
+
+	l, err := NewLoan(amount, e.Rates.LoanRate, e.Rates.LoanMonths)
+	if err != nil {
+		return nil, err
+	}
+	e.Loans = append(e.Loans, l)
+	e.Record(LoanProceeds, amount, fmt.Sprintf("Borrowed %.0f at %.1f%%", amount, l.Rate*100))
+	return l, nil
+}
+
+// Repay pays off what is left of a Loan early
+func (e *Economy) Repay(l *Loan) error {
+	if !e.CanAfford(l.Remaining) {
+		return fmt.Errorf("Insufficient funds to repay loan, need %.0f but have %.0f", l.Remaining, e.Balance)
+	}
+	e.Record(LoanRepayment, -l.Remaining, "Repaid loan early")
+	l.Remaining = 0
+	e.removeRepaidLoans()
+	return nil
+}
+
+// Update earns the revenue of the calls completed since the last Update
+func (e *Economy) Update(calls *traffic.Engine) {
+	seconds := calls.Stats.CallSeconds - e.callSeconds
+	e.callSeconds = calls.Stats.CallSeconds
+	if seconds <= 0 {
+		return
+	}
+
+	revenue := seconds / 60 * e.Rates.TrafficScale * e.Rates.PerMinute
+	e.accrued += revenue
+	e.Balance += revenue
+}
+
+// EndMonth charges and pays everything due monthly, applies churn to the
+// subscribers, then closes the month in the Ledger and opens the next
+func (e *Economy) EndMonth(net *network.Network, calls *traffic.Engine) {
+	// Call revenue accrues every Update, it is entered once a month to keep the Ledger short
+	e.Balance -= e.accrued
+	e.Record(CallRevenue, e.accrued, "Call minutes")
+	e.accrued = 0
+
+	lines := 0
+	for _, node := range net.GetNodes() {
+		if opex, found := e.Rates.NodeOpex[node.Type]; found {
+			e.Record(Opex, -opex, fmt.Sprintf("Running %v", node.Name))
+		}
+		lines += node.Subscribers
+	}
+
+	maintenance := 0.0
+	for _, link := range net.GetLinks() {
+		maintenance += float64(link.Cost) * e.Rates.MaintenanceRate
+	}
+	e.Record(Maintenance, -maintenance, "Link upkeep")
+
+	e.Record(SubscriptionRevenue, float64(lines)*e.Rates.Subscription, fmt.Sprintf("%d lines", lines))
+
+	for _, l := range e.Loans {
+		interest := l.GetMonthlyInterest()
+		principal := l.Payment - interest
+		if principal > l.Remaining {
+			principal = l.Remaining
+		}
+		l.Remaining -= principal
+		e.Record(Interest, -interest, "Loan interest")
+		e.Record(LoanRepayment, -principal, "Loan installment")
+	}
+	e.removeRepaidLoans()
+
+	churned := e.applyChurn(net, calls)
+
+	month := Month{
+		Month:   e.Month,
+		Opening: e.opening,
+		Closing: e.Balance,
+		Lines:   lines - churned,
+		Churned: churned,
+	}
+	for _, entry := range e.Ledger.GetEntries(e.Month) {
+		month.Totals[entry.Category] += entry.Amount
+	}
+	e.Ledger.Months = append(e.Ledger.Months, month)
+
+	e.Month++
+	e.opening = e.Balance
+}
+
+// applyChurn removes lines from each region in proportion to its blocking rate this month,
+// returning how many were lost
+func (e *Economy) applyChurn(net *network.Network, calls *traffic.Engine) int {
+	churned := 0
+	for _, node := range net.GetNodesOfType(network.Subscriber) {
+		total := calls.GetRegionStats(node.ID)
+		month := total.Sub(e.regionStats[node.ID])
+		e.regionStats[node.ID] = total
+
+		lost := int(float64(node.Subscribers) * month.GetBlockingRate() * e.Rates.ChurnFactor)
+		if lost > node.Subscribers {
+			lost = node.Subscribers
+		}
+		node.Subscribers -= lost
+		churned += lost
+	}
+	return churned
+}
+
+// GetAccruedRevenue returns the call revenue earned this month, not yet entered in the Ledger
+func (e *Economy) GetAccruedRevenue() float64 {
+	return e.accrued
+}
+
+func (e *Economy) removeRepaidLoans() {
+	loans := e.Loans[:0]
+	for _, l := range e.Loans {
+		if !l.IsRepaid() {
+			loans = append(loans, l)
+		}
+	}
+	e.Loans = loans
+}
+
+// economyJSON is the saved form of an Economy
+type economyJSON struct {
+	Balance float64
+	Month   int
+	Rates   Rates
+	Ledger  Ledger
+	Loans   []*Loan
+
+	Opening     float64
+	Accrued     float64
+	CallSeconds float64
+	RegionStats map[network.NodeID]traffic.Stats
+}
+
+// MarshalJSON writes the Economy, including what it tracks within the current month
+func (e *Economy) MarshalJSON() ([]byte, error) {
+	return json.Marshal(economyJSON{
+		Balance:     e.Balance,
+		Month:       e.Month,
+		Rates:       e.Rates,
+		Ledger:      e.Ledger,
+		Loans:       e.Loans,
+		Opening:     e.opening,
+		Accrued:     e.accrued,
+		CallSeconds: e.callSeconds,
+		RegionStats: e.regionStats,
+	})
+}
+
+// UnmarshalJSON replaces the Economy with one written by MarshalJSON
+func (e *Economy) UnmarshalJSON(data []byte) error {
+	ej := economyJSON{}
+	err := json.Unmarshal(data, &ej)
+	if err != nil {
+		return err
+	}
+
+	*e = Economy{
+		Balance:     ej.Balance,
+		Month:       ej.Month,
+		Rates:       ej.Rates,
+		Ledger:      ej.Ledger,
+		Loans:       ej.Loans,
+		opening:     ej.Opening,
+		accrued:     ej.Accrued,
+		callSeconds: ej.CallSeconds,
+		regionStats: ej.RegionStats,
+	}
+	if e.regionStats == nil {
+		e.regionStats = map[network.NodeID]traffic.Stats{}
+	}
+	return nil
+}
@@ -0,0 +1,234 @@
+package economy
+
+import (
+	"math"
+	"testing"
+
+	"github.com/WhoBrokeTheBuild/TelcomSim/network"
+	"github.com/WhoBrokeTheBuild/TelcomSim/traffic"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+func TestCapex(t *testing.T) {
+	net := network.NewNetwork()
+	a := net.AddExchange("A", mgl32.Vec2{0, 0})
+	b := net.AddExchange("B", mgl32.Vec2{3, 0})
+	link, _ := net.Connect(a.ID, b.ID, network.Copper)
+
+	e := NewEconomy(300000)
+	if err := e.BuildNode(a); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.BuildLink(link); err != nil {
+		t.Fatal(err)
+	}
+	// What is left can't pay for a second exchange
+	if err := e.BuildNode(b); err == nil {
+		t.Errorf("built an exchange that can't be afforded")
+	}
+
+	want := -float64(a.Cost + link.Cost)
+	if got := e.Ledger.GetTotal(0, Capex); got != want {
+		t.Errorf("Capex total = %v, want %v", got, want)
+	}
+	if got := e.Balance; got != 300000+want {
+		t.Errorf("Balance = %v, want %v", got, 300000+want)
+	}
+	if n := len(e.Ledger.GetEntries(0)); n != 2 {
+		t.Errorf("got %d entries, want 2 with nothing for the failed build", n)
+	}
+}
+
+func TestEndMonth(t *testing.T) {
+	net := network.NewNetwork()
+	a := net.AddExchange("A", mgl32.Vec2{0, 0})
+	tower := net.AddTower("T", mgl32.Vec2{3, 0})
+	s := net.AddSubscriber("S", mgl32.Vec2{0, 1}, 100)
+	link, _ := net.Connect(a.ID, tower.ID, network.Copper)
+	net.Connect(s.ID, a.ID, network.Copper)
+	calls := traffic.NewEngine(net, 1)
+
+	e := NewEconomy(1000000)
+	e.Rates.PerMinute = 1
+	e.Rates.TrafficScale = 2
+
+	// 10 minutes of calls, each standing for 2
+	calls.Stats.CallSeconds = 600
+	e.Update(calls)
+	if got := e.GetAccruedRevenue(); got != 20 {
+		t.Errorf("GetAccruedRevenue() = %v, want 20", got)
+	}
+	// Revenue is only counted once
+	e.Update(calls)
+
+	e.EndMonth(net, calls)
+
+	m := e.Ledger.GetMonth(0)
+	if m == nil {
+		t.Fatalf("month 0 wasn't closed")
+	}
+	maintenance := 0.0
+	for _, l := range net.GetLinks() {
+		maintenance -= float64(l.Cost) * e.Rates.MaintenanceRate
+	}
+	want := map[Category]float64{
+		Opex:                -5500,
+		Maintenance:         maintenance,
+		SubscriptionRevenue: 1200,
+		CallRevenue:         20,
+	}
+	for c, total := range want {
+		if math.Abs(m.Totals[c]-total) > 1e-6 {
+			t.Errorf("%v total = %v, want %v", c, m.Totals[c], total)
+		}
+		if got := e.Ledger.GetTotal(0, c); math.Abs(got-total) > 1e-6 {
+			t.Errorf("GetTotal(0, %v) = %v, want %v", c, got, total)
+		}
+	}
+	if link.Cost == 0 || maintenance == 0 {
+		t.Errorf("links have no upkeep to test")
+	}
+
+	total := 1200 + 20 - 5500 + maintenance
+	if math.Abs(m.GetNet()-total) > 1e-6 {
+		t.Errorf("GetNet() = %v, want %v", m.GetNet(), total)
+	}
+	if m.Opening != 1000000 || math.Abs(m.Closing-e.Balance) > 1e-6 {
+		t.Errorf("month ran from %v to %v, want from 1000000 to the balance %v", m.Opening, m.Closing, e.Balance)
+	}
+	if m.GetIncome() != 1220 || math.Abs(m.GetExpenses()-(5500-maintenance)) > 1e-6 {
+		t.Errorf("income %v and expenses %v, want 1220 and %v", m.GetIncome(), m.GetExpenses(), 5500-maintenance)
+	}
+	if m.Lines != 100 || m.Churned != 0 {
+		t.Errorf("%d lines and %d churned, want 100 and none without blocking", m.Lines, m.Churned)
+	}
+
+	// The next month is open, and starts from the close of the last
+	if e.Ledger.GetMonth(1) != nil {
+		t.Errorf("month 1 is closed before it ended")
+	}
+	e.Spend(1000, "Something")
+	if entries := e.Ledger.GetEntries(1); len(entries) != 1 || entries[0].Amount != -1000 {
+		t.Errorf("month 1 entries = %v, want the one spend", entries)
+	}
+	if n := len(e.Ledger.GetEntries(0)); n != 5 {
+		t.Errorf("month 0 has %d entries, want 5", n)
+	}
+}
+
+func TestChurn(t *testing.T) {
+	// With no trunk between the exchanges every call is blocked
+	net := network.NewNetwork()
+	a := net.AddExchange("A", mgl32.Vec2{0, 0})
+	b := net.AddExchange("B", mgl32.Vec2{4, 0})
+	sa := net.AddSubscriber("SA", mgl32.Vec2{0, 1}, 150)
+	sb := net.AddSubscriber("SB", mgl32.Vec2{4, 1}, 150)
+	net.Connect(sa.ID, a.ID, network.Fiber)
+	net.Connect(sb.ID, b.ID, network.Fiber)
+
+	calls := traffic.NewEngine(net, 1)
+	calls.Update(3600)
+	if calls.GetRegionStats(sa.ID).GetBlockingRate() != 1 {
+		t.Fatalf("calls weren't all blocked")
+	}
+
+	e := NewEconomy(1000000)
+	e.EndMonth(net, calls)
+
+	// Half the lines are lost at a blocking rate of 1
+	m := e.Ledger.GetMonth(0)
+	if sa.Subscribers != 75 || sb.Subscribers != 75 {
+		t.Errorf("regions have %d and %d lines, want 75 each", sa.Subscribers, sb.Subscribers)
+	}
+	if m.Churned != 150 || m.Lines != 150 {
+		t.Errorf("month churned %d leaving %d lines, want 150 leaving 150", m.Churned, m.Lines)
+	}
+
+	// Only the calls of the month count, so a month without calls loses no one
+	e.EndMonth(net, calls)
+	if m := e.Ledger.GetMonth(1); m.Churned != 0 {
+		t.Errorf("month 1 churned %d lines without any calls", m.Churned)
+	}
+}
+
+func TestLoanRepayment(t *testing.T) {
+	tests := []struct {
+		name   string
+		rate   float64
+		months int
+		// interest is the total paid over the term
+		interest float64
+	}{
+		{name: "without interest", rate: 0, months: 10, interest: 0},
+		{name: "with interest", rate: 0.12, months: 12, interest: 6618.55},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			net := network.NewNetwork()
+			calls := traffic.NewEngine(net, 1)
+			e := NewEconomy(0)
+			e.Rates.LoanRate = tt.rate
+			e.Rates.LoanMonths = tt.months
+
+			l, err := e.Borrow(100000)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if e.Balance != 100000 || e.GetDebt() != 100000 {
+				t.Fatalf("borrowed to a balance of %v and debt of %v, want 100000", e.Balance, e.GetDebt())
+			}
+
+			interest, principal := 0.0, 0.0
+			lastInterest := math.Inf(1)
+			for m := 0; m < tt.months; m++ {
+				e.EndMonth(net, calls)
+				month := e.Ledger.GetMonth(m)
+				interest -= month.Totals[Interest]
+				principal -= month.Totals[LoanRepayment]
+
+				// Each installment is the same, less of it interest as the principal falls
+				if paid := -month.Totals[Interest] - month.Totals[LoanRepayment]; math.Abs(paid-l.Payment) > 0.01 {
+					t.Errorf("month %d: paid %.2f, want the installment %.2f", m, paid, l.Payment)
+				}
+				if -month.Totals[Interest] > lastInterest {
+					t.Errorf("month %d: interest rose to %.2f", m, -month.Totals[Interest])
+				}
+				lastInterest = -month.Totals[Interest]
+			}
+
+			if len(e.Loans) != 0 || e.GetDebt() != 0 {
+				t.Errorf("%d loans owing %v after the term, want none", len(e.Loans), e.GetDebt())
+			}
+			if math.Abs(principal-100000) > 0.01 {
+				t.Errorf("repaid %.2f principal, want 100000", principal)
+			}
+			if math.Abs(interest-tt.interest) > 0.01 {
+				t.Errorf("paid %.2f interest, want %.2f", interest, tt.interest)
+			}
+		})
+	}
+}
+
+func TestBorrowLimits(t *testing.T) {
+	e := NewEconomy(0)
+	e.Rates.MaxDebt = 150000
+
+	if _, err := e.Borrow(100000); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := e.Borrow(100000); err == nil {
+		t.Errorf("borrowed past the debt limit")
+	}
+	if _, err := e.Borrow(-10); err == nil {
+		t.Errorf("borrowed a negative amount")
+	}
+
+	e.Rates.LoanMonths = 0
+	if _, err := e.Borrow(1000); err == nil {
+		t.Errorf("borrowed over a term of 0 months")
+	}
+	if len(e.Loans) != 1 || e.Balance != 100000 {
+		t.Errorf("%d loans and a balance of %v, want only the first loan", len(e.Loans), e.Balance)
+	}
+}
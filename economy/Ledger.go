@@ -0,0 +1,145 @@
+package economy
+
+import (
+	"fmt"
+)
+
+// Category groups the Entries of a Ledger
+type Category int
+
+const (
+	// Capex is spent building Nodes and Links
+	Capex Category = iota
+	// Opex is the running cost of the Nodes
+	Opex
+	// Maintenance is the upkeep of the Links, a share of what they cost
+	Maintenance
+	// CallRevenue is earned per minute of completed calls
+	CallRevenue
+	// SubscriptionRevenue is earned per subscriber line each month
+	SubscriptionRevenue
+	// LoanProceeds is borrowed money
+	LoanProceeds
+	// LoanRepayment is the principal paid back on loans
+	LoanRepayment
+	// Interest is paid on loans
+	Interest
+	// Repairs is spent on crews and the parts they fit
+	Repairs
+	// Research is spent on developing new technology
+	Research
+
+	numCategories
+)
+
+// String returns the name of the Category
+func (c Category) String() string {
+	switch c {
+	case Capex:
+		return "Capex"
+	case Opex:
+		return "Opex"
+	case Maintenance:
+		return "Maintenance"
+	case CallRevenue:
+		return "Call Revenue"
+	case SubscriptionRevenue:
+		return "Subscription Revenue"
+	case LoanProceeds:
+		return "Loan Proceeds"
+	case LoanRepayment:
+		return "Loan Repayment"
+	case Interest:
+		return "Interest"
+	case Repairs:
+		return "Repairs"
+	case Research:
+		return "Research"
+	}
+	return fmt.Sprintf("Category(%d)", int(c))
+}
+
+// Entry is one transaction, Amount is positive for income and negative for expenses
+type Entry struct {
+	Month    int
+	Category Category
+	Amount   float64
+	Memo     string
+}
+
+// Month is the summary of the Entries of one month
+type Month struct {
+	Month  int
+	Totals [numCategories]float64
+	// Opening and Closing are the balance at the start and end of the month
+	Opening float64
+	Closing float64
+	// Lines is the subscriber lines at the end of the month, after Churned were lost
+	Lines   int
+	Churned int
+}
+
+// GetNet returns the income less the expenses of the Month
+func (m *Month) GetNet() float64 {
+	return m.Closing - m.Opening
+}
+
+// GetIncome returns the sum of the positive Totals
+func (m *Month) GetIncome() float64 {
+	total := 0.0
+	for _, t := range m.Totals {
+		if t > 0 {
+			total += t
+		}
+	}
+	return total
+}
+
+// GetExpenses returns the sum of the negative Totals, as a positive number
+func (m *Month) GetExpenses() float64 {
+	total := 0.0
+	for _, t := range m.Totals {
+		if t < 0 {
+			total -= t
+		}
+	}
+	return total
+}
+
+// Ledger records every Entry and a Month summary of each month closed
+type Ledger struct {
+	Entries []Entry
+	Months  []Month
+}
+
+// GetMonth returns the summary of a closed month, or nil
+func (l *Ledger) GetMonth(month int) *Month {
+	for i := range l.Months {
+		if l.Months[i].Month == month {
+			return &l.Months[i]
+		}
+	}
+	return nil
+}
+
+// GetEntries returns the Entries of a month, closed or not
+func (l *Ledger) GetEntries(month int) []Entry {
+	entries := []Entry{}
+	for _, e := range l.Entries {
+		if e.Month == month {
+			entries = append(entries, e)
+		}
+	}
+	return entries
+}
+
+// GetTotal returns the total of a Category in a month, closed or not
+func (l *Ledger) GetTotal(month int, c Category) float64 {
+	total := 0.0
+	for _, e := range l.Entries {
+		if e.Month == month && e.Category == c {
+			total += e.Amount
+		}
+	}
+	return total
+}
@@ -0,0 +1,50 @@
+package economy
+
+import (
+	"fmt"
+	"math"
+)
+
+// Loan is borrowed money repaid in equal monthly installments
+type Loan struct {
+	Principal float64
+	// Rate is the annual interest rate, 0.08 being 8%
+	Rate float64
+	// Months is the term, Remaining is the principal still owed
+	Months    int
+	Remaining float64
+	// Payment is the monthly installment, interest included
+	Payment float64
+}
+
+// NewLoan returns a new Loan of amount at the annual rate, repaid over months
+func NewLoan(amount, rate float64, months int) (*Loan, error) {
+	if months <= 0 {
+		return nil, fmt.Errorf("Invalid loan term [%v] months", months)
+	}
+
+	l := &Loan{
+		Principal: amount,
+		Rate:      rate,
+		Months:    months,
+		Remaining: amount,
+	}
+
+	r := rate / 12
+	if r == 0 {
+		l.Payment = amount / float64(months)
+	} else {
+		l.Payment = amount * r / (1 - math.Pow(1+r, -float64(months)))
+	}
+	return l, nil
+}
+
+// GetMonthlyInterest returns the interest due this month on the Remaining principal
+func (l *Loan) GetMonthlyInterest() float64 {
+	return l.Remaining * l.Rate / 12
+}
+
+// IsRepaid returns whether nothing is owed on the Loan
+func (l *Loan) IsRepaid() bool {
+	return l.Remaining < 0.005
+}
@@ -0,0 +1,56 @@
+package economy
+
+import (
+	"math"
+	"testing"
+)
+
+func TestNewLoan(t *testing.T) {
+	tests := []struct {
+		name    string
+		amount  float64
+		rate    float64
+		months  int
+		payment float64
+		// interest is the total paid over the term
+		interest float64
+	}{
+		{name: "without interest", amount: 120000, rate: 0, months: 12, payment: 10000, interest: 0},
+		{name: "with interest", amount: 100000, rate: 0.12, months: 12, payment: 8884.88, interest: 6618.55},
+		{name: "one month", amount: 5000, rate: 0.12, months: 1, payment: 5050, interest: 50},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, err := NewLoan(tt.amount, tt.rate, tt.months)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if math.Abs(l.Payment-tt.payment) > 0.01 {
+				t.Errorf("Payment = %.2f, want %.2f", l.Payment, tt.payment)
+			}
+
+			// Amortize by hand, as EndMonth does
+			interest := 0.0
+			for m := 0; m < tt.months; m++ {
+				i := l.GetMonthlyInterest()
+				interest += i
+				l.Remaining -= l.Payment - i
+			}
+			if !l.IsRepaid() {
+				t.Errorf("%.2f still owed after %d payments", l.Remaining, tt.months)
+			}
+			if math.Abs(interest-tt.interest) > 0.01 {
+				t.Errorf("paid %.2f interest, want %.2f", interest, tt.interest)
+			}
+		})
+	}
+}
+
+func TestNewLoanInvalidTerm(t *testing.T) {
+	for _, months := range []int{0, -12} {
+		if l, err := NewLoan(1000, 0.08, months); err == nil {
+			t.Errorf("NewLoan over %d months = %+v, want an error", months, l)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package ecs
+
+import "fmt"
+
+// Entity identifies a thing in a World, 0 is never used
+type Entity uint32
+
+// SparseSet is a set of Entities packed into a dense array, with constant time
+// insertion, removal and lookup. A component Store keeps its components in a
+// second dense array in the same order, moving them as the SparseSet moves Entities.
+type SparseSet struct {
+	// sparse maps an Entity to its index in dense plus one, 0 for absent
+	sparse []int32
+	dense  []Entity
+}
+
+// Has returns whether e is in the set
+func (s *SparseSet) Has(e Entity) bool {
+	return s.IndexOf(e) >= 0
+}
+
+// IndexOf returns the index of e in the dense array, or -1 if it isn't in the set
+func (s *SparseSet) IndexOf(e Entity) int {
+	if int(e) >= len(s.sparse) {
+		return -1
+	}
+	return int(s.sparse[e]) - 1
+}
+
+// Insert adds e to the end of the dense array and returns its index, or returns
+// its index and false if it was already in the set
+func (s *SparseSet) Insert(e Entity) (int, bool) {
+	if i := s.IndexOf(e); i >= 0 {
+		return i, false
+	}
+
+	if int(e) >= len(s.sparse) {
+		size := len(s.sparse)*2 + 1
+		if size <= int(e) {
+			size = int(e) + 1
+		}
+		sparse := make([]int32, size)
+		copy(sparse, s.sparse)
+		s.sparse = sparse
+	}
+
+	s.dense = append(s.dense, e)
+	s.sparse[e] = int32(len(s.dense))
+	return len(s.dense) - 1, true
+}
+
+// Delete removes e by moving the last Entity into its place, and returns the index
+// it was at, or false if it wasn't in the set. Stores do the same with their data.
+func (s *SparseSet) Delete(e Entity) (int, bool) {
+	i := s.IndexOf(e)
+	if i < 0 {
+		return -1, false
+	}
+
+	last := len(s.dense) - 1
+	moved := s.dense[last]
+	s.dense[i] = moved
+	s.sparse[moved] = int32(i + 1)
+	s.dense = s.dense[:last]
+	s.sparse[e] = 0
+	return i, true
+}
+
+// Len returns the number of Entities in the set
+func (s *SparseSet) Len() int {
+	return len(s.dense)
+}
+
+// GetEntities returns the Entities in dense order, which must not be modified
+func (s *SparseSet) GetEntities() []Entity {
+	if s.dense == nil {
+		// A new set saves the same as one emptied or loaded empty
+		return []Entity{}
+	}
+	return s.dense
+}
+
+// Reset replaces the set with entities, in order
+func (s *SparseSet) Reset(entities []Entity) error {
+	s.sparse = nil
+	s.dense = make([]Entity, 0, len(entities))
+	for _, e := range entities {
+		if e == 0 {
+			return fmt.Errorf("Invalid entity [%v]", e)
+		}
+		if _, added := s.Insert(e); !added {
+			return fmt.Errorf("Duplicate entity [%v]", e)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,166 @@
+package ecs
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// checkIndices fails t if the sparse indices of s don't match its dense order
+func checkIndices(t *testing.T, s *SparseSet) {
+	t.Helper()
+	for i, e := range s.GetEntities() {
+		if got := s.IndexOf(e); got != i {
+			t.Errorf("IndexOf(%v) = %d, want %d", e, got, i)
+		}
+	}
+}
+
+func TestDelete(t *testing.T) {
+	tests := []struct {
+		name   string
+		insert []Entity
+		delete []Entity
+		want   []Entity
+	}{
+		{
+			name:   "last",
+			insert: []Entity{1, 2, 3},
+			delete: []Entity{3},
+			want:   []Entity{1, 2},
+		},
+		{
+			name:   "first moves the last into its place",
+			insert: []Entity{1, 2, 3, 4},
+			delete: []Entity{1},
+			want:   []Entity{4, 2, 3},
+		},
+		{
+			name:   "middle",
+			insert: []Entity{5, 9, 2, 7},
+			delete: []Entity{9},
+			want:   []Entity{5, 7, 2},
+		},
+		{
+			name:   "several",
+			insert: []Entity{1, 2, 3, 4, 5},
+			delete: []Entity{2, 1, 5},
+			want:   []Entity{4, 3},
+		},
+		{
+			name:   "only",
+			insert: []Entity{8},
+			delete: []Entity{8},
+			want:   []Entity{},
+		},
+		{
+			name:   "absent",
+			insert: []Entity{1, 2},
+			delete: []Entity{3, 100},
+			want:   []Entity{1, 2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var s SparseSet
+			for _, e := range tt.insert {
+				s.Insert(e)
+			}
+			for _, e := range tt.delete {
+				i := s.IndexOf(e)
+				got, ok := s.Delete(e)
+				if got != i || ok != (i >= 0) {
+					t.Errorf("Delete(%v) = %d, %v, want %d, %v", e, got, ok, i, i >= 0)
+				}
+				if s.Has(e) {
+					t.Errorf("%v is still in the set", e)
+				}
+			}
+
+			if got := s.GetEntities(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("entities are %v, want %v", got, tt.want)
+			}
+			checkIndices(t, &s)
+		})
+	}
+}
+
+func TestDeleteReinsert(t *testing.T) {
+	var s SparseSet
+	for _, e := range []Entity{1, 2, 3} {
+		s.Insert(e)
+	}
+	s.Delete(1)
+
+	if i, added := s.Insert(1); i != 2 || !added {
+		t.Errorf("Insert(1) = %d, %v, want it added at the end", i, added)
+	}
+	if i, added := s.Insert(3); i != 0 || added {
+		t.Errorf("Insert(3) = %d, %v, want it found where 1 was", i, added)
+	}
+	checkIndices(t, &s)
+}
+
+func TestStoreRemove(t *testing.T) {
+	s := NewVec2Store()
+	for e := Entity(1); e <= 4; e++ {
+		s.Add(e, mgl32.Vec2{float32(e), 0})
+	}
+	s.Remove(2)
+	s.Remove(1)
+
+	if s.Remove(1) {
+		t.Errorf("removed 1 twice")
+	}
+	if len(s.Data) != s.Set.Len() {
+		t.Fatalf("%d components for %d entities", len(s.Data), s.Set.Len())
+	}
+	// Each component moves with its Entity
+	for _, e := range s.Set.GetEntities() {
+		if v := s.Get(e); v == nil || v.X() != float32(e) {
+			t.Errorf("Get(%v) = %v, want {%v 0}", e, v, e)
+		}
+	}
+}
+
+func TestReset(t *testing.T) {
+	tests := []struct {
+		name     string
+		entities []Entity
+		err      bool
+	}{
+		{name: "empty", entities: []Entity{}},
+		{name: "in order", entities: []Entity{3, 1, 2}},
+		{name: "sparse", entities: []Entity{1000, 4}},
+		{name: "zero", entities: []Entity{1, 0, 2}, err: true},
+		{name: "duplicate", entities: []Entity{1, 2, 1}, err: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var s SparseSet
+			s.Insert(7)
+
+			err := s.Reset(tt.entities)
+			if tt.err {
+				if err == nil {
+					t.Errorf("Reset(%v) succeeded, want an error", tt.entities)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := s.GetEntities(); !reflect.DeepEqual(got, tt.entities) {
+				t.Errorf("entities are %v, want %v", got, tt.entities)
+			}
+			if s.Has(7) {
+				t.Errorf("7 is still in the set after Reset")
+			}
+			checkIndices(t, &s)
+		})
+	}
+}
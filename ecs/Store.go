@@ -0,0 +1,65 @@
+package ecs
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// Store holds one type of component for some of the Entities of a World. Each
+// component type has its own Store type with a dense array of its values, kept
+// in the order of the Store's SparseSet.
+type Store interface {
+	// GetSet returns the Entities with a component in the Store
+	GetSet() *SparseSet
+	// Remove removes the component of e, returning false if it had none
+	Remove(e Entity) bool
+
+	json.Marshaler
+	json.Unmarshaler
+}
+
+// storeJSON is the saved form of a Store, with a component for each Entity
+type storeJSON struct {
+	Entities []Entity
+	Data     json.RawMessage
+}
+
+// MarshalStore writes the Entities of set with data, the Store's dense array in the same order
+func MarshalStore(set *SparseSet, data interface{}) ([]byte, error) {
+	b, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(storeJSON{
+		Entities: set.GetEntities(),
+		Data:     b,
+	})
+}
+
+// UnmarshalStore reads what MarshalStore wrote, resetting set and decoding the
+// components into data, a pointer to the Store's dense array
+func UnmarshalStore(b []byte, set *SparseSet, data interface{}) error {
+	var sj storeJSON
+	err := json.Unmarshal(b, &sj)
+	if err != nil {
+		return err
+	}
+
+	err = set.Reset(sj.Entities)
+	if err != nil {
+		return err
+	}
+	if len(sj.Data) == 0 {
+		sj.Data = []byte("[]")
+	}
+	err = json.Unmarshal(sj.Data, data)
+	if err != nil {
+		return err
+	}
+
+	if n := reflect.ValueOf(data).Elem().Len(); n != set.Len() {
+		return fmt.Errorf("Store has %d components for %d entities", n, set.Len())
+	}
+	return nil
+}
@@ -0,0 +1,21 @@
+package ecs
+
+// System updates the components of a World every tick
+type System interface {
+	Update(w *World, dt float64)
+}
+
+// SystemFunc is a function used as a System
+type SystemFunc func(w *World, dt float64)
+
+// Update calls f
+func (f SystemFunc) Update(w *World, dt float64) {
+	f(w, dt)
+}
+
+// systemEntry is a System added to a World
+type systemEntry struct {
+	Name   string
+	Order  int
+	System System
+}
@@ -0,0 +1,65 @@
+package ecs
+
+import (
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// Vec2Store is a Store of mgl32.Vec2 components, such as positions on the map
+type Vec2Store struct {
+	Set  SparseSet
+	Data []mgl32.Vec2
+}
+
+// NewVec2Store returns a new empty Vec2Store
+func NewVec2Store() *Vec2Store {
+	return &Vec2Store{
+		Data: []mgl32.Vec2{},
+	}
+}
+
+// GetSet returns the Entities with a component in the Store
+func (s *Vec2Store) GetSet() *SparseSet {
+	return &s.Set
+}
+
+// Add sets the component of e to v
+func (s *Vec2Store) Add(e Entity, v mgl32.Vec2) {
+	i, added := s.Set.Insert(e)
+	if added {
+		s.Data = append(s.Data, v)
+	} else {
+		s.Data[i] = v
+	}
+}
+
+// Get returns the component of e, valid until the Store is next added to or
+// removed from, or nil if e has none
+func (s *Vec2Store) Get(e Entity) *mgl32.Vec2 {
+	if i := s.Set.IndexOf(e); i >= 0 {
+		return &s.Data[i]
+	}
+	return nil
+}
+
+// Remove removes the component of e, returning false if it had none
+func (s *Vec2Store) Remove(e Entity) bool {
+	i, ok := s.Set.Delete(e)
+	if !ok {
+		return false
+	}
+	last := len(s.Data) - 1
+	s.Data[i] = s.Data[last]
+	s.Data = s.Data[:last]
+	return true
+}
+
+// MarshalJSON writes the Entities of the Store and their components
+func (s *Vec2Store) MarshalJSON() ([]byte, error) {
+	return MarshalStore(&s.Set, s.Data)
+}
+
+// UnmarshalJSON replaces the Store with one written by MarshalJSON
+func (s *Vec2Store) UnmarshalJSON(b []byte) error {
+	s.Data = []mgl32.Vec2{}
+	return UnmarshalStore(b, &s.Set, &s.Data)
+}
@@ -0,0 +1,211 @@
+package ecs
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// World is a set of Entities, the Stores of their components, and the Systems
+// updating them. Entities are never reused, so a stale Entity never refers to
+// a newer one.
+type World struct {
+	next     Entity
+	entities SparseSet
+
+	stores     map[string]Store
+	storeNames []string
+	systems    []systemEntry
+}
+
+// NewWorld returns a new World with no Entities, Stores or Systems
+func NewWorld() *World {
+	return &World{
+		next:       1,
+		stores:     map[string]Store{},
+		storeNames: []string{},
+		systems:    []systemEntry{},
+	}
+}
+
+// Create returns a new Entity with no components
+func (w *World) Create() Entity {
+	e := w.next
+	w.next++
+	w.entities.Insert(e)
+	return e
+}
+
+// Destroy removes e and all of its components
+func (w *World) Destroy(e Entity) {
+	if _, ok := w.entities.Delete(e); !ok {
+		return
+	}
+	for _, name := range w.storeNames {
+		w.stores[name].Remove(e)
+	}
+}
+
+// IsAlive returns whether e has been created and not destroyed
+func (w *World) IsAlive(e Entity) bool {
+	return w.entities.Has(e)
+}
+
+// GetEntities returns every live Entity, which must not be modified
+func (w *World) GetEntities() []Entity {
+	return w.entities.GetEntities()
+}
+
+// Register adds a Store of components under name, which it is saved as
+func (w *World) Register(name string, s Store) error {
+	if _, found := w.stores[name]; found {
+		return fmt.Errorf("Store [%v] is already registered", name)
+	}
+	w.stores[name] = s
+	w.storeNames = append(w.storeNames, name)
+	return nil
+}
+
+// GetStore returns the Store registered under name, or nil
+func (w *World) GetStore(name string) Store {
+	return w.stores[name]
+}
+
+// AddSystem adds a System, run by Update after the Systems of a lower order,
+// and after the Systems of the same order added before it
+func (w *World) AddSystem(name string, order int, s System) {
+	w.systems = append(w.systems, systemEntry{Name: name, Order: order, System: s})
+	sort.SliceStable(w.systems, func(i, j int) bool {
+		return w.systems[i].Order < w.systems[j].Order
+	})
+}
+
+// RemoveSystem removes the System added under name
+func (w *World) RemoveSystem(name string) {
+	for i := range w.systems {
+		if w.systems[i].Name == name {
+			w.systems = append(w.systems[:i], w.systems[i+1:]...)
+			return
+		}
+	}
+}
+
+// GetSystemNames returns the names of the Systems, in the order they run
+func (w *World) GetSystemNames() []string {
+	names := make([]string, 0, len(w.systems))
+	for _, s := range w.systems {
+		names = append(names, s.Name)
+	}
+	return names
+}
+
+// Update runs every System, in order
+func (w *World) Update(dt float64) {
+	for _, s := range w.systems {
+		s.System.Update(w, dt)
+	}
+}
+
+// Each calls fn for every Entity with a component in all of stores, in the dense
+// order of the smallest Store. Components must not be added or removed from the
+// Stores during Each, use Query for that.
+func (w *World) Each(fn func(Entity), stores ...Store) {
+	if len(stores) == 0 {
+		return
+	}
+
+	smallest := stores[0].GetSet()
+	for _, s := range stores[1:] {
+		if s.GetSet().Len() < smallest.Len() {
+			smallest = s.GetSet()
+		}
+	}
+
+	for _, e := range smallest.GetEntities() {
+		match := true
+		for _, s := range stores {
+			if !s.GetSet().Has(e) {
+				match = false
+				break
+			}
+		}
+		if match {
+			fn(e)
+		}
+	}
+}
+
+// Query returns every Entity with a component in all of stores, in the order of Each
+func (w *World) Query(stores ...Store) []Entity {
+	matches := []Entity{}
+	w.Each(func(e Entity) {
+		matches = append(matches, e)
+	}, stores...)
+	return matches
+}
+
+// worldJSON is the saved form of a World
+type worldJSON struct {
+	Next     Entity
+	Entities []Entity
+	Stores   map[string]json.RawMessage
+}
+
+// MarshalJSON writes the Entities and the components in every Store. Systems are
+// code, so they aren't saved.
+func (w *World) MarshalJSON() ([]byte, error) {
+	wj := worldJSON{
+		Next:     w.next,
+		Entities: w.entities.GetEntities(),
+		Stores:   map[string]json.RawMessage{},
+	}
+	for _, name := range w.storeNames {
+		b, err := json.Marshal(w.stores[name])
+		if err != nil {
+			return nil, err
+		}
+		wj.Stores[name] = b
+	}
+	return json.Marshal(wj)
+}
+
+// UnmarshalJSON replaces the Entities and components with ones written by
+// MarshalJSON. Every saved Store must be registered first, and Stores that
+// weren't saved are left empty.
+func (w *World) UnmarshalJSON(data []byte) error {
+	var wj worldJSON
+	err := json.Unmarshal(data, &wj)
+	if err != nil {
+		return err
+	}
+
+	if w.stores == nil {
+		*w = *NewWorld()
+	}
+	for name := range wj.Stores {
+		if _, found := w.stores[name]; !found {
+			return fmt.Errorf("Store [%v] is not registered", name)
+		}
+	}
+
+	w.next = wj.Next
+	if w.next == 0 {
+		w.next = 1
+	}
+	err = w.entities.Reset(wj.Entities)
+	if err != nil {
+		return err
+	}
+
+	for _, name := range w.storeNames {
+		b, found := wj.Stores[name]
+		if !found {
+			b = []byte("{}")
+		}
+		err = w.stores[name].UnmarshalJSON(b)
+		if err != nil {
+			return fmt.Errorf("Failed to load store [%v] [%v]", name, err)
+		}
+	}
+	return nil
+}
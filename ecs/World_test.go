@@ -0,0 +1,178 @@
+package ecs
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// newTestWorld returns a World with 5 Entities, all with a position and the
+// even ones with a velocity
+func newTestWorld() (*World, *Vec2Store, *Vec2Store) {
+	w := NewWorld()
+	pos := NewVec2Store()
+	vel := NewVec2Store()
+	w.Register("position", pos)
+	w.Register("velocity", vel)
+
+	for i := 0; i < 5; i++ {
+		e := w.Create()
+		pos.Add(e, mgl32.Vec2{float32(e), 0})
+		if e%2 == 0 {
+			vel.Add(e, mgl32.Vec2{0, float32(e)})
+		}
+	}
+	return w, pos, vel
+}
+
+func TestQuery(t *testing.T) {
+	w, pos, vel := newTestWorld()
+	empty := NewVec2Store()
+	w.Register("empty", empty)
+
+	tests := []struct {
+		name   string
+		stores []Store
+		want   []Entity
+	}{
+		{name: "none", stores: []Store{}, want: []Entity{}},
+		{name: "one", stores: []Store{pos}, want: []Entity{1, 2, 3, 4, 5}},
+		{name: "larger first", stores: []Store{pos, vel}, want: []Entity{2, 4}},
+		{name: "smaller first", stores: []Store{vel, pos}, want: []Entity{2, 4}},
+		{name: "with an empty store", stores: []Store{pos, empty}, want: []Entity{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := w.Query(tt.stores...); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Query() = %v, want %v", got, tt.want)
+			}
+
+			got := []Entity{}
+			w.Each(func(e Entity) { got = append(got, e) }, tt.stores...)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Each() visited %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQueryAfterDestroy(t *testing.T) {
+	w, pos, vel := newTestWorld()
+	vel.Add(5, mgl32.Vec2{0, 5})
+	w.Destroy(2)
+
+	// The smaller velocity Store sets the order, 5 having moved into 2's place
+	if got, want := w.Query(pos, vel), []Entity{5, 4}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Query() = %v, want %v", got, want)
+	}
+	if w.IsAlive(2) || pos.Get(2) != nil || vel.Get(2) != nil {
+		t.Errorf("2 still exists after Destroy")
+	}
+}
+
+func TestSystemOrder(t *testing.T) {
+	w := NewWorld()
+	ran := []string{}
+	add := func(name string, order int) {
+		w.AddSystem(name, order, SystemFunc(func(w *World, dt float64) {
+			ran = append(ran, name)
+		}))
+	}
+
+	add("render", 10)
+	add("physics", 0)
+	add("input", -5)
+	add("collision", 0)
+	add("audio", 10)
+
+	want := []string{"input", "physics", "collision", "render", "audio"}
+	if got := w.GetSystemNames(); !reflect.DeepEqual(got, want) {
+		t.Errorf("GetSystemNames() = %v, want %v", got, want)
+	}
+	w.Update(0.1)
+	if !reflect.DeepEqual(ran, want) {
+		t.Errorf("Update ran %v, want %v", ran, want)
+	}
+
+	ran = []string{}
+	w.RemoveSystem("physics")
+	w.RemoveSystem("missing")
+	w.Update(0.1)
+	want = []string{"input", "collision", "render", "audio"}
+	if !reflect.DeepEqual(ran, want) {
+		t.Errorf("Update ran %v after RemoveSystem, want %v", ran, want)
+	}
+}
+
+func TestWorldJSON(t *testing.T) {
+	w, _, vel := newTestWorld()
+	w.Destroy(3)
+	vel.Remove(2)
+
+	b, err := json.Marshal(w)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, pos, loadedVel := NewWorld(), NewVec2Store(), NewVec2Store()
+	loaded.Register("position", pos)
+	loaded.Register("velocity", loadedVel)
+	err = json.Unmarshal(b, loaded)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := loaded.GetEntities(), w.GetEntities(); !reflect.DeepEqual(got, want) {
+		t.Errorf("entities are %v, want %v", got, want)
+	}
+	for _, e := range loaded.GetEntities() {
+		if v := pos.Get(e); v == nil || v.X() != float32(e) {
+			t.Errorf("position of %v is %v, want {%v 0}", e, v, e)
+		}
+	}
+	if got, want := loadedVel.Set.GetEntities(), []Entity{4}; !reflect.DeepEqual(got, want) {
+		t.Errorf("velocity entities are %v, want %v", got, want)
+	}
+
+	// Entities aren't reused after loading
+	if e := loaded.Create(); e != 6 {
+		t.Errorf("Create() = %v after loading, want 6", e)
+	}
+
+	// Saving again gives the same result
+	again, _, _ := newTestWorld()
+	again.Destroy(3)
+	again.GetStore("velocity").Remove(2)
+	b2, err := json.Marshal(again)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != string(b2) {
+		t.Errorf("saved %s, then %s", b, b2)
+	}
+}
+
+func TestWorldJSONErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		json string
+	}{
+		{name: "unregistered store", json: `{"Next":2,"Entities":[1],"Stores":{"mass":{"Entities":[],"Data":[]}}}`},
+		{name: "zero entity", json: `{"Next":2,"Entities":[0],"Stores":{}}`},
+		{name: "duplicate entity", json: `{"Next":3,"Entities":[1,1],"Stores":{}}`},
+		{name: "components without entities", json: `{"Next":2,"Entities":[1],"Stores":{"position":{"Entities":[1],"Data":[[0,0],[1,1]]}}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := NewWorld()
+			w.Register("position", NewVec2Store())
+			if err := json.Unmarshal([]byte(tt.json), w); err == nil {
+				t.Errorf("loaded %s, want an error", tt.json)
+			}
+		})
+	}
+}
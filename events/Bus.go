@@ -0,0 +1,92 @@
+package events
+
+import "sort"
+
+// Handler is called with each Event of the Types it is subscribed to
+type Handler func(Event)
+
+// SubscriptionID identifies a subscription to remove it with Unsubscribe
+type SubscriptionID int
+
+type subscription struct {
+	ID      SubscriptionID
+	Type    Type
+	Handler Handler
+}
+
+// Bus queues published Events and delivers them when drained, so every Handler
+// sees the Events of a tick in the order they were published, whenever in the
+// tick that was. A nil Bus drops everything published to it.
+type Bus struct {
+	queue         []Event
+	subscriptions []subscription
+	nextID        SubscriptionID
+}
+
+// NewBus returns a new Bus with no subscriptions
+func NewBus() *Bus {
+	return &Bus{
+		queue:         []Event{},
+		subscriptions: []subscription{},
+		nextID:        1,
+	}
+}
+
+// Subscribe calls h with every Event of Type t, after the Handlers subscribed before it
+func (b *Bus) Subscribe(t Type, h Handler) SubscriptionID {
+	id := b.nextID
+	b.nextID++
+	b.subscriptions = append(b.subscriptions, subscription{ID: id, Type: t, Handler: h})
+	return id
+}
+
+// SubscribeAll calls h with every Event
+func (b *Bus) SubscribeAll(h Handler) SubscriptionID {
+	return b.Subscribe("", h)
+}
+
+// Unsubscribe stops calling the Handler of a subscription
+func (b *Bus) Unsubscribe(id SubscriptionID) {
+	i := sort.Search(len(b.subscriptions), func(i int) bool { return b.subscriptions[i].ID >= id })
+	if i < len(b.subscriptions) && b.subscriptions[i].ID == id {
+		b.subscriptions = append(b.subscriptions[:i], b.subscriptions[i+1:]...)
+	}
+}
+
+// Publish queues e for the next Drain
+func (b *Bus) Publish(e Event) {
+	if b == nil {
+		return
+	}
+	b.queue = append(b.queue, e)
+}
+
+// GetPending returns the number of Events waiting for the next Drain
+func (b *Bus) GetPending() int {
+	if b == nil {
+		return 0
+	}
+	return len(b.queue)
+}
+
+// Drain delivers the queued Events in the order they were published. Events
+// published by the Handlers are queued for the next Drain.
+func (b *Bus) Drain() {
+	if b == nil {
+		return
+	}
+
+	queue := b.queue
+	b.queue = []Event{}
+
+	// Handlers may subscribe or unsubscribe, which takes effect on the next Drain
+	subs := append([]subscription{}, b.subscriptions...)
+	for _, e := range queue {
+		t := e.GetType()
+		for _, s := range subs {
+			if s.Type == "" || s.Type == t {
+				s.Handler(e)
+			}
+		}
+	}
+}
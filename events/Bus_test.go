@@ -0,0 +1,115 @@
+package events
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDrainOrder(t *testing.T) {
+	b := NewBus()
+	all, budget := []Event{}, []Event{}
+	b.SubscribeAll(func(e Event) { all = append(all, e) })
+	b.Subscribe(BudgetNegativeType, func(e Event) { budget = append(budget, e) })
+
+	published := []Event{
+		BudgetNegative{Balance: -1},
+		TechAvailable{Tech: "fiber", Year: 1995},
+		BudgetNegative{Balance: -2},
+		TechAvailable{Tech: "sdh", Year: 1998},
+	}
+	for _, e := range published {
+		b.Publish(e)
+	}
+	if len(all) != 0 || b.GetPending() != 4 {
+		t.Fatalf("delivered %d with %d pending before Drain, want none delivered and 4 pending", len(all), b.GetPending())
+	}
+
+	b.Drain()
+	if !reflect.DeepEqual(all, published) {
+		t.Errorf("SubscribeAll got %v, want %v", all, published)
+	}
+	if want := []Event{published[0], published[2]}; !reflect.DeepEqual(budget, want) {
+		t.Errorf("Subscribe(BudgetNegativeType) got %v, want %v", budget, want)
+	}
+	if b.GetPending() != 0 {
+		t.Errorf("%d pending after Drain, want 0", b.GetPending())
+	}
+
+	// Each Event is only delivered once
+	b.Drain()
+	if len(all) != 4 {
+		t.Errorf("delivered %d after a second Drain, want 4", len(all))
+	}
+}
+
+func TestPublishFromHandler(t *testing.T) {
+	b := NewBus()
+	got := []Event{}
+	b.SubscribeAll(func(e Event) { got = append(got, e) })
+	b.Subscribe(TechAvailableType, func(e Event) {
+		b.Publish(BudgetNegative{Balance: -1})
+	})
+
+	b.Publish(TechAvailable{Tech: "fiber"})
+	b.Drain()
+	if want := []Event{TechAvailable{Tech: "fiber"}}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("first Drain got %v, want %v", got, want)
+	}
+	if b.GetPending() != 1 {
+		t.Fatalf("%d pending after the first Drain, want the 1 published by the handler", b.GetPending())
+	}
+
+	b.Drain()
+	if want := []Event{TechAvailable{Tech: "fiber"}, BudgetNegative{Balance: -1}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("second Drain got %v, want %v", got, want)
+	}
+}
+
+func TestUnsubscribe(t *testing.T) {
+	b := NewBus()
+	counts := make([]int, 3)
+	ids := make([]SubscriptionID, 3)
+	for i := range ids {
+		i := i
+		ids[i] = b.Subscribe(BudgetNegativeType, func(e Event) { counts[i]++ })
+	}
+
+	b.Unsubscribe(ids[1])
+	// Removing an ID twice, or one never given, changes nothing
+	b.Unsubscribe(ids[1])
+	b.Unsubscribe(ids[2] + 1)
+
+	b.Publish(BudgetNegative{})
+	b.Drain()
+	if want := []int{1, 0, 1}; !reflect.DeepEqual(counts, want) {
+		t.Errorf("handlers called %v times, want %v", counts, want)
+	}
+
+	// A Handler unsubscribing during Drain is still called for the rest of it
+	var self SubscriptionID
+	calls := 0
+	self = b.Subscribe(BudgetNegativeType, func(e Event) {
+		calls++
+		b.Unsubscribe(self)
+	})
+	b.Publish(BudgetNegative{})
+	b.Publish(BudgetNegative{})
+	b.Drain()
+	b.Publish(BudgetNegative{})
+	b.Drain()
+	if calls != 2 {
+		t.Errorf("handler called %d times, want 2 in the Drain it unsubscribed in", calls)
+	}
+	if want := []int{4, 0, 4}; !reflect.DeepEqual(counts, want) {
+		t.Errorf("other handlers called %v times, want %v", counts, want)
+	}
+}
+
+func TestNilBus(t *testing.T) {
+	var b *Bus
+	b.Publish(BudgetNegative{})
+	b.Drain()
+	if n := b.GetPending(); n != 0 {
+		t.Errorf("nil Bus has %d pending, want 0", n)
+	}
+}
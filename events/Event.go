@@ -0,0 +1,167 @@
+package events
+
+import (
+	"github.com/WhoBrokeTheBuild/TelcomSim/network"
+)
+
+// Type identifies the kind of an Event
+type Type string
+
+// Event is something that happened, delivered to the Handlers subscribed to its Type
+type Event interface {
+	GetType() Type
+}
+
+const (
+	// CallBlockedType is the Type of CallBlocked
+	CallBlockedType Type = "call_blocked"
+	// LinkSaturatedType is the Type of LinkSaturated
+	LinkSaturatedType Type = "link_saturated"
+	// BudgetNegativeType is the Type of BudgetNegative
+	BudgetNegativeType Type = "budget_negative"
+	// WindowResizedType is the Type of WindowResized
+	WindowResizedType Type = "window_resized"
+	// EquipmentFailedType is the Type of EquipmentFailed
+	EquipmentFailedType Type = "equipment_failed"
+	// EquipmentRepairedType is the Type of EquipmentRepaired
+	EquipmentRepairedType Type = "equipment_repaired"
+	// TechAvailableType is the Type of TechAvailable
+	TechAvailableType Type = "tech_available"
+	// ResearchCompletedType is the Type of ResearchCompleted
+	ResearchCompletedType Type = "research_completed"
+	// ObjectiveCompletedType is the Type of ObjectiveCompleted
+	ObjectiveCompletedType Type = "objective_completed"
+	// ObjectiveFailedType is the Type of ObjectiveFailed
+	ObjectiveFailedType Type = "objective_failed"
+	// ScenarioEndedType is the Type of ScenarioEnded
+	ScenarioEndedType Type = "scenario_ended"
+)
+
+// CallBlocked is published when a call finds no route
+type CallBlocked struct {
+	From network.NodeID
+	To   network.NodeID
+	// Home is the exchange or tower the call was placed through, 0 if it has none
+	Home network.NodeID
+	// Time is the simulated time of the call
+	Time float64
+}
+
+// GetType returns CallBlockedType
+func (CallBlocked) GetType() Type {
+	return CallBlockedType
+}
+
+// LinkSaturated is published when every circuit of a Link is in use
+type LinkSaturated struct {
+	Link     network.LinkID
+	Capacity int
+	// Time is the simulated time the last circuit was seized
+	Time float64
+}
+
+// GetType returns LinkSaturatedType
+func (LinkSaturated) GetType() Type {
+	return LinkSaturatedType
+}
+
+// BudgetNegative is published when the balance falls below zero
+type BudgetNegative struct {
+	Balance float64
+}
+
+// GetType returns BudgetNegativeType
+func (BudgetNegative) GetType() Type {
+	return BudgetNegativeType
+}
+
+// WindowResized is published when the window changes size, in pixels
+type WindowResized struct {
+	Width  int
+	Height int
+}
+
+// GetType returns WindowResizedType
+func (WindowResized) GetType() Type {
+	return WindowResizedType
+}
+
+// EquipmentFailed is published when a Node or Link breaks down
+type EquipmentFailed struct {
+	// Node or Link is the broken equipment, the other is 0
+	Node  network.NodeID
+	Link  network.LinkID
+	Cause string
+}
+
+// GetType returns EquipmentFailedType
+func (EquipmentFailed) GetType() Type {
+	return EquipmentFailedType
+}
+
+// EquipmentRepaired is published when a Crew finishes repairing a Node or Link
+type EquipmentRepaired struct {
+	// Node or Link is the repaired equipment, the other is 0
+	Node network.NodeID
+	Link network.LinkID
+	// Cost is what the repair cost, with the Crew's wages
+	Cost float64
+}
+
+// GetType returns EquipmentRepairedType
+func (EquipmentRepaired) GetType() Type {
+	return EquipmentRepairedType
+}
+
+// TechAvailable is published when a new year makes a Tech researchable
+type TechAvailable struct {
+	Tech string
+	Year int
+}
+
+// GetType returns TechAvailableType
+func (TechAvailable) GetType() Type {
+	return TechAvailableType
+}
+
+// ResearchCompleted is published when a Tech has been researched
+type ResearchCompleted struct {
+	Tech string
+}
+
+// GetType returns ResearchCompletedType
+func (ResearchCompleted) GetType() Type {
+	return ResearchCompletedType
+}
+
+// ObjectiveCompleted is published when an Objective of a Scenario is met
+type ObjectiveCompleted struct {
+	Objective string
+}
+
+// GetType returns ObjectiveCompletedType
+func (ObjectiveCompleted) GetType() Type {
+	return ObjectiveCompletedType
+}
+
+// ObjectiveFailed is published when an Objective of a Scenario can no longer be met,
+// or a losing one is
+type ObjectiveFailed struct {
+	Objective string
+}
+
+// GetType returns ObjectiveFailedType
+func (ObjectiveFailed) GetType() Type {
+	return ObjectiveFailedType
+}
+
+// ScenarioEnded is published when a Scenario is won or lost
+type ScenarioEnded struct {
+	Scenario string
+	Won      bool
+}
+
+// GetType returns ScenarioEndedType
+func (ScenarioEnded) GetType() Type {
+	return ScenarioEndedType
+}
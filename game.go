@@ -0,0 +1,483 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+
+	gl "github.com/go-gl/gl/v4.1-core/gl"
+	"github.com/go-gl/glfw/v3.2/glfw"
+
+	"github.com/WhoBrokeTheBuild/TelcomSim/asset"
+	"github.com/WhoBrokeTheBuild/TelcomSim/data"
+	"github.com/WhoBrokeTheBuild/TelcomSim/events"
+	"github.com/WhoBrokeTheBuild/TelcomSim/log"
+	"github.com/WhoBrokeTheBuild/TelcomSim/loop"
+	"github.com/WhoBrokeTheBuild/TelcomSim/network"
+	"github.com/WhoBrokeTheBuild/TelcomSim/radio"
+	"github.com/WhoBrokeTheBuild/TelcomSim/replay"
+	"github.com/WhoBrokeTheBuild/TelcomSim/save"
+	"github.com/WhoBrokeTheBuild/TelcomSim/scenario"
+	"github.com/WhoBrokeTheBuild/TelcomSim/scene"
+	"github.com/WhoBrokeTheBuild/TelcomSim/sim"
+	"github.com/WhoBrokeTheBuild/TelcomSim/terrain"
+	"github.com/WhoBrokeTheBuild/TelcomSim/traffic"
+	"github.com/WhoBrokeTheBuild/TelcomSim/ui"
+)
+
+// game is the running simulation and the scene it is shown in
+type game struct {
+	World  *sim.World
+	Loop   *loop.Loop
+	Root   *scene.Node
+	Camera *scene.Node
+	// Terrain shows the World's Map, if it has one
+	Terrain *scene.Node
+	// Coverage shows the radio coverage over the Terrain while ShowCoverage is set
+	Coverage     *scene.Node
+	ShowCoverage bool
+
+	coverageTexture *asset.Texture
+	shownCoverage   *radio.Coverage
+
+	// Scenario is the progress through the scenario played
+	Scenario *scenario.Run
+
+	// TechTree shows the World's Research while it is toggled on
+	TechTree *techTree
+	// Routing shows the routing policy of each exchange while it is toggled on
+	Routing *routingPanel
+	// Crews shows the repair crews and faults while it is toggled on
+	Crews *crewPanel
+
+	// Recorder is set while the game is recorded to a replay
+	Recorder *replay.Recorder
+
+	// Events carries what happens in the World to the HUD, sounds and log
+	Events *events.Bus
+	Notice *ui.Notice
+	Alert  *asset.Sound
+
+	lastAlert float64
+}
+
+func (g *game) newSave() *save.Game {
+	return &save.Game{
+		World:  g.World,
+		Tick:   g.Loop.GetTick(),
+		Camera: g.Camera.Camera.Save(),
+		Nodes:  g.Root.Save(),
+
+		Scenario: g.Scenario,
+	}
+}
+
+// save writes the game to the named save slot
+func (g *game) save(name string) {
+	slot, err := save.GetSlot(name)
+	if err == nil {
+		err = slot.Save(g.newSave())
+	}
+	if err != nil {
+		log.Errorf("%v", err)
+		return
+	}
+	log.Infof("Saved [%v]", slot.Filename)
+}
+
+// autosave writes the game to the oldest autosave slot
+func (g *game) autosave() {
+	slot, err := save.Autosave(g.newSave())
+	if err != nil {
+		log.Errorf("%v", err)
+		return
+	}
+	log.Infof("Autosaved [%v]", slot.Filename)
+}
+
+// load replaces the game with the one in the named save slot
+func (g *game) load(name string) {
+	slot, err := save.GetSlot(name)
+	if err != nil {
+		log.Errorf("%v", err)
+		return
+	}
+	if !slot.Exists() {
+		log.Warnf("No save in slot [%v]", name)
+		return
+	}
+
+	s, err := slot.Load()
+	if err != nil {
+		log.Errorf("%v", err)
+		return
+	}
+
+	*g.World = *s.World
+	g.World.OnCommand = logCommand
+	g.World.SetEvents(g.Events)
+	g.Loop.SetTick(s.Tick)
+	g.Root.Load(s.Nodes)
+	g.Camera.Camera.Load(s.Camera)
+	g.setTerrain(g.World.Map)
+	g.Scenario = s.Scenario
+	if g.Scenario != nil {
+		g.Scenario.Events = g.Events
+	}
+	log.Infof("Loaded [%v]", slot.Filename)
+
+	// A replay can't span a load, so it starts over from the loaded game
+	if g.Recorder != nil {
+		g.startRecording()
+	}
+}
+
+// getPanels returns whether each panel shown over the map is shown, only one is at a time
+func (g *game) getPanels() []*bool {
+	panels := []*bool{}
+	if g.TechTree != nil {
+		panels = append(panels, &g.TechTree.Shown)
+	}
+	if g.Routing != nil {
+		panels = append(panels, &g.Routing.Shown)
+	}
+	if g.Crews != nil {
+		panels = append(panels, &g.Crews.Shown)
+	}
+	return panels
+}
+
+// togglePanel shows a panel, hiding the others, or hides it if it is shown
+func (g *game) togglePanel(shown *bool) {
+	show := !*shown
+	for _, p := range g.getPanels() {
+		*p = false
+	}
+	*shown = show
+}
+
+// isPanelShown returns whether any panel is shown over the map
+func (g *game) isPanelShown() bool {
+	for _, p := range g.getPanels() {
+		if *p {
+			return true
+		}
+	}
+	return false
+}
+
+// setTerrain replaces the terrain in the scene with one of m
+func (g *game) setTerrain(m *terrain.Map) {
+	// The coverage overlay is draped over the old Map
+	g.deleteCoverage()
+
+	if g.Terrain != nil {
+		g.Root.RemoveChild(g.Terrain)
+		g.Terrain.Delete()
+		g.Terrain = nil
+	}
+	if m == nil {
+		return
+	}
+
+	t, err := scene.NewTerrain(m, scene.DefaultChunkCells)
+	if err != nil {
+		log.Errorf("%v", err)
+		return
+	}
+	g.Terrain = t
+	g.Root.AddChild(t)
+}
+
+// coverageLift keeps the coverage overlay above the terrain, in kilometers
+const coverageLift = 0.01
+
+// toggleCoverage shows or hides the coverage overlay
+func (g *game) toggleCoverage() {
+	g.ShowCoverage = !g.ShowCoverage
+	if g.Coverage == nil {
+		return
+	}
+	if g.ShowCoverage {
+		g.Root.AddChild(g.Coverage)
+	} else {
+		g.Root.RemoveChild(g.Coverage)
+	}
+}
+
+// updateCoverage redraws the coverage overlay when the World's Coverage has changed
+func (g *game) updateCoverage() {
+	if !g.ShowCoverage {
+		return
+	}
+	cov := g.World.GetCoverage()
+	if cov == nil || cov == g.shownCoverage {
+		return
+	}
+	g.shownCoverage = cov
+
+	if g.Coverage != nil {
+		err := g.coverageTexture.LoadFromData(cov.GetHeatmap(), gl.RGBA, gl.RGBA, cov.Width, cov.Depth)
+		if err != nil {
+			log.Errorf("%v", err)
+		}
+		return
+	}
+
+	tex, err := asset.NewTextureFromData(cov.GetHeatmap(), gl.RGBA, gl.RGBA, cov.Width, cov.Depth)
+	if err != nil {
+		log.Errorf("%v", err)
+		return
+	}
+	overlay, err := scene.NewOverlay("coverage", g.World.Map, scene.DefaultChunkCells, coverageLift, 0.6, tex)
+	if err != nil {
+		tex.Delete()
+		log.Errorf("%v", err)
+		return
+	}
+	g.coverageTexture = tex
+	g.Coverage = overlay
+	g.Root.AddChild(overlay)
+}
+
+// deleteCoverage frees the coverage overlay, it is made again by updateCoverage
+func (g *game) deleteCoverage() {
+	if g.Coverage == nil {
+		return
+	}
+	g.Root.RemoveChild(g.Coverage)
+	g.Coverage.Delete()
+	g.Coverage = nil
+	g.coverageTexture = nil
+	g.shownCoverage = nil
+}
+
+// mapFile is the map.json of a map, next to its height.png and population.png
+type mapFile struct {
+	Name string
+	terrain.MapConfig
+	Spawn traffic.SpawnConfig
+}
+
+// loadMap reads the named map from maps/<name>
+func loadMap(name string) (*terrain.Map, traffic.SpawnConfig, error) {
+	dir := path.Join("maps", name)
+
+	log.Loadf("terrain.Map [%v]", dir)
+	b, err := data.Asset(path.Join(dir, "map.json"))
+	if err != nil {
+		return nil, traffic.SpawnConfig{}, err
+	}
+
+	mf := mapFile{Spawn: traffic.DefaultSpawnConfig}
+	err = json.Unmarshal(b, &mf)
+	if err != nil {
+		return nil, traffic.SpawnConfig{}, fmt.Errorf("Failed to parse [%v] [%v]", path.Join(dir, "map.json"), err)
+	}
+
+	heights, err := data.Asset(path.Join(dir, "height.png"))
+	if err != nil {
+		return nil, traffic.SpawnConfig{}, err
+	}
+	// A map without people is valid, it just spawns no subscribers
+	population, _ := data.Asset(path.Join(dir, "population.png"))
+
+	m, err := terrain.DecodeMap(heights, population, mf.MapConfig)
+	if err != nil {
+		return nil, traffic.SpawnConfig{}, fmt.Errorf("Failed to load map [%v] [%v]", name, err)
+	}
+	return m, mf.Spawn, nil
+}
+
+// alertDelay is the fewest seconds between two alert sounds
+const alertDelay = 2.0
+
+// subscribe has the World publish to bus, and reports what it publishes
+func (g *game) subscribe(bus *events.Bus) {
+	g.Events = bus
+	g.World.SetEvents(bus)
+	if g.Scenario != nil {
+		g.Scenario.Events = bus
+	}
+
+	bus.Subscribe(events.LinkSaturatedType, func(e events.Event) {
+		ls := e.(events.LinkSaturated)
+		msg := fmt.Sprintf("Link %v is full, all %d circuits in use", g.getLinkName(ls.Link), ls.Capacity)
+		log.Warnf("%v", msg)
+		g.notify(msg)
+		g.alert()
+	})
+
+	bus.Subscribe(events.BudgetNegativeType, func(e events.Event) {
+		msg := fmt.Sprintf("Balance is negative, $%.0f", e.(events.BudgetNegative).Balance)
+		log.Warnf("%v", msg)
+		g.notify(msg)
+		g.alert()
+	})
+
+	bus.Subscribe(events.EquipmentFailedType, func(e events.Event) {
+		ef := e.(events.EquipmentFailed)
+		msg := fmt.Sprintf("%v is down, %v", g.getEquipmentName(ef.Node, ef.Link), ef.Cause)
+		log.Warnf("%v", msg)
+		g.notify(msg)
+		g.alert()
+	})
+
+	bus.Subscribe(events.EquipmentRepairedType, func(e events.Event) {
+		er := e.(events.EquipmentRepaired)
+		msg := fmt.Sprintf("%v is back up, repairs cost $%.0f", g.getEquipmentName(er.Node, er.Link), er.Cost)
+		log.Infof("%v", msg)
+		g.notify(msg)
+	})
+
+	bus.Subscribe(events.TechAvailableType, func(e events.Event) {
+		msg := fmt.Sprintf("%v can now be researched", g.getTechName(e.(events.TechAvailable).Tech))
+		log.Infof("%v", msg)
+		g.notify(msg)
+	})
+
+	bus.Subscribe(events.ResearchCompletedType, func(e events.Event) {
+		msg := fmt.Sprintf("Researched %v", g.getTechName(e.(events.ResearchCompleted).Tech))
+		log.Infof("%v", msg)
+		g.notify(msg)
+		g.alert()
+	})
+
+	bus.Subscribe(events.ObjectiveCompletedType, func(e events.Event) {
+		msg := fmt.Sprintf("Objective complete: %v", e.(events.ObjectiveCompleted).Objective)
+		log.Infof("%v", msg)
+		g.notify(msg)
+	})
+
+	bus.Subscribe(events.ObjectiveFailedType, func(e events.Event) {
+		msg := fmt.Sprintf("Objective failed: %v", e.(events.ObjectiveFailed).Objective)
+		log.Warnf("%v", msg)
+		g.notify(msg)
+	})
+
+	// The game stops when a scenario ends, until the next one is started
+	bus.Subscribe(events.ScenarioEndedType, func(e events.Event) {
+		se := e.(events.ScenarioEnded)
+		msg := fmt.Sprintf("%v lost", se.Scenario)
+		if se.Won {
+			msg = fmt.Sprintf("%v won", se.Scenario)
+		}
+		log.Infof("%v", msg)
+		g.notify(msg)
+		g.alert()
+		g.Loop.SetPaused(true)
+	})
+
+	// Blocked calls come in bursts, so they are only shown when nothing else is
+	bus.Subscribe(events.CallBlockedType, func(e events.Event) {
+		if g.Notice == nil || g.Notice.IsShown() {
+			return
+		}
+		cb := e.(events.CallBlocked)
+		if node := g.World.Network.GetNode(cb.From); node != nil {
+			g.notify(fmt.Sprintf("Calls from %v are being blocked", node.Name))
+		}
+	})
+}
+
+// getLinkName returns the names of the Nodes a Link connects
+func (g *game) getLinkName(id network.LinkID) string {
+	link := g.World.Network.GetLink(id)
+	if link == nil {
+		return fmt.Sprintf("[%v]", id)
+	}
+	a, b := g.World.Network.GetNode(link.A), g.World.Network.GetNode(link.B)
+	return fmt.Sprintf("%v-%v", a.Name, b.Name)
+}
+
+// getEquipmentName returns the name of a Node, or of a Link if node is 0
+func (g *game) getEquipmentName(node network.NodeID, link network.LinkID) string {
+	if node == 0 {
+		return "Link " + g.getLinkName(link)
+	}
+	if n := g.World.Network.GetNode(node); n != nil {
+		return n.Name
+	}
+	return fmt.Sprintf("[%v]", node)
+}
+
+// getTechName returns the name of a Tech in the World's tech tree
+func (g *game) getTechName(id string) string {
+	if t := g.World.Research.Tree.GetTech(id); t != nil {
+		return t.Name
+	}
+	return fmt.Sprintf("[%v]", id)
+}
+
+// notify shows msg in the HUD
+func (g *game) notify(msg string) {
+	if g.Notice != nil {
+		g.Notice.Show(msg)
+	}
+}
+
+// alert plays the alert sound, unless it played within alertDelay
+func (g *game) alert() {
+	now := glfw.GetTime()
+	if g.Alert == nil || now-g.lastAlert < alertDelay {
+		return
+	}
+	g.lastAlert = now
+	g.Alert.Play()
+}
+
+// startRecording records the game from its current state
+func (g *game) startRecording() {
+	r, err := replay.NewRecorder(g.World, g.Loop.Step)
+	if err != nil {
+		log.Errorf("%v", err)
+		return
+	}
+	g.Recorder = r
+}
+
+// stopRecording writes what was recorded to a replay file
+func (g *game) stopRecording(filename string) {
+	if g.Recorder == nil {
+		return
+	}
+
+	err := g.Recorder.Stop().WriteFile(filename)
+	g.Recorder = nil
+	if err != nil {
+		log.Errorf("%v", err)
+		return
+	}
+	log.Infof("Recorded replay [%v]", filename)
+}
+
+// logCommand reports the Commands that failed
+func logCommand(c sim.Command, err error) {
+	if err != nil {
+		log.Warnf("%v failed: %v", c.Type, err)
+	}
+}
+
+// runReplay plays a replay file and prints the hashes of the final state
+func runReplay(filename string) error {
+	r, err := replay.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+
+	w, err := r.Play()
+	if err != nil {
+		return err
+	}
+
+	hashes, err := w.GetHashes()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("tick %d\n", w.Tick)
+	for _, h := range hashes {
+		fmt.Printf("%v %v\n", h.Name, h.Sum)
+	}
+	return nil
+}
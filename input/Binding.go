@@ -0,0 +1,204 @@
+package input
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/go-gl/glfw/v3.2/glfw"
+)
+
+// The mouse buttons, for checking a State without importing glfw
+const (
+	MouseButtonLeft   = glfw.MouseButtonLeft
+	MouseButtonRight  = glfw.MouseButtonRight
+	MouseButtonMiddle = glfw.MouseButtonMiddle
+)
+
+// Binding is a key or mouse button that triggers an action
+type Binding struct {
+	Key    glfw.Key
+	Button glfw.MouseButton
+	// Mouse is true when the Binding is for Button instead of Key
+	Mouse bool
+}
+
+// KeyBinding returns a Binding for a key
+func KeyBinding(key glfw.Key) Binding {
+	return Binding{Key: key}
+}
+
+// ButtonBinding returns a Binding for a mouse button
+func ButtonBinding(button glfw.MouseButton) Binding {
+	return Binding{Button: button, Mouse: true}
+}
+
+// String returns the name of the Binding, as used in bindings files
+func (b Binding) String() string {
+	if b.Mouse {
+		for name, button := range _buttonNames {
+			if button == b.Button {
+				return name
+			}
+		}
+		return fmt.Sprintf("Mouse%d", int(b.Button)+1)
+	}
+	for name, key := range _keyNames {
+		if key == b.Key {
+			return name
+		}
+	}
+	return fmt.Sprintf("Key%d", int(b.Key))
+}
+
+// ParseBinding returns the Binding for a key or mouse button name, like "A", "F2", "Left" or "MouseRight"
+func ParseBinding(name string) (Binding, error) {
+	if button, found := _buttonNames[name]; found {
+		return ButtonBinding(button), nil
+	}
+	if key, found := _keyNames[name]; found {
+		return KeyBinding(key), nil
+	}
+	return Binding{}, fmt.Errorf("Unknown key or button [%v]", name)
+}
+
+// Bindings maps action names to the keys and buttons that trigger them
+type Bindings map[string][]Binding
+
+// DefaultBindings returns the Bindings used when no bindings file overrides them
+func DefaultBindings() Bindings {
+	return Bindings{
+		"toggle_wireframe": {KeyBinding(glfw.KeyF2)},
+		"toggle_coverage":  {KeyBinding(glfw.KeyC)},
+		"toggle_tech":      {KeyBinding(glfw.KeyT)},
+		"toggle_routing":   {KeyBinding(glfw.KeyR)},
+		"next_policy":      {KeyBinding(glfw.KeyTab)},
+		"toggle_crews":     {KeyBinding(glfw.KeyM)},
+		"hire_crew":        {KeyBinding(glfw.KeyH)},
+		"dispatch_crew":    {KeyBinding(glfw.KeyEnter)},
+		"next_scenario":    {KeyBinding(glfw.KeyN)},
+
+		"pause":   {KeyBinding(glfw.KeySpace), KeyBinding(glfw.KeyP)},
+		"step":    {KeyBinding(glfw.KeyPeriod)},
+		"speed_1": {KeyBinding(glfw.Key1)},
+		"speed_2": {KeyBinding(glfw.Key2)},
+		"speed_3": {KeyBinding(glfw.Key3)},
+
+		"quicksave": {KeyBinding(glfw.KeyF5)},
+		"quickload": {KeyBinding(glfw.KeyF9)},
+
+		"tariff_up":   {KeyBinding(glfw.KeyEqual), KeyBinding(glfw.KeyKPAdd)},
+		"tariff_down": {KeyBinding(glfw.KeyMinus), KeyBinding(glfw.KeyKPSubtract)},
+		"borrow":      {KeyBinding(glfw.KeyB)},
+
+		"pan_forward":  {KeyBinding(glfw.KeyW), KeyBinding(glfw.KeyUp)},
+		"pan_back":     {KeyBinding(glfw.KeyS), KeyBinding(glfw.KeyDown)},
+		"pan_left":     {KeyBinding(glfw.KeyA), KeyBinding(glfw.KeyLeft)},
+		"pan_right":    {KeyBinding(glfw.KeyD), KeyBinding(glfw.KeyRight)},
+		"rotate_left":  {KeyBinding(glfw.KeyQ)},
+		"rotate_right": {KeyBinding(glfw.KeyE)},
+		"move_up":      {KeyBinding(glfw.KeyPageUp)},
+		"move_down":    {KeyBinding(glfw.KeyPageDown)},
+		"move_fast":    {KeyBinding(glfw.KeyLeftShift)},
+
+		"orbit": {ButtonBinding(MouseButtonLeft)},
+		"look":  {ButtonBinding(MouseButtonRight)},
+	}
+}
+
+// UnmarshalJSON reads Bindings from an object of action names to arrays of key and button names
+func (b *Bindings) UnmarshalJSON(data []byte) error {
+	names := map[string][]string{}
+	err := json.Unmarshal(data, &names)
+	if err != nil {
+		return err
+	}
+
+	*b = Bindings{}
+	for action, list := range names {
+		bindings := make([]Binding, 0, len(list))
+		for _, name := range list {
+			binding, err := ParseBinding(name)
+			if err != nil {
+				return fmt.Errorf("Invalid binding for [%v]: %v", action, err)
+			}
+			bindings = append(bindings, binding)
+		}
+		(*b)[action] = bindings
+	}
+	return nil
+}
+
+// MarshalJSON writes Bindings as an object of action names to arrays of key and button names
+func (b Bindings) MarshalJSON() ([]byte, error) {
+	names := map[string][]string{}
+	for action, list := range b {
+		names[action] = make([]string, 0, len(list))
+		for _, binding := range list {
+			names[action] = append(names[action], binding.String())
+		}
+	}
+	return json.Marshal(names)
+}
+
+var _keyNames map[string]glfw.Key
+var _buttonNames map[string]glfw.MouseButton
+
+func init() {
+	_keyNames = map[string]glfw.Key{
+		"Space":        glfw.KeySpace,
+		"Apostrophe":   glfw.KeyApostrophe,
+		"Comma":        glfw.KeyComma,
+		"Minus":        glfw.KeyMinus,
+		"Period":       glfw.KeyPeriod,
+		"Slash":        glfw.KeySlash,
+		"Semicolon":    glfw.KeySemicolon,
+		"Equal":        glfw.KeyEqual,
+		"LeftBracket":  glfw.KeyLeftBracket,
+		"Backslash":    glfw.KeyBackslash,
+		"RightBracket": glfw.KeyRightBracket,
+		"GraveAccent":  glfw.KeyGraveAccent,
+		"Escape":       glfw.KeyEscape,
+		"Enter":        glfw.KeyEnter,
+		"Tab":          glfw.KeyTab,
+		"Backspace":    glfw.KeyBackspace,
+		"Insert":       glfw.KeyInsert,
+		"Delete":       glfw.KeyDelete,
+		"Right":        glfw.KeyRight,
+		"Left":         glfw.KeyLeft,
+		"Down":         glfw.KeyDown,
+		"Up":           glfw.KeyUp,
+		"PageUp":       glfw.KeyPageUp,
+		"PageDown":     glfw.KeyPageDown,
+		"Home":         glfw.KeyHome,
+		"End":          glfw.KeyEnd,
+		"Pause":        glfw.KeyPause,
+		"KPAdd":        glfw.KeyKPAdd,
+		"KPSubtract":   glfw.KeyKPSubtract,
+		"KPEnter":      glfw.KeyKPEnter,
+		"LeftShift":    glfw.KeyLeftShift,
+		"LeftControl":  glfw.KeyLeftControl,
+		"LeftAlt":      glfw.KeyLeftAlt,
+		"RightShift":   glfw.KeyRightShift,
+		"RightControl": glfw.KeyRightControl,
+		"RightAlt":     glfw.KeyRightAlt,
+	}
+	for i := 0; i < 26; i++ {
+		_keyNames[string(rune('A'+i))] = glfw.KeyA + glfw.Key(i)
+	}
+	for i := 0; i < 10; i++ {
+		_keyNames[string(rune('0'+i))] = glfw.Key0 + glfw.Key(i)
+		_keyNames[fmt.Sprintf("KP%d", i)] = glfw.KeyKP0 + glfw.Key(i)
+	}
+	for i := 0; i < 12; i++ {
+		_keyNames[fmt.Sprintf("F%d", i+1)] = glfw.KeyF1 + glfw.Key(i)
+	}
+
+	_buttonNames = map[string]glfw.MouseButton{
+		"MouseLeft":   MouseButtonLeft,
+		"MouseRight":  MouseButtonRight,
+		"MouseMiddle": MouseButtonMiddle,
+	}
+	for i := glfw.MouseButton4; i <= glfw.MouseButtonLast; i++ {
+		_buttonNames[fmt.Sprintf("Mouse%d", int(i)+1)] = i
+	}
+}
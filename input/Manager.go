@@ -0,0 +1,175 @@
+package input
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/WhoBrokeTheBuild/TelcomSim/data"
+	"github.com/go-gl/glfw/v3.2/glfw"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// Manager collects input from a window's callbacks between snapshots
+type Manager struct {
+	Bindings Bindings
+
+	window *glfw.Window
+	next   State
+	cursor mgl32.Vec2
+}
+
+// NewManager returns a new Manager receiving input from window, using the default bindings
+func NewManager(window *glfw.Window) *Manager {
+	m := &Manager{
+		Bindings: DefaultBindings(),
+		window:   window,
+	}
+	m.reset()
+
+	x, y := window.GetCursorPos()
+	m.cursor = mgl32.Vec2{float32(x), float32(y)}
+	m.next.Cursor = m.cursor
+
+	window.SetKeyCallback(m.onKey)
+	window.SetCharCallback(m.onChar)
+	window.SetMouseButtonCallback(m.onMouseButton)
+	window.SetCursorPosCallback(m.onCursorPos)
+	window.SetScrollCallback(m.onScroll)
+
+	return m
+}
+
+// Delete removes the callbacks installed by the Manager
+func (m *Manager) Delete() {
+	if m.window != nil {
+		m.window.SetKeyCallback(nil)
+		m.window.SetCharCallback(nil)
+		m.window.SetMouseButtonCallback(nil)
+		m.window.SetCursorPosCallback(nil)
+		m.window.SetScrollCallback(nil)
+		m.window = nil
+	}
+}
+
+// Bind replaces the bindings of an action
+func (m *Manager) Bind(action string, bindings ...Binding) {
+	m.Bindings[action] = bindings
+}
+
+// LoadBindings reads a JSON file of action names to key and button names, like
+// `{"toggle_wireframe": ["F2"]}`, overriding the bindings of the actions it lists.
+// A file of the same name in the user's config directory is read in place of the
+// bundled asset, if there is one.
+func (m *Manager) LoadBindings(filename string) error {
+	path, err := GetUserBindingsFile(filename)
+	if err == nil {
+		b, err := os.ReadFile(path)
+		if err == nil {
+			return m.parseBindings(path, b)
+		}
+		if !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	b, err := data.Asset(filename)
+	if err != nil {
+		return err
+	}
+	return m.parseBindings(filename, b)
+}
+
+// GetUserBindingsFile returns where the user's copy of the bindings file filename is kept
+func GetUserBindingsFile(filename string) (string, error) {
+	base, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(base, "TelcomSim", filepath.Base(filename)), nil
+}
+
+func (m *Manager) parseBindings(filename string, b []byte) error {
+	bindings := Bindings{}
+	err := json.Unmarshal(b, &bindings)
+	if err != nil {
+		return fmt.Errorf("Failed to load bindings [%v]: %v", filename, err)
+	}
+
+	for action, list := range bindings {
+		m.Bindings[action] = list
+	}
+	return nil
+}
+
+// Snapshot returns the input received since the previous call, and should be called once per update
+func (m *Manager) Snapshot() *State {
+	s := m.next
+	s.Bindings = m.Bindings
+	s.CursorDelta = m.cursor.Sub(s.Cursor)
+	s.Cursor = m.cursor
+
+	if m.window != nil {
+		w, h := m.window.GetSize()
+		s.WindowSize = mgl32.Vec2{float32(w), float32(h)}
+		s.Focused = (m.window.GetAttrib(glfw.Focused) == glfw.True)
+	}
+
+	// Held keys carry over, everything else starts empty
+	m.reset()
+	for key := range s.keysDown {
+		m.next.keysDown[key] = true
+	}
+	for button := range s.buttonsDown {
+		m.next.buttonsDown[button] = true
+	}
+	m.next.Cursor = m.cursor
+
+	return &s
+}
+
+func (m *Manager) reset() {
+	m.next = State{
+		keysDown:        map[glfw.Key]bool{},
+		keysPressed:     map[glfw.Key]bool{},
+		keysReleased:    map[glfw.Key]bool{},
+		buttonsDown:     map[glfw.MouseButton]bool{},
+		buttonsPressed:  map[glfw.MouseButton]bool{},
+		buttonsReleased: map[glfw.MouseButton]bool{},
+	}
+}
+
+func (m *Manager) onKey(w *glfw.Window, key glfw.Key, scancode int, action glfw.Action, mods glfw.ModifierKey) {
+	switch action {
+	case glfw.Press:
+		m.next.keysDown[key] = true
+		m.next.keysPressed[key] = true
+	case glfw.Release:
+		delete(m.next.keysDown, key)
+		m.next.keysReleased[key] = true
+	}
+}
+
+func (m *Manager) onChar(w *glfw.Window, char rune) {
+	m.next.Chars = append(m.next.Chars, char)
+}
+
+func (m *Manager) onMouseButton(w *glfw.Window, button glfw.MouseButton, action glfw.Action, mods glfw.ModifierKey) {
+	switch action {
+	case glfw.Press:
+		m.next.buttonsDown[button] = true
+		m.next.buttonsPressed[button] = true
+	case glfw.Release:
+		delete(m.next.buttonsDown, button)
+		m.next.buttonsReleased[button] = true
+	}
+}
+
+func (m *Manager) onCursorPos(w *glfw.Window, x, y float64) {
+	m.cursor = mgl32.Vec2{float32(x), float32(y)}
+}
+
+func (m *Manager) onScroll(w *glfw.Window, x, y float64) {
+	m.next.Scroll = m.next.Scroll.Add(mgl32.Vec2{float32(x), float32(y)})
+}
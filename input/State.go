@@ -0,0 +1,88 @@
+package input
+
+import (
+	"github.com/go-gl/glfw/v3.2/glfw"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// State is a snapshot of the input received since the previous snapshot.
+// The zero value has nothing held or pressed, so it can be used headless.
+type State struct {
+	// Cursor is the cursor position in window coordinates, CursorDelta is how far it moved
+	Cursor      mgl32.Vec2
+	CursorDelta mgl32.Vec2
+	// Scroll is the scroll wheel offset
+	Scroll mgl32.Vec2
+	// Chars is the text typed, for text fields
+	Chars []rune
+
+	WindowSize mgl32.Vec2
+	Focused    bool
+
+	Bindings Bindings
+
+	keysDown        map[glfw.Key]bool
+	keysPressed     map[glfw.Key]bool
+	keysReleased    map[glfw.Key]bool
+	buttonsDown     map[glfw.MouseButton]bool
+	buttonsPressed  map[glfw.MouseButton]bool
+	buttonsReleased map[glfw.MouseButton]bool
+}
+
+// IsKeyDown returns whether the key is held
+func (s *State) IsKeyDown(key glfw.Key) bool {
+	return s.keysDown[key]
+}
+
+// IsKeyPressed returns whether the key went down since the previous snapshot
+func (s *State) IsKeyPressed(key glfw.Key) bool {
+	return s.keysPressed[key]
+}
+
+// IsKeyReleased returns whether the key went up since the previous snapshot
+func (s *State) IsKeyReleased(key glfw.Key) bool {
+	return s.keysReleased[key]
+}
+
+// IsButtonDown returns whether the mouse button is held
+func (s *State) IsButtonDown(button glfw.MouseButton) bool {
+	return s.buttonsDown[button]
+}
+
+// IsButtonPressed returns whether the mouse button went down since the previous snapshot
+func (s *State) IsButtonPressed(button glfw.MouseButton) bool {
+	return s.buttonsPressed[button]
+}
+
+// IsButtonReleased returns whether the mouse button went up since the previous snapshot
+func (s *State) IsButtonReleased(button glfw.MouseButton) bool {
+	return s.buttonsReleased[button]
+}
+
+// IsActionDown returns whether any binding of the action is held
+func (s *State) IsActionDown(action string) bool {
+	return s.anyBinding(action, s.IsKeyDown, s.IsButtonDown)
+}
+
+// IsActionPressed returns whether any binding of the action went down since the previous snapshot
+func (s *State) IsActionPressed(action string) bool {
+	return s.anyBinding(action, s.IsKeyPressed, s.IsButtonPressed)
+}
+
+// IsActionReleased returns whether any binding of the action went up since the previous snapshot
+func (s *State) IsActionReleased(action string) bool {
+	return s.anyBinding(action, s.IsKeyReleased, s.IsButtonReleased)
+}
+
+func (s *State) anyBinding(action string, key func(glfw.Key) bool, button func(glfw.MouseButton) bool) bool {
+	for _, b := range s.Bindings[action] {
+		if b.Mouse {
+			if button(b.Button) {
+				return true
+			}
+		} else if key(b.Key) {
+			return true
+		}
+	}
+	return false
+}
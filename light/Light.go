@@ -0,0 +1,133 @@
+package light
+
+import (
+	"fmt"
+	"math"
+
+	gl "github.com/go-gl/gl/v4.1-core/gl"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// Type is the kind of a Light, matching the LIGHT_* constants in GLSL
+type Type int32
+
+const (
+	// Ambient lights every surface equally
+	Ambient Type = iota
+	// Directional lights from a direction, like the sun
+	Directional
+	// Point lights in all directions from a position
+	Point
+	// Spot lights in a cone from a position
+	Spot
+)
+
+// MaxLights is the size of the uLights array in the default shader
+const MaxLights = 8
+
+// Light is a light source passed to the shaders through context.Render
+type Light struct {
+	Type      Type
+	Color     mgl32.Vec3
+	Intensity float32
+
+	// Position is used by Point and Spot lights
+	Position mgl32.Vec3
+	// Direction is used by Directional and Spot lights, and points away from the light
+	Direction mgl32.Vec3
+
+	// Constant, Linear and Quadratic attenuate Point and Spot lights over distance
+	Constant  float32
+	Linear    float32
+	Quadratic float32
+
+	// InnerCone and OuterCone are the half-angles in radians of a Spot light's
+	// full-intensity cone and the edge of its falloff
+	InnerCone float32
+	OuterCone float32
+}
+
+// NewAmbient returns a new Ambient Light
+func NewAmbient(color mgl32.Vec3, intensity float32) *Light {
+	return &Light{
+		Type:      Ambient,
+		Color:     color,
+		Intensity: intensity,
+	}
+}
+
+// NewDirectional returns a new Directional Light shining in the given direction
+func NewDirectional(direction, color mgl32.Vec3, intensity float32) *Light {
+	return &Light{
+		Type:      Directional,
+		Color:     color,
+		Intensity: intensity,
+		Direction: direction.Normalize(),
+	}
+}
+
+// NewPoint returns a new Point Light whose attenuation fades it out over roughly the given range
+func NewPoint(position, color mgl32.Vec3, intensity, lightRange float32) *Light {
+	l := &Light{
+		Type:      Point,
+		Color:     color,
+		Intensity: intensity,
+		Position:  position,
+	}
+	l.SetRange(lightRange)
+	return l
+}
+
+// NewSpot returns a new Spot Light, with inner and outer cone half-angles in radians
+func NewSpot(position, direction, color mgl32.Vec3, intensity, lightRange, inner, outer float32) *Light {
+	l := &Light{
+		Type:      Spot,
+		Color:     color,
+		Intensity: intensity,
+		Position:  position,
+		Direction: direction.Normalize(),
+		InnerCone: inner,
+		OuterCone: outer,
+	}
+	l.SetRange(lightRange)
+	return l
+}
+
+// SetRange sets the attenuation factors so the Light falls to about 1% at the given distance
+func (l *Light) SetRange(lightRange float32) {
+	if lightRange <= 0 {
+		l.Constant, l.Linear, l.Quadratic = 1, 0, 0
+		return
+	}
+	l.Constant = 1
+	l.Linear = 4.5 / lightRange
+	l.Quadratic = 75 / (lightRange * lightRange)
+}
+
+// Bind uploads up to MaxLights Lights to the uLights array and uLightCount, locate
+// is usually asset.Shader.GetUniformLocation
+func Bind(locate func(string) int32, lights []*Light) {
+	count := len(lights)
+	if count > MaxLights {
+		count = MaxLights
+	}
+
+	gl.Uniform1i(locate("uLightCount"), int32(count))
+	for i := 0; i < count; i++ {
+		l := lights[i]
+		prefix := fmt.Sprintf("uLights[%d].", i)
+
+		color := l.Color.Mul(l.Intensity)
+		cutoff := mgl32.Vec2{
+			float32(math.Cos(float64(l.InnerCone))),
+			float32(math.Cos(float64(l.OuterCone))),
+		}
+
+		gl.Uniform1i(locate(prefix+"Type"), int32(l.Type))
+		gl.Uniform3fv(locate(prefix+"Color"), 1, &color[0])
+		gl.Uniform3fv(locate(prefix+"Position"), 1, &l.Position[0])
+		gl.Uniform3fv(locate(prefix+"Direction"), 1, &l.Direction[0])
+		gl.Uniform3f(locate(prefix+"Attenuation"), l.Constant, l.Linear, l.Quadratic)
+		gl.Uniform2fv(locate(prefix+"Cutoff"), 1, &cutoff[0])
+	}
+}
@@ -0,0 +1,130 @@
+package loop
+
+// TimeScales are the simulation speeds the player can choose from
+var TimeScales = []float64{1, 2, 4}
+
+// Tick is the timing of one simulation tick
+type Tick struct {
+	// Step is the simulated seconds the tick covers
+	Step float64
+	// Number is the number of ticks run before this one
+	Number uint64
+	// TotalTime is the seconds simulated before this tick
+	TotalTime float64
+}
+
+// Loop runs a simulation at a fixed step, independent of the frame rate. Each
+// frame's time is added to an accumulator, and as many ticks as fit are run.
+type Loop struct {
+	// Step is the simulated seconds per tick
+	Step float64
+	// TimeScale multiplies the time added each frame, so 2 runs twice as many ticks
+	TimeScale float64
+	// MaxFrameTime caps the time added per frame, so a stall doesn't cause a burst of ticks
+	MaxFrameTime float64
+	Paused       bool
+
+	// OnTick runs the simulation for one Step
+	OnTick func(Tick)
+
+	tick        uint64
+	accumulator float64
+	pendingStep bool
+}
+
+// NewLoop returns a new Loop calling onTick every step seconds
+func NewLoop(step float64, onTick func(Tick)) *Loop {
+	return &Loop{
+		Step:         step,
+		TimeScale:    1,
+		MaxFrameTime: 0.25,
+		OnTick:       onTick,
+	}
+}
+
+// Advance adds frameTime real seconds to the Loop and runs the ticks that are due,
+// returning how many ran
+func (l *Loop) Advance(frameTime float64) int {
+	if l.Paused {
+		l.accumulator = 0
+		if l.pendingStep {
+			l.pendingStep = false
+			l.runTick()
+			return 1
+		}
+		return 0
+	}
+
+	if frameTime > l.MaxFrameTime {
+		frameTime = l.MaxFrameTime
+	}
+	l.accumulator += frameTime * l.TimeScale
+
+	ticks := 0
+	for l.accumulator >= l.Step {
+		l.accumulator -= l.Step
+		l.runTick()
+		ticks++
+	}
+	return ticks
+}
+
+func (l *Loop) runTick() {
+	if l.OnTick != nil {
+		l.OnTick(Tick{
+			Step:      l.Step,
+			Number:    l.tick,
+			TotalTime: l.GetTotalTime(),
+		})
+	}
+	l.tick++
+}
+
+// GetAlpha returns how far between the last tick and the next the Loop is, from 0 to 1,
+// for interpolating what is rendered
+func (l *Loop) GetAlpha() float32 {
+	if l.Paused {
+		return 0
+	}
+	return float32(l.accumulator / l.Step)
+}
+
+// GetTick returns the number of ticks run
+func (l *Loop) GetTick() uint64 {
+	return l.tick
+}
+
+// GetTotalTime returns the simulated seconds run
+func (l *Loop) GetTotalTime() float64 {
+	return float64(l.tick) * l.Step
+}
+
+// SetTick sets the number of ticks run, when restoring a saved simulation
+func (l *Loop) SetTick(tick uint64) {
+	l.tick = tick
+	l.accumulator = 0
+}
+
+// SetPaused pauses or resumes the Loop
+func (l *Loop) SetPaused(paused bool) {
+	l.Paused = paused
+}
+
+// TogglePause pauses a running Loop, or resumes a paused one
+func (l *Loop) TogglePause() {
+	l.Paused = !l.Paused
+}
+
+// SingleStep pauses the Loop and runs one tick on the next Advance
+func (l *Loop) SingleStep() {
+	l.Paused = true
+	l.pendingStep = true
+}
+
+// SetTimeScale sets the simulation speed, 1 being real time
+func (l *Loop) SetTimeScale(scale float64) {
+	if scale <= 0 {
+		scale = 1
+	}
+	l.TimeScale = scale
+}
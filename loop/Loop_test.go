@@ -0,0 +1,138 @@
+package loop
+
+import (
+	"testing"
+)
+
+func TestAdvance(t *testing.T) {
+	const step = 0.1
+
+	tests := []struct {
+		name string
+		// setup is run on the Loop before the frames
+		setup  func(l *Loop)
+		frames []float64
+		// ticks is how many ticks each frame must run
+		ticks []int
+	}{
+		{
+			name:   "accumulates partial steps",
+			frames: []float64{0.05, 0.05, 0.05, 0.05},
+			ticks:  []int{0, 1, 0, 1},
+		},
+		{
+			name:   "runs every due step",
+			frames: []float64{0.2, 0.21},
+			ticks:  []int{2, 2},
+		},
+		{
+			name:   "caps a long frame",
+			frames: []float64{10},
+			ticks:  []int{2},
+			setup:  func(l *Loop) { l.MaxFrameTime = 0.25 },
+		},
+		{
+			name:   "doubles the ticks at double time",
+			frames: []float64{0.1, 0.05},
+			ticks:  []int{2, 1},
+			setup:  func(l *Loop) { l.SetTimeScale(2) },
+		},
+		{
+			name:   "runs at real time for an invalid scale",
+			frames: []float64{0.1},
+			ticks:  []int{1},
+			setup:  func(l *Loop) { l.SetTimeScale(0) },
+		},
+		{
+			name:   "runs nothing while paused",
+			frames: []float64{0.2, 0.2},
+			ticks:  []int{0, 0},
+			setup:  func(l *Loop) { l.SetPaused(true) },
+		},
+		{
+			name:   "drops the time accumulated before a pause",
+			frames: []float64{0.05},
+			ticks:  []int{0},
+			setup: func(l *Loop) {
+				l.Advance(0.09)
+				l.SetPaused(true)
+				l.Advance(0)
+				l.SetPaused(false)
+			},
+		},
+		{
+			name:   "single steps once while paused",
+			frames: []float64{0, 0.2, 0.2},
+			ticks:  []int{1, 0, 0},
+			setup:  func(l *Loop) { l.SingleStep() },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ran := 0
+			l := NewLoop(step, func(Tick) { ran++ })
+			if tt.setup != nil {
+				tt.setup(l)
+			}
+			ran = 0
+			start := l.GetTick()
+
+			for i, frame := range tt.frames {
+				got := l.Advance(frame)
+				if got != tt.ticks[i] {
+					t.Errorf("frame %d: Advance(%v) ran %d ticks, want %d", i, frame, got, tt.ticks[i])
+				}
+			}
+
+			want := 0
+			for _, n := range tt.ticks {
+				want += n
+			}
+			if ran != want {
+				t.Errorf("OnTick ran %d times, want %d", ran, want)
+			}
+			if got := l.GetTick() - start; got != uint64(want) {
+				t.Errorf("GetTick advanced by %d, want %d", got, want)
+			}
+		})
+	}
+}
+
+func TestSetTick(t *testing.T) {
+	l := NewLoop(0.5, nil)
+	l.Advance(0.2)
+	l.SetTick(10)
+
+	if l.GetTick() != 10 {
+		t.Errorf("GetTick() = %d, want 10", l.GetTick())
+	}
+	if l.GetTotalTime() != 5 {
+		t.Errorf("GetTotalTime() = %v, want 5", l.GetTotalTime())
+	}
+	if l.GetAlpha() != 0 {
+		t.Errorf("GetAlpha() = %v, want 0 after SetTick", l.GetAlpha())
+	}
+}
+
+func TestTick(t *testing.T) {
+	ticks := []Tick{}
+	l := NewLoop(0.5, func(tick Tick) { ticks = append(ticks, tick) })
+	l.SetTick(4)
+	for i := 0; i < 4; i++ {
+		l.Advance(0.25)
+	}
+
+	want := []Tick{
+		{Step: 0.5, Number: 4, TotalTime: 2},
+		{Step: 0.5, Number: 5, TotalTime: 2.5},
+	}
+	if len(ticks) != len(want) {
+		t.Fatalf("OnTick ran %d times, want %d", len(ticks), len(want))
+	}
+	for i := range want {
+		if ticks[i] != want[i] {
+			t.Errorf("tick %d: OnTick(%+v), want %+v", i, ticks[i], want[i])
+		}
+	}
+}
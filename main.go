@@ -2,10 +2,12 @@ package main
 
 import (
 	"bytes"
+	"flag"
 	"fmt"
 	"image"
 	"image/color"
 	_ "image/png"
+	"os"
 	"runtime"
 
 	gl "github.com/go-gl/gl/v4.1-core/gl"
@@ -13,15 +15,39 @@ import (
 	"github.com/go-gl/mathgl/mgl32"
 
 	"github.com/WhoBrokeTheBuild/TelcomSim/asset"
+	"github.com/WhoBrokeTheBuild/TelcomSim/camera"
 	"github.com/WhoBrokeTheBuild/TelcomSim/context"
 	"github.com/WhoBrokeTheBuild/TelcomSim/data"
+	"github.com/WhoBrokeTheBuild/TelcomSim/events"
+	"github.com/WhoBrokeTheBuild/TelcomSim/input"
+	"github.com/WhoBrokeTheBuild/TelcomSim/light"
 	"github.com/WhoBrokeTheBuild/TelcomSim/log"
+	"github.com/WhoBrokeTheBuild/TelcomSim/loop"
+	"github.com/WhoBrokeTheBuild/TelcomSim/network"
+	"github.com/WhoBrokeTheBuild/TelcomSim/save"
+	"github.com/WhoBrokeTheBuild/TelcomSim/scenario"
+	"github.com/WhoBrokeTheBuild/TelcomSim/scene"
+	"github.com/WhoBrokeTheBuild/TelcomSim/sim"
 	"github.com/WhoBrokeTheBuild/TelcomSim/ui"
 )
 
 const (
 	windowWidth  int = 1024
 	windowHeight int = 768
+
+	// simStep is the seconds simulated per tick
+	simStep float64 = 1.0 / 20.0
+
+	// tariffStep is how much the per minute price changes per key press, loanStep how much is borrowed
+	tariffStep float64 = 0.002
+	loanStep   float64 = 100000
+)
+
+var (
+	recordFile   = flag.String("record", "", "Record the game to a replay `file` on exit")
+	scenarioName = flag.String("scenario", "sandbox", "Play the `name`d scenario")
+	campaignName = flag.String("campaign", "", "Play the `name`d campaign from its first scenario")
+	replayFile   = flag.String("replay", "", "Play a replay `file` without a window and print the final state hashes")
 )
 
 func init() {
@@ -42,10 +68,22 @@ var defaultShader *asset.Shader
 
 var hud *ui.Overlay
 var fps *ui.Text
+var status *ui.Text
+var notice *ui.Notice
 
 func main() {
 	var err error
 
+	flag.Parse()
+	if *replayFile != "" {
+		err = runReplay(*replayFile)
+		if err != nil {
+			log.Errorf("%v", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	log.Infof("CPU Cores: %v", runtime.NumCPU()-1)
 
 	err = glfw.Init()
@@ -121,70 +159,211 @@ func main() {
 	}
 	defer defaultShader.Delete()
 
-	defaultShader.Bind()
+	aspect := float32(windowWidth) / float32(windowHeight)
 
-	camera := mgl32.Vec3{3, 3, 3}
-	gl.Uniform3fv(defaultShader.GetUniformLocation("uCamera"), 1, &camera[0])
+	root, cam, err := initScene(aspect)
+	if err != nil {
+		panic(err)
+	}
+	defer root.Delete()
 
-	light := mgl32.Vec3{3, 3, 3}
-	gl.Uniform3fv(defaultShader.GetUniformLocation("uLight"), 1, &light[0])
+	inputMgr := input.NewManager(window)
+	defer inputMgr.Delete()
 
-	m, err := asset.NewModelFromFile("models/crate/crate.obj")
+	err = inputMgr.LoadBindings("config/bindings.json")
+	if err != nil {
+		log.Warnf("Using default bindings, %v", err)
+	}
+
+	frameCtx := &context.Update{
+		Input: &input.State{},
+	}
+	renderCtx := &context.Render{
+		Shader: defaultShader,
+	}
+
+	g := &game{
+		Root:   root,
+		Camera: cam,
+		Notice: notice,
+		Events: events.NewBus(),
+	}
+
+	// The simulation runs at a fixed rate, everything else once per frame. Each
+	// tick gets the input of the frame it runs in.
+	tickCtx := &context.Update{
+		Input: &input.State{},
+	}
+	simLoop := loop.NewLoop(simStep, func(t loop.Tick) {
+		tickCtx.DeltaTime = float32(t.Step)
+		tickCtx.ElapsedTime = t.Step
+		tickCtx.TotalTime = t.TotalTime
+		tickCtx.Tick = t.Number
+		updateSim(g, tickCtx)
+	})
+	g.Loop = simLoop
+
+	var campaign *scenario.Campaign
+	name := *scenarioName
+	if *campaignName != "" {
+		campaign, err = loadCampaign(*campaignName)
+		if err != nil {
+			panic(err)
+		}
+		name = campaign.Scenarios[0]
+	}
+	err = g.newGame(name, campaign, 0)
 	if err != nil {
 		panic(err)
 	}
-	defer m.Delete()
+	world := g.World
 
-	aspect := float32(windowWidth) / float32(windowHeight)
+	g.TechTree = newTechTree(world)
+	hud.AddComponent(g.TechTree)
+	g.Routing = newRoutingPanel(world)
+	hud.AddComponent(g.Routing)
+	g.Crews = newCrewPanel(g)
+	hud.AddComponent(g.Crews)
+	hud.AddComponent(newObjectiveList(g))
 
-	updateCtx := &context.Update{}
-	renderCtx := &context.Render{
-		View:       mgl32.LookAtV(mgl32.Vec3{2, 2, 2}, mgl32.Vec3{0, 0, 0}, mgl32.Vec3{0, 1, 0}),
-		Projection: mgl32.Perspective(mgl32.DegToRad(45.0), aspect, 0.1, 100.0),
-		Shader:     defaultShader,
+	bus := g.Events
+	g.subscribe(bus)
+
+	g.Alert, err = asset.NewSoundFromFile("sounds/alert.wav")
+	if err != nil {
+		log.Warnf("Alerts are silent, %v", err)
 	}
+	if g.Alert != nil {
+		defer g.Alert.Delete()
+	}
+
+	window.SetFramebufferSizeCallback(func(w *glfw.Window, width, height int) {
+		bus.Publish(events.WindowResized{Width: width, Height: height})
+	})
+	defer window.SetFramebufferSizeCallback(nil)
+
+	bus.Subscribe(events.WindowResizedType, func(e events.Event) {
+		wr := e.(events.WindowResized)
+		if wr.Width <= 0 || wr.Height <= 0 {
+			// Minimized
+			return
+		}
+		gl.Viewport(0, 0, int32(wr.Width), int32(wr.Height))
+		cam.Camera.SetAspect(float32(wr.Width) / float32(wr.Height))
+		if fps != nil {
+			fps.SetPosition(mgl32.Vec2{float32(wr.Width) - 60, 5})
+		}
+	})
+
+	if *recordFile != "" {
+		g.startRecording()
+		defer g.stopRecording(*recordFile)
+	}
+
+	wireframe := false
 
-	rotation := 0.0
 	update := func(ctx *context.Update) {
-		if window.GetKey(glfw.KeyF2) == glfw.Press {
-			gl.PolygonMode(gl.FRONT_AND_BACK, gl.LINE)
-		} else if window.GetKey(glfw.KeyF2) == glfw.Release {
-			gl.PolygonMode(gl.FRONT_AND_BACK, gl.FILL)
+		if ctx.Input.IsActionPressed("toggle_wireframe") {
+			wireframe = !wireframe
+			if wireframe {
+				gl.PolygonMode(gl.FRONT_AND_BACK, gl.LINE)
+			} else {
+				gl.PolygonMode(gl.FRONT_AND_BACK, gl.FILL)
+			}
+		}
+
+		if ctx.Input.IsActionPressed("toggle_coverage") {
+			g.toggleCoverage()
+		}
+		g.updateCoverage()
+
+		if ctx.Input.IsActionPressed("toggle_tech") {
+			g.togglePanel(&g.TechTree.Shown)
+		}
+		if ctx.Input.IsActionPressed("toggle_routing") {
+			g.togglePanel(&g.Routing.Shown)
+		}
+		if ctx.Input.IsActionPressed("toggle_crews") {
+			g.togglePanel(&g.Crews.Shown)
+		}
+		if ctx.Input.IsActionPressed("next_scenario") {
+			g.nextGame()
 		}
 
-		hud.Update(updateCtx)
-		rotation += ctx.ElapsedTime
+		if ctx.Input.IsActionPressed("pause") {
+			simLoop.TogglePause()
+		}
+		if ctx.Input.IsActionPressed("step") {
+			simLoop.SingleStep()
+		}
+		for i, scale := range loop.TimeScales {
+			if ctx.Input.IsActionPressed(fmt.Sprintf("speed_%d", i+1)) {
+				simLoop.SetTimeScale(scale)
+				simLoop.SetPaused(false)
+			}
+		}
+
+		if ctx.Input.IsActionPressed("quicksave") {
+			g.save(save.Quicksave)
+		}
+		if ctx.Input.IsActionPressed("quickload") {
+			g.load(save.Quicksave)
+		}
+
+		rates := world.Economy.Rates
+		if ctx.Input.IsActionPressed("tariff_up") {
+			world.Queue(sim.Command{Type: sim.SetTariff, PerMinute: rates.PerMinute + tariffStep, Subscription: rates.Subscription})
+		}
+		if ctx.Input.IsActionPressed("tariff_down") && rates.PerMinute >= tariffStep {
+			world.Queue(sim.Command{Type: sim.SetTariff, PerMinute: rates.PerMinute - tariffStep, Subscription: rates.Subscription})
+		}
+		if ctx.Input.IsActionPressed("borrow") {
+			world.Queue(sim.Command{Type: sim.Borrow, Amount: loanStep})
+		}
+
+		month := world.Economy.Month
+		tickCtx.Input = ctx.Input
+		simLoop.Advance(ctx.ElapsedTime)
+		if world.Economy.Month != month {
+			g.autosave()
+		}
+		updateStatus(world, simLoop)
 
-		m.Transform = mgl32.HomogRotate3D(float32(rotation), mgl32.Vec3{0, 1, 0})
+		// Everything published since the last update is handled at once, in order
+		bus.Drain()
+
+		hud.Update(ctx)
+		root.Update(ctx)
 	}
 
 	render := func(ctx *context.Render) {
-		m.Draw(renderCtx)
+		cam.Camera.Bind(ctx)
+		ctx.Lights = root.GetLights()
+
+		root.Draw(ctx)
 		hud.Draw()
 	}
 
 	//music.Play()
 
-	const (
-		frameDelay = 1.0 / 60.0
-		fpsDelay   = 1.0
-	)
+	const fpsDelay = 1.0
 	var (
-		frameElap  = 0.0
 		frameCount = 0
 		fpsElap    = 0.0
 	)
 
-	prev := glfw.GetTime()
+	start := glfw.GetTime()
+	prev := start
 	for !window.ShouldClose() {
 		time := glfw.GetTime()
 		elapsed := time - prev
 		prev = time
 
-		frameElap += elapsed
 		fpsElap += elapsed
-		updateCtx.DeltaTime = 0.0
-		updateCtx.ElapsedTime = elapsed
+		frameCtx.DeltaTime = float32(elapsed)
+		frameCtx.ElapsedTime = elapsed
+		frameCtx.TotalTime = time - start
+		frameCtx.Tick = simLoop.GetTick()
 
 		if fpsElap >= fpsDelay {
 			if fps != nil {
@@ -202,20 +381,115 @@ func main() {
 		}
 
 		glfw.PollEvents()
-		update(updateCtx)
+		frameCtx.Input = inputMgr.Snapshot()
+		update(frameCtx)
 
-		if frameElap >= frameDelay {
-			frameCount++
-			frameElap = 0.0
+		// SwapInterval(1) paces the frames to the display
+		frameCount++
 
-			cc := fromRGB(16, 163, 160)
-			gl.ClearColor(cc[0], cc[1], cc[2], 1.0)
-			gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
+		cc := fromRGB(16, 163, 160)
+		gl.ClearColor(cc[0], cc[1], cc[2], 1.0)
+		gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
 
-			render(renderCtx)
+		render(renderCtx)
 
-			window.SwapBuffers()
-		}
+		window.SwapBuffers()
+	}
+}
+
+func initScene(aspect float32) (*scene.Node, *scene.Node, error) {
+	root := scene.NewNode("root")
+
+	ambient := scene.NewNode("ambient")
+	ambient.Light = light.NewAmbient(mgl32.Vec3{1, 1, 1}, 0.15)
+	root.AddChild(ambient)
+
+	sun := scene.NewNode("sun")
+	sun.Light = light.NewDirectional(mgl32.Vec3{-1, -2, -1}, mgl32.Vec3{1, 0.95, 0.85}, 0.8)
+	root.AddChild(sun)
+
+	lamp := scene.NewNode("lamp")
+	lamp.SetTranslation(mgl32.Vec3{3, 3, 3})
+	lamp.Light = light.NewPoint(mgl32.Vec3{0, 0, 0}, mgl32.Vec3{1, 1, 1}, 1, 20)
+	root.AddChild(lamp)
+
+	mast := scene.NewNode("mast")
+	mast.SetScale(mgl32.Vec3{0.25, 1, 0.25})
+	root.AddChild(mast)
+
+	m, err := asset.NewModelFromFile("models/crate/crate.obj")
+	if err != nil {
+		root.Delete()
+		return nil, nil, err
+	}
+	mast.Model = m
+
+	// The dish is a child of the mast's top, so it inherits its position but not its scale
+	top := scene.NewNode("mast_top")
+	top.SetTranslation(mgl32.Vec3{0, 1.25, 0})
+	root.AddChild(top)
+
+	dish := scene.NewNode("dish")
+	dish.SetScale(mgl32.Vec3{0.5, 0.25, 0.1})
+	dish.OnUpdate = func(n *scene.Node, ctx *context.Update) {
+		rot := mgl32.QuatRotate(ctx.DeltaTime, mgl32.Vec3{0, 1, 0})
+		n.SetRotation(rot.Mul(n.GetRotation()))
+	}
+	top.AddChild(dish)
+
+	m, err = asset.NewModelFromFile("models/crate/crate.obj")
+	if err != nil {
+		root.Delete()
+		return nil, nil, err
+	}
+	dish.Model = m
+
+	cam := scene.NewNode("camera")
+	cam.Camera = camera.NewPerspective(mgl32.DegToRad(45.0), aspect, 0.1, 1000.0)
+	cam.Camera.Controller = camera.NewTopDown(mgl32.Vec2{24, 24}, 30)
+	root.AddChild(cam)
+
+	return root, cam, nil
+}
+
+// updateSim runs one tick of the World and the scenario played
+func updateSim(g *game, ctx *context.Update) {
+	g.World.Update(ctx.ElapsedTime)
+	if g.Scenario != nil {
+		g.Scenario.Update(g.World)
+	}
+}
+
+// updateStatus shows the date, money and service of the World in the top bar
+func updateStatus(world *sim.World, simLoop *loop.Loop) {
+	if status == nil {
+		return
+	}
+
+	speed := fmt.Sprintf("%gx", simLoop.TimeScale)
+	if simLoop.Paused {
+		speed = "Paused"
+	}
+
+	lines := 0
+	for _, n := range world.Network.GetNodesOfType(network.Subscriber) {
+		lines += n.Subscribers
+	}
+
+	text := fmt.Sprintf("%v  [%v]   $%.0f   Debt $%.0f   Lines %d   Blocking %.1f%%",
+		world.Calendar, speed, world.Economy.Balance, world.Economy.GetDebt(), lines,
+		world.Traffic.Stats.GetBlockingRate()*100)
+	if world.Map != nil {
+		text += fmt.Sprintf("   Mobiles %d/%d", world.Mobiles.GetServed(), world.Mobiles.GetCount())
+	}
+	if r := world.Research; r.Current != "" {
+		text += fmt.Sprintf("   Researching %v", r.Tree.GetTech(r.Current).Name)
+	}
+	if faults := len(world.Maintenance.Faults); faults > 0 {
+		text += fmt.Sprintf("   Faults %d", faults)
+	}
+	if text != status.Text {
+		status.SetText(text)
 	}
 }
 
@@ -226,9 +500,15 @@ func initUI() {
 	fps.SetPosition(mgl32.Vec2{float32(windowWidth) - 60, 5})
 	hud.AddComponent(fps)
 
-	menu := ui.NewText("File  Edit  Window", "ui/default.ttf", 18.0, color.White)
-	menu.SetPosition(mgl32.Vec2{10, 5})
-	hud.AddComponent(menu)
+	status = ui.NewText("Telcom Simulator", "ui/default.ttf", 18.0, color.White)
+	status.SetPosition(mgl32.Vec2{10, 5})
+	hud.AddComponent(status)
+
+	notice = ui.NewNotice("ui/default.ttf", 18.0, color.RGBA{255, 200, 0, 255}, 4)
+	if notice != nil {
+		notice.SetPosition(mgl32.Vec2{10, float32(windowHeight) - 30})
+		hud.AddComponent(notice)
+	}
 
 	//box := ui.NewImageFromFile("models/crate/crate.png")
 	//box.SetPosition(mgl32.Vec2{100, 100})
@@ -0,0 +1,107 @@
+package maintenance
+
+import (
+	"github.com/WhoBrokeTheBuild/TelcomSim/ecs"
+	"github.com/WhoBrokeTheBuild/TelcomSim/network"
+)
+
+// CrewState is what a Crew is doing
+type CrewState int
+
+const (
+	// Idle is waiting at its Base
+	Idle CrewState = iota
+	// Travelling is on its way to a Fault
+	Travelling
+	// Repairing is at a Fault, fixing it
+	Repairing
+	// Returning is on its way back to its Base
+	Returning
+)
+
+// String returns the name of the CrewState
+func (s CrewState) String() string {
+	switch s {
+	case Idle:
+		return "Idle"
+	case Travelling:
+		return "Travelling"
+	case Repairing:
+		return "Repairing"
+	case Returning:
+		return "Returning"
+	}
+	return "Unknown"
+}
+
+// Crew is a repair team with a truck, an Entity with a position in the Manager's Positions
+type Crew struct {
+	// Base is the Exchange the Crew works from
+	Base  network.NodeID
+	State CrewState
+	// Fault is the Fault the Crew is sent to, 0 if none
+	Fault FaultID
+	// Remaining is the simulated seconds left of a repair
+	Remaining float64
+	// Started is the simulated time the Crew left for its Fault, its wages are paid from then
+	Started float64
+}
+
+// CrewStore is a Store of Crew components
+type CrewStore struct {
+	Set  ecs.SparseSet
+	Data []Crew
+}
+
+// NewCrewStore returns a new empty CrewStore
+func NewCrewStore() *CrewStore {
+	return &CrewStore{
+		Data: []Crew{},
+	}
+}
+
+// GetSet returns the Entities with a Crew
+func (s *CrewStore) GetSet() *ecs.SparseSet {
+	return &s.Set
+}
+
+// Add sets the Crew of e
+func (s *CrewStore) Add(e ecs.Entity, c Crew) {
+	i, added := s.Set.Insert(e)
+	if added {
+		s.Data = append(s.Data, c)
+	} else {
+		s.Data[i] = c
+	}
+}
+
+// Get returns the Crew of e, valid until the Store is next added to or removed from, or nil
+func (s *CrewStore) Get(e ecs.Entity) *Crew {
+	if i := s.Set.IndexOf(e); i >= 0 {
+		return &s.Data[i]
+	}
+	return nil
+}
+
+// Remove removes the Crew of e, returning false if it had none
+func (s *CrewStore) Remove(e ecs.Entity) bool {
+	i, ok := s.Set.Delete(e)
+	if !ok {
+		return false
+	}
+	last := len(s.Data) - 1
+	s.Data[i] = s.Data[last]
+	s.Data = s.Data[:last]
+	return true
+}
+
+// MarshalJSON writes the Entities of the Store and their Crews
+func (s *CrewStore) MarshalJSON() ([]byte, error) {
+	return ecs.MarshalStore(&s.Set, s.Data)
+}
+
+// UnmarshalJSON replaces the Store with one written by MarshalJSON
+func (s *CrewStore) UnmarshalJSON(b []byte) error {
+	s.Data = []Crew{}
+	return ecs.UnmarshalStore(b, &s.Set, &s.Data)
+}
@@ -0,0 +1,28 @@
+package maintenance
+
+import (
+	"github.com/WhoBrokeTheBuild/TelcomSim/ecs"
+	"github.com/WhoBrokeTheBuild/TelcomSim/network"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// FaultID identifies a Fault, 0 is never used
+type FaultID int
+
+// Fault is a Node or Link that is down until a Crew repairs it
+type Fault struct {
+	ID FaultID
+	// Node or Link is the broken equipment, the other is 0
+	Node network.NodeID `json:",omitempty"`
+	Link network.LinkID `json:",omitempty"`
+
+	Equipment Equipment
+	Cause     string
+	// Position is where a Crew repairs it, the middle of a Link
+	Position mgl32.Vec2
+	// Time is the simulated time it failed
+	Time float64
+
+	// Crew is the Crew sent to repair it, 0 if none has been
+	Crew ecs.Entity `json:",omitempty"`
+}
@@ -0,0 +1,455 @@
+package maintenance
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+
+	"github.com/WhoBrokeTheBuild/TelcomSim/economy"
+	"github.com/WhoBrokeTheBuild/TelcomSim/ecs"
+	"github.com/WhoBrokeTheBuild/TelcomSim/events"
+	"github.com/WhoBrokeTheBuild/TelcomSim/network"
+	"github.com/WhoBrokeTheBuild/TelcomSim/rng"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// Config holds the costs and rates of a Manager. Times are in game days and
+// distances in kilometers.
+type Config struct {
+	// CrewSpeed is how far a Crew drives in a day
+	CrewSpeed float32
+	// CrewWage is what a Crew is paid for each day it is out on a repair
+	CrewWage float64
+	// HireCost is what hiring a Crew and its truck costs
+	HireCost float64
+
+	// StormRate is the number of Storms in a year
+	StormRate   float64
+	StormRadius float32
+	StormDays   float64
+}
+
+// DefaultConfig is the Config of a new Manager
+var DefaultConfig = Config{
+	CrewSpeed: 200,
+	CrewWage:  500,
+	HireCost:  25000,
+
+	StormRate:   6,
+	StormRadius: 10,
+	StormDays:   2,
+}
+
+const (
+	// CrewStoreName is the name the CrewStore is registered under
+	CrewStoreName = "crew"
+	// PositionStoreName is the name the positions of Crews are registered under
+	PositionStoreName = "crew_position"
+	// SystemName is the name the Manager runs under as a System
+	SystemName = "maintenance"
+)
+
+// Manager breaks Nodes and Links down, at random and as they wear out, and
+// sends Crews out to repair them. Equipment that is down carries no traffic
+// until its Crew has driven there and finished the repair.
+type Manager struct {
+	Network *network.Network `json:"-"`
+	Economy *economy.Economy `json:"-"`
+	// Events receives EquipmentFailed and EquipmentRepaired, if set
+	Events *events.Bus `json:"-"`
+	// Crews and Positions are the Stores of the Crew Entities
+	Crews     *CrewStore     `json:"-"`
+	Positions *ecs.Vec2Store `json:"-"`
+	// Changed is set when equipment fails or is repaired, for whoever caches the state of the Network to clear
+	Changed bool `json:"-"`
+
+	Rand        *rng.Rand
+	Reliability map[Equipment]Reliability
+	Config      Config
+	// SecondsPerDay is the simulated seconds in a game day
+	SecondsPerDay float64
+	// AutoDispatch sends the nearest free Crew to each new Fault
+	AutoDispatch bool
+
+	// Time is the simulated seconds run
+	Time        float64
+	Faults      []*Fault
+	NextFaultID FaultID
+	Storms      []*Storm
+
+	// NodeInstalled and LinkInstalled hold the simulated time each piece of
+	// equipment was built or last repaired, which its wear is counted from
+	NodeInstalled map[network.NodeID]float64
+	LinkInstalled map[network.LinkID]float64
+}
+
+// NewManager returns a Manager for net paying from econ, registering its Stores
+// and itself as a System of world
+func NewManager(world *ecs.World, net *network.Network, econ *economy.Economy, seed int64, secondsPerDay float64) *Manager {
+	reliability := map[Equipment]Reliability{}
+	for e, r := range DefaultReliability {
+		reliability[e] = r
+	}
+
+	m := &Manager{
+		Network:       net,
+		Economy:       econ,
+		Crews:         NewCrewStore(),
+		Positions:     ecs.NewVec2Store(),
+		Rand:          rng.New(seed),
+		Reliability:   reliability,
+		Config:        DefaultConfig,
+		SecondsPerDay: secondsPerDay,
+		AutoDispatch:  true,
+		Faults:        []*Fault{},
+		NextFaultID:   1,
+		Storms:        []*Storm{},
+		NodeInstalled: map[network.NodeID]float64{},
+		LinkInstalled: map[network.LinkID]float64{},
+	}
+
+	world.Register(CrewStoreName, m.Crews)
+	world.Register(PositionStoreName, m.Positions)
+	world.AddSystem(SystemName, 0, m)
+	return m
+}
+
+// GetFault returns the Fault with id, or nil
+func (m *Manager) GetFault(id FaultID) *Fault {
+	for _, f := range m.Faults {
+		if f.ID == id {
+			return f
+		}
+	}
+	return nil
+}
+
+// GetCrews returns every Crew Entity, in the order they were hired
+func (m *Manager) GetCrews() []ecs.Entity {
+	return m.Crews.GetSet().GetEntities()
+}
+
+// Hire adds a Crew based at an Exchange, paying HireCost
+func (m *Manager) Hire(world *ecs.World, base network.NodeID) (ecs.Entity, error) {
+	node := m.Network.GetNode(base)
+	if node == nil || node.Type != network.Exchange {
+		return 0, fmt.Errorf("No such exchange [%v]", base)
+	}
+	if !m.Economy.CanAfford(m.Config.HireCost) {
+		return 0, fmt.Errorf("Insufficient funds to hire a crew at [%v]", node.Name)
+	}
+	m.Economy.Record(economy.Repairs, -m.Config.HireCost, fmt.Sprintf("Hired a crew at %v", node.Name))
+
+	e := world.Create()
+	m.Crews.Add(e, Crew{Base: base, State: Idle})
+	m.Positions.Add(e, node.Position)
+	return e, nil
+}
+
+// Dispatch sends a Crew that isn't busy to repair a Fault no other Crew is sent to
+func (m *Manager) Dispatch(crew ecs.Entity, id FaultID) error {
+	c := m.Crews.Get(crew)
+	if c == nil {
+		return fmt.Errorf("No such crew [%v]", crew)
+	}
+	if c.State == Travelling || c.State == Repairing {
+		return fmt.Errorf("Crew [%v] is busy", crew)
+	}
+	f := m.GetFault(id)
+	if f == nil {
+		return fmt.Errorf("No such fault [%v]", id)
+	}
+	if f.Crew != 0 {
+		return fmt.Errorf("Fault [%v] already has crew [%v]", id, f.Crew)
+	}
+
+	f.Crew = crew
+	c.State = Travelling
+	c.Fault = id
+	c.Started = m.Time
+	return nil
+}
+
+// Update advances the Manager by dt simulated seconds, as a System of w
+func (m *Manager) Update(w *ecs.World, dt float64) {
+	m.Time += dt
+	days := dt / m.SecondsPerDay
+
+	m.updateStorms(days)
+	m.removeStaleFaults()
+	m.breakDown(days)
+	if m.AutoDispatch {
+		m.dispatchNearest()
+	}
+	m.updateCrews(dt)
+}
+
+// updateStorms ends the Storms that have passed, and starts new ones at StormRate
+func (m *Manager) updateStorms(days float64) {
+	kept := m.Storms[:0]
+	for _, s := range m.Storms {
+		if s.End > m.Time {
+			kept = append(kept, s)
+		}
+	}
+	m.Storms = kept
+
+	if m.Rand.Float64() >= 1-math.Exp(-m.Config.StormRate/365*days) {
+		return
+	}
+
+	sites := []*network.Node{}
+	for _, node := range m.Network.GetNodes() {
+		if node.Type != network.Subscriber {
+			sites = append(sites, node)
+		}
+	}
+	if len(sites) == 0 {
+		return
+	}
+
+	site := sites[m.Rand.Intn(len(sites))]
+	angle := m.Rand.Float64() * 2 * math.Pi
+	dist := m.Rand.Float64() * float64(m.Config.StormRadius)
+	m.Storms = append(m.Storms, &Storm{
+		Center: site.Position.Add(mgl32.Vec2{float32(math.Sin(angle) * dist), float32(-math.Cos(angle) * dist)}),
+		Radius: m.Config.StormRadius,
+		End:    m.Time + m.Config.StormDays*m.SecondsPerDay,
+	})
+}
+
+// inStorm returns whether pos is inside any Storm
+func (m *Manager) inStorm(pos mgl32.Vec2) bool {
+	for _, s := range m.Storms {
+		if s.Contains(pos) {
+			return true
+		}
+	}
+	return false
+}
+
+// breakDown fails each working Node and Link with the chance it fails in days
+func (m *Manager) breakDown(days float64) {
+	for _, node := range m.Network.GetNodes() {
+		equipment := GetNodeEquipment(node)
+		if equipment == "" || node.Down {
+			continue
+		}
+		installed, found := m.NodeInstalled[node.ID]
+		if !found {
+			installed = m.Time
+			m.NodeInstalled[node.ID] = installed
+		}
+		if cause, failed := m.sample(equipment, installed, 1, node.Position, days); failed {
+			node.Down = true
+			m.addFault(&Fault{Node: node.ID, Equipment: equipment, Cause: cause, Position: node.Position})
+		}
+	}
+
+	for _, link := range m.Network.GetLinks() {
+		if link.Down {
+			continue
+		}
+		installed, found := m.LinkInstalled[link.ID]
+		if !found {
+			installed = m.Time
+			m.LinkInstalled[link.ID] = installed
+		}
+		equipment := GetLinkEquipment(link)
+		pos := m.getLinkMiddle(link)
+		scale := math.Max(float64(link.Length)/ReferenceLength, 0.1)
+		if cause, failed := m.sample(equipment, installed, scale, pos, days); failed {
+			link.Down = true
+			m.addFault(&Fault{Link: link.ID, Equipment: equipment, Cause: cause, Position: pos})
+		}
+	}
+}
+
+// sample returns whether Equipment at pos installed at the given time fails in
+// days, and why. One random number is drawn whatever the result, so runs stay
+// in step.
+func (m *Manager) sample(equipment Equipment, installed, scale float64, pos mgl32.Vec2, days float64) (string, bool) {
+	r := m.Reliability[equipment]
+	age := (m.Time - installed) / m.SecondsPerDay
+	rate := r.GetFailureRate(age, scale)
+	storm := m.inStorm(pos)
+	if storm {
+		rate *= r.StormFactor
+	}
+
+	if m.Rand.Float64() >= 1-math.Exp(-rate*days) {
+		return "", false
+	}
+	if storm {
+		return r.StormCause, true
+	}
+	return r.Cause, true
+}
+
+// getLinkMiddle returns the point halfway along a Link
+func (m *Manager) getLinkMiddle(link *network.Link) mgl32.Vec2 {
+	a := m.Network.GetNode(link.A).Position
+	b := m.Network.GetNode(link.B).Position
+	return a.Add(b).Mul(0.5)
+}
+
+// addFault records a failure and publishes it
+func (m *Manager) addFault(f *Fault) {
+	f.ID = m.NextFaultID
+	f.Time = m.Time
+	m.NextFaultID++
+	m.Faults = append(m.Faults, f)
+	m.Changed = true
+	m.Events.Publish(events.EquipmentFailed{Node: f.Node, Link: f.Link, Cause: f.Cause})
+}
+
+// removeStaleFaults drops the Faults of equipment that has been removed, sending their Crews home
+func (m *Manager) removeStaleFaults() {
+	kept := m.Faults[:0]
+	for _, f := range m.Faults {
+		if (f.Node != 0 && m.Network.GetNode(f.Node) != nil) || (f.Link != 0 && m.Network.GetLink(f.Link) != nil) {
+			kept = append(kept, f)
+			continue
+		}
+		if c := m.Crews.Get(f.Crew); c != nil {
+			m.payCrew(c, 0, "Called off")
+			c.State = Returning
+			c.Fault = 0
+		}
+	}
+	m.Faults = kept
+
+	for id := range m.NodeInstalled {
+		if m.Network.GetNode(id) == nil {
+			delete(m.NodeInstalled, id)
+		}
+	}
+	for id := range m.LinkInstalled {
+		if m.Network.GetLink(id) == nil {
+			delete(m.LinkInstalled, id)
+		}
+	}
+}
+
+// dispatchNearest sends the nearest free Crew to each Fault without one, oldest Fault first
+func (m *Manager) dispatchNearest() {
+	for _, f := range m.Faults {
+		if f.Crew != 0 {
+			continue
+		}
+
+		var best ecs.Entity
+		bestDist := float32(math.Inf(1))
+		for i, e := range m.GetCrews() {
+			c := &m.Crews.Data[i]
+			if c.State == Travelling || c.State == Repairing {
+				continue
+			}
+			if dist := m.Positions.Get(e).Sub(f.Position).Len(); dist < bestDist {
+				best = e
+				bestDist = dist
+			}
+		}
+		if best == 0 {
+			return
+		}
+		m.Dispatch(best, f.ID)
+	}
+}
+
+// updateCrews drives each Crew toward its Fault or Base, and works on the repairs of those that are there
+func (m *Manager) updateCrews(dt float64) {
+	step := m.Config.CrewSpeed * float32(dt/m.SecondsPerDay)
+	for i, e := range m.GetCrews() {
+		c := &m.Crews.Data[i]
+		pos := m.Positions.Get(e)
+
+		switch c.State {
+		case Travelling:
+			f := m.GetFault(c.Fault)
+			if moveToward(pos, f.Position, step) {
+				c.State = Repairing
+				c.Remaining = m.Reliability[f.Equipment].RepairTime * m.SecondsPerDay
+			}
+
+		case Repairing:
+			c.Remaining -= dt
+			if c.Remaining <= 0 {
+				m.repair(c)
+			}
+
+		case Returning:
+			base := m.Network.GetNode(c.Base)
+			if base == nil || moveToward(pos, base.Position, step) {
+				c.State = Idle
+			}
+		}
+	}
+}
+
+// moveToward moves pos up to step toward target, returning whether it got there
+func moveToward(pos *mgl32.Vec2, target mgl32.Vec2, step float32) bool {
+	d := target.Sub(*pos)
+	if d.Len() <= step {
+		*pos = target
+		return true
+	}
+	*pos = pos.Add(d.Normalize().Mul(step))
+	return false
+}
+
+// repair brings the equipment of the Fault a Crew is at back up, pays for it and sends the Crew home
+func (m *Manager) repair(c *Crew) {
+	f := m.GetFault(c.Fault)
+	name := ""
+	if node := m.Network.GetNode(f.Node); node != nil {
+		node.Down = false
+		m.NodeInstalled[f.Node] = m.Time
+		name = node.Name
+	}
+	if link := m.Network.GetLink(f.Link); link != nil {
+		link.Down = false
+		m.LinkInstalled[f.Link] = m.Time
+		name = fmt.Sprintf("%.1fkm %v link", link.Length, link.Type)
+	}
+
+	cost := m.payCrew(c, m.Reliability[f.Equipment].RepairCost, fmt.Sprintf("Repaired %v", name))
+	m.Changed = true
+	m.Events.Publish(events.EquipmentRepaired{Node: f.Node, Link: f.Link, Cost: cost})
+
+	for i := range m.Faults {
+		if m.Faults[i] == f {
+			m.Faults = append(m.Faults[:i], m.Faults[i+1:]...)
+			break
+		}
+	}
+	c.State = Returning
+	c.Fault = 0
+}
+
+// payCrew records the wages of a Crew since it set out and parts costing parts,
+// returning the total
+func (m *Manager) payCrew(c *Crew, parts float64, memo string) float64 {
+	cost := parts + m.Config.CrewWage*(m.Time-c.Started)/m.SecondsPerDay
+	m.Economy.Record(economy.Repairs, -cost, memo)
+	return cost
+}
+
+// UnmarshalJSON replaces the Manager with one written by json.Marshal, keeping
+// the Network, Economy, Events and Stores it works on
+func (m *Manager) UnmarshalJSON(data []byte) error {
+	type manager Manager
+	mj := manager(*m)
+	mj.Reliability = map[Equipment]Reliability{}
+	mj.Faults = []*Fault{}
+	mj.Storms = []*Storm{}
+	mj.NodeInstalled = map[network.NodeID]float64{}
+	mj.LinkInstalled = map[network.LinkID]float64{}
+	err := json.Unmarshal(data, &mj)
+	if err != nil {
+		return err
+	}
+
+	*m = Manager(mj)
+	return nil
+}
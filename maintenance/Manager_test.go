@@ -0,0 +1,453 @@
+package maintenance
+
+import (
+	"math"
+	"testing"
+
+	"github.com/WhoBrokeTheBuild/TelcomSim/economy"
+	"github.com/WhoBrokeTheBuild/TelcomSim/ecs"
+	"github.com/WhoBrokeTheBuild/TelcomSim/events"
+	"github.com/WhoBrokeTheBuild/TelcomSim/network"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// testSecondsPerDay keeps days short, and a quarter of one exact
+const testSecondsPerDay = 100
+
+// newTestManager returns a Manager over an empty Network with no Storms and
+// nothing failing on its own, so tests add the failures they need
+func newTestManager(seed int64) (*ecs.World, *network.Network, *Manager) {
+	world := ecs.NewWorld()
+	net := network.NewNetwork()
+	m := NewManager(world, net, economy.NewEconomy(1000000), seed, testSecondsPerDay)
+	m.Events = events.NewBus()
+	m.Config.StormRate = 0
+	for e, r := range m.Reliability {
+		r.MTBF = 0
+		m.Reliability[e] = r
+	}
+	return world, net, m
+}
+
+// breakNode fails node as breakDown does, returning the Fault
+func breakNode(m *Manager, node *network.Node) *Fault {
+	node.Down = true
+	m.addFault(&Fault{Node: node.ID, Equipment: GetNodeEquipment(node), Cause: "test", Position: node.Position})
+	return m.Faults[len(m.Faults)-1]
+}
+
+func TestGetFailureRate(t *testing.T) {
+	tests := []struct {
+		name  string
+		r     Reliability
+		age   float64
+		scale float64
+		want  float64
+	}{
+		{name: "random when new", r: Reliability{MTBF: 100, Shape: 1}, age: 0, scale: 1, want: 0.01},
+		{name: "random when old", r: Reliability{MTBF: 100, Shape: 1}, age: 500, scale: 1, want: 0.01},
+		{name: "no shape is random", r: Reliability{MTBF: 100}, age: 500, scale: 1, want: 0.01},
+		{name: "wearing when new", r: Reliability{MTBF: 100, Shape: 2}, age: 0, scale: 1, want: 0},
+		{name: "wearing at the MTBF", r: Reliability{MTBF: 100, Shape: 2}, age: 100, scale: 1, want: 0.02},
+		{name: "wearing at twice the MTBF", r: Reliability{MTBF: 100, Shape: 2}, age: 200, scale: 1, want: 0.04},
+		{name: "scaled", r: Reliability{MTBF: 100, Shape: 1}, age: 0, scale: 2.5, want: 0.025},
+		{name: "never fails", r: Reliability{MTBF: 0, Shape: 1}, age: 100, scale: 1, want: 0},
+	}
+
+	for _, tt := range tests {
+		if got := tt.r.GetFailureRate(tt.age, tt.scale); math.Abs(got-tt.want) > 1e-12 {
+			t.Errorf("%v: GetFailureRate() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestBreakDown(t *testing.T) {
+	const towers = 2000
+
+	tests := []struct {
+		name  string
+		r     Reliability
+		storm bool
+		// age is the days since the towers were installed, when they are sampled
+		age float64
+		// rate is the failures per day expected, each tower failing in the day
+		// with a chance of 1 - e^-rate
+		rate  float64
+		cause string
+	}{
+		{
+			name: "at random",
+			r:    Reliability{MTBF: 10, Shape: 1, StormFactor: 3, Cause: "failure", StormCause: "storm"},
+			rate: 0.1, cause: "failure",
+		},
+		{
+			name: "wearing out when new",
+			r:    Reliability{MTBF: 10, Shape: 2, StormFactor: 3, Cause: "failure", StormCause: "storm"},
+			rate: 0, cause: "failure",
+		},
+		{
+			name: "worn out",
+			r:    Reliability{MTBF: 10, Shape: 2, StormFactor: 3, Cause: "failure", StormCause: "storm"},
+			age:  10, rate: 0.2, cause: "failure",
+		},
+		{
+			name:  "in a storm",
+			r:     Reliability{MTBF: 10, Shape: 1, StormFactor: 3, Cause: "failure", StormCause: "storm"},
+			storm: true, rate: 0.3, cause: "storm",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			world, net, m := newTestManager(1)
+			m.AutoDispatch = false
+			m.Reliability[TowerEquipment] = tt.r
+			for i := 0; i < towers; i++ {
+				tower := net.AddTower("T", mgl32.Vec2{float32(i % 50), float32(i / 50)})
+				m.NodeInstalled[tower.ID] = (1 - tt.age) * testSecondsPerDay
+			}
+			if tt.storm {
+				m.Storms = append(m.Storms, &Storm{Center: mgl32.Vec2{25, 20}, Radius: 100, End: 2 * testSecondsPerDay})
+			}
+
+			m.Update(world, testSecondsPerDay)
+
+			// Within 4 standard deviations of the expected number of failures
+			p := 1 - math.Exp(-tt.rate)
+			want := towers * p
+			tolerance := 4 * math.Sqrt(towers*p*(1-p))
+			if got := float64(len(m.Faults)); math.Abs(got-want) > tolerance {
+				t.Errorf("%v towers failed, want %.0f ± %.0f", got, want, tolerance)
+			}
+
+			down := 0
+			for _, node := range net.GetNodes() {
+				if node.Down {
+					down++
+				}
+			}
+			if down != len(m.Faults) || m.Events.GetPending() != len(m.Faults) {
+				t.Errorf("%d faults with %d towers down and %d events", len(m.Faults), down, m.Events.GetPending())
+			}
+			for _, f := range m.Faults {
+				if f.Cause != tt.cause || f.Equipment != TowerEquipment {
+					t.Errorf("fault %v is %v of %v, want %v of %v", f.ID, f.Cause, f.Equipment, tt.cause, TowerEquipment)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestBreakDownSeed(t *testing.T) {
+	run := func(seed int64) []network.NodeID {
+		world, net, m := newTestManager(seed)
+		m.Reliability[ExchangeEquipment] = Reliability{MTBF: 5, Shape: 1.5}
+		m.Config.StormRate = 50
+		for i := 0; i < 100; i++ {
+			net.AddExchange("A", mgl32.Vec2{float32(i), 0})
+		}
+		for i := 0; i < 10; i++ {
+			m.Update(world, testSecondsPerDay)
+		}
+
+		failed := []network.NodeID{}
+		for _, f := range m.Faults {
+			failed = append(failed, f.Node)
+		}
+		return failed
+	}
+
+	a, b := run(3), run(3)
+	if len(a) == 0 || len(a) != len(b) {
+		t.Fatalf("runs of the same seed had %d and %d failures", len(a), len(b))
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			t.Fatalf("failure %d was of %v then %v with the same seed", i, a[i], b[i])
+		}
+	}
+}
+
+func TestStorms(t *testing.T) {
+	world, net, m := newTestManager(5)
+	site := net.AddExchange("A", mgl32.Vec2{50, 50})
+	net.AddSubscriber("S", mgl32.Vec2{0, 0}, 100)
+	// Certain to start a Storm on the first day
+	m.Config.StormRate = 365 * 100
+
+	m.Update(world, testSecondsPerDay)
+	if len(m.Storms) != 1 {
+		t.Fatalf("%d storms started, want 1", len(m.Storms))
+	}
+	s := m.Storms[0]
+	if s.Center.Sub(site.Position).Len() > m.Config.StormRadius {
+		t.Errorf("storm at %v, want it within %v of the exchange", s.Center, m.Config.StormRadius)
+	}
+	if want := m.Time + m.Config.StormDays*testSecondsPerDay; s.End != want {
+		t.Errorf("storm ends at %v, want %v", s.End, want)
+	}
+
+	// Storms pass once they end
+	m.Config.StormRate = 0
+	m.Update(world, m.Config.StormDays*testSecondsPerDay)
+	if len(m.Storms) != 0 {
+		t.Errorf("%d storms left after they ended", len(m.Storms))
+	}
+}
+
+func TestDispatchNearest(t *testing.T) {
+	tests := []struct {
+		name string
+		// busy are the crews sent elsewhere first
+		busy  []int
+		fault mgl32.Vec2
+		// want is the crew sent, -1 for none
+		want int
+	}{
+		{name: "nearest to the west", fault: mgl32.Vec2{4, 0}, want: 0},
+		{name: "nearest in the middle", fault: mgl32.Vec2{11, 3}, want: 1},
+		{name: "nearest to the east", fault: mgl32.Vec2{16, 0}, want: 2},
+		{name: "nearest busy", busy: []int{2}, fault: mgl32.Vec2{16, 0}, want: 1},
+		{name: "all busy", busy: []int{0, 1, 2}, fault: mgl32.Vec2{16, 0}, want: -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			world, net, m := newTestManager(1)
+			crews := []ecs.Entity{}
+			for _, x := range []float32{0, 10, 20} {
+				a := net.AddExchange("A", mgl32.Vec2{x, 0})
+				crew, err := m.Hire(world, a.ID)
+				if err != nil {
+					t.Fatal(err)
+				}
+				crews = append(crews, crew)
+			}
+			for _, i := range tt.busy {
+				elsewhere := breakNode(m, net.AddTower("Elsewhere", mgl32.Vec2{100, 100}))
+				if err := m.Dispatch(crews[i], elsewhere.ID); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			f := breakNode(m, net.AddTower("T", tt.fault))
+			m.Update(world, 1)
+
+			want := ecs.Entity(0)
+			if tt.want >= 0 {
+				want = crews[tt.want]
+			}
+			if f.Crew != want {
+				t.Errorf("crew %v was sent, want %v", f.Crew, want)
+			}
+			if want != 0 {
+				if c := m.Crews.Get(want); c.State != Travelling || c.Fault != f.ID {
+					t.Errorf("crew is %v to fault %v, want travelling to %v", c.State, c.Fault, f.ID)
+				}
+			}
+		})
+	}
+}
+
+func TestDispatchErrors(t *testing.T) {
+	world, net, m := newTestManager(1)
+	m.AutoDispatch = false
+	a := net.AddExchange("A", mgl32.Vec2{0, 0})
+	first, _ := m.Hire(world, a.ID)
+	second, _ := m.Hire(world, a.ID)
+	f := breakNode(m, net.AddTower("T", mgl32.Vec2{5, 0}))
+	g := breakNode(m, net.AddTower("U", mgl32.Vec2{6, 0}))
+	if err := m.Dispatch(first, f.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		crew  ecs.Entity
+		fault FaultID
+	}{
+		{name: "busy crew", crew: first, fault: g.ID},
+		{name: "missing crew", crew: 99, fault: g.ID},
+		{name: "missing fault", crew: second, fault: 99},
+		{name: "fault with a crew", crew: second, fault: f.ID},
+	}
+	for _, tt := range tests {
+		if err := m.Dispatch(tt.crew, tt.fault); err == nil {
+			t.Errorf("%v: Dispatch succeeded, want an error", tt.name)
+		}
+	}
+}
+
+func TestHire(t *testing.T) {
+	world, net, m := newTestManager(1)
+	a := net.AddExchange("A", mgl32.Vec2{0, 0})
+	tower := net.AddTower("T", mgl32.Vec2{5, 0})
+
+	if _, err := m.Hire(world, tower.ID); err == nil {
+		t.Errorf("hired a crew at a tower")
+	}
+	m.Economy.Balance = m.Config.HireCost - 1
+	if _, err := m.Hire(world, a.ID); err == nil {
+		t.Errorf("hired a crew that can't be afforded")
+	}
+	m.Economy.Balance = m.Config.HireCost
+	crew, err := m.Hire(world, a.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := m.Economy.Ledger.GetTotal(0, economy.Repairs); got != -m.Config.HireCost {
+		t.Errorf("Repairs total = %v, want the hire cost %v", got, -m.Config.HireCost)
+	}
+	if pos := m.Positions.Get(crew); pos == nil || *pos != a.Position {
+		t.Errorf("crew is at %v, want at its base %v", pos, a.Position)
+	}
+	if len(m.GetCrews()) != 1 {
+		t.Errorf("%d crews, want the 1 hired", len(m.GetCrews()))
+	}
+}
+
+func TestRepair(t *testing.T) {
+	tests := []struct {
+		name string
+		// link breaks the Link to the tower instead of the tower
+		link bool
+		// repaired is the time the repair is done, and returned the time the
+		// Crew is back at its Base
+		repaired float64
+		returned float64
+		// cost is the parts and the wages from when the Crew set out at 25
+		cost float64
+	}{
+		// 4 quarter days out at 20km a day, 2 days repairing and 4 quarter days back
+		{name: "tower", repaired: 300, returned: 400, cost: 15000 + 500*2.75},
+		// Half way out, and the same 2 day repair
+		{name: "fiber link", link: true, repaired: 250, returned: 300, cost: 10000 + 500*2.25},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			world, net, m := newTestManager(1)
+			m.Config.CrewSpeed = 20
+			a := net.AddExchange("A", mgl32.Vec2{0, 0})
+			tower := net.AddTower("T", mgl32.Vec2{20, 0})
+			link, err := net.Connect(a.ID, tower.ID, network.Fiber)
+			if err != nil {
+				t.Fatal(err)
+			}
+			crew, _ := m.Hire(world, a.ID)
+			hired := m.Economy.Balance
+
+			if tt.link {
+				link.Down = true
+				m.addFault(&Fault{Link: link.ID, Equipment: FiberEquipment, Position: m.getLinkMiddle(link)})
+			} else {
+				breakNode(m, tower)
+			}
+			m.Events.Drain()
+			repaired := []events.EquipmentRepaired{}
+			m.Events.Subscribe(events.EquipmentRepairedType, func(e events.Event) {
+				repaired = append(repaired, e.(events.EquipmentRepaired))
+			})
+
+			for m.Time < tt.returned {
+				m.Update(world, testSecondsPerDay/4)
+				m.Events.Drain()
+
+				c := m.Crews.Get(crew)
+				fixed := m.Time >= tt.repaired
+				if tower.Down == fixed && !tt.link || link.Down == fixed && tt.link {
+					t.Fatalf("at %v the equipment is down %v, want it repaired at %v", m.Time, !fixed, tt.repaired)
+				}
+				if m.Time == tt.repaired && c.State != Returning {
+					t.Errorf("crew is %v after the repair, want Returning", c.State)
+				}
+			}
+
+			c := m.Crews.Get(crew)
+			if c.State != Idle || *m.Positions.Get(crew) != a.Position {
+				t.Errorf("crew is %v at %v, want Idle at its base", c.State, *m.Positions.Get(crew))
+			}
+			if len(m.Faults) != 0 {
+				t.Errorf("%d faults left after the repair", len(m.Faults))
+			}
+			if len(repaired) != 1 || repaired[0].Cost != tt.cost {
+				t.Errorf("repairs published %+v, want one costing %v", repaired, tt.cost)
+			}
+			if spent := hired - m.Economy.Balance; spent != tt.cost {
+				t.Errorf("repair cost %v, want %v", spent, tt.cost)
+			}
+			if got := m.Economy.Ledger.GetTotal(0, economy.Repairs); got != -m.Config.HireCost-tt.cost {
+				t.Errorf("Repairs total = %v, want %v", got, -m.Config.HireCost-tt.cost)
+			}
+		})
+	}
+}
+
+func TestRemoveStaleFaults(t *testing.T) {
+	tests := []struct {
+		name   string
+		remove func(net *network.Network, tower *network.Node, link *network.Link)
+	}{
+		{
+			name: "node",
+			remove: func(net *network.Network, tower *network.Node, link *network.Link) {
+				net.RemoveNode(tower.ID)
+			},
+		},
+		{
+			name: "link",
+			remove: func(net *network.Network, tower *network.Node, link *network.Link) {
+				net.RemoveLink(link.ID)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			world, net, m := newTestManager(1)
+			m.Config.CrewSpeed = 20
+			a := net.AddExchange("A", mgl32.Vec2{0, 0})
+			tower := net.AddTower("T", mgl32.Vec2{40, 0})
+			link, err := net.Connect(a.ID, tower.ID, network.Fiber)
+			if err != nil {
+				t.Fatal(err)
+			}
+			crew, _ := m.Hire(world, a.ID)
+
+			if tt.name == "node" {
+				breakNode(m, tower)
+			} else {
+				link.Down = true
+				m.addFault(&Fault{Link: link.ID, Equipment: FiberEquipment, Position: m.getLinkMiddle(link)})
+			}
+			m.Update(world, testSecondsPerDay/4)
+			m.Update(world, testSecondsPerDay/4)
+			if c := m.Crews.Get(crew); c.State != Travelling {
+				t.Fatalf("crew is %v, want Travelling", c.State)
+			}
+			balance := m.Economy.Balance
+
+			tt.remove(net, tower, link)
+			m.Update(world, testSecondsPerDay/4)
+
+			if len(m.Faults) != 0 {
+				t.Errorf("%d faults left of removed equipment", len(m.Faults))
+			}
+			if c := m.Crews.Get(crew); c.State != Returning || c.Fault != 0 {
+				t.Errorf("crew is %v to fault %v, want Returning", c.State, c.Fault)
+			}
+			// Called off after half a day out, from 25 to 75
+			if spent := balance - m.Economy.Balance; spent != m.Config.CrewWage/2 {
+				t.Errorf("called off crew cost %v, want half a day's wages %v", spent, m.Config.CrewWage/2)
+			}
+			if _, found := m.NodeInstalled[tower.ID]; found && tt.name == "node" {
+				t.Errorf("install time of the removed tower was kept")
+			}
+			if _, found := m.LinkInstalled[link.ID]; found {
+				t.Errorf("install time of the removed link was kept")
+			}
+		})
+	}
+}
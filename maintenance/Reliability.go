@@ -0,0 +1,123 @@
+package maintenance
+
+import (
+	"math"
+
+	"github.com/WhoBrokeTheBuild/TelcomSim/network"
+)
+
+// Equipment is a kind of Node or Link that can fail
+type Equipment string
+
+const (
+	// ExchangeEquipment is an Exchange, which fails by losing power
+	ExchangeEquipment Equipment = "exchange"
+	// TowerEquipment is a Tower, which fails in bad weather
+	TowerEquipment Equipment = "tower"
+	// CopperEquipment is a Copper Link, often cut by digging
+	CopperEquipment Equipment = "copper"
+	// FiberEquipment is a Fiber Link
+	FiberEquipment Equipment = "fiber"
+	// MicrowaveEquipment is a Microwave Link, which fails in bad weather
+	MicrowaveEquipment Equipment = "microwave"
+)
+
+// Reliability is how often a kind of Equipment fails and what fixing it takes.
+// Times are in game days.
+type Reliability struct {
+	// MTBF is the mean time between failures, for Links of ReferenceLength
+	MTBF float64
+	// Shape is the Weibull shape of the failure rate over the time since the last
+	// repair: 1 fails at random, above 1 wears out and fails more the older it gets
+	Shape float64
+	// StormFactor multiplies the failure rate inside a Storm
+	StormFactor float64
+
+	// RepairTime is how long a Crew takes to repair it once there
+	RepairTime float64
+	// RepairCost is what the parts cost
+	RepairCost float64
+
+	// Cause describes a failure, and StormCause a failure inside a Storm
+	Cause      string
+	StormCause string
+}
+
+// ReferenceLength is the length in kilometers of a Link the MTBF is given for,
+// longer Links fail more often
+const ReferenceLength = 10.0
+
+// DefaultReliability is the Reliability of each Equipment in a new Manager
+var DefaultReliability = map[Equipment]Reliability{
+	ExchangeEquipment: {
+		MTBF: 3650, Shape: 1.5, StormFactor: 3,
+		RepairTime: 1, RepairCost: 20000,
+		Cause: "power loss", StormCause: "power cut by a storm",
+	},
+	TowerEquipment: {
+		MTBF: 1460, Shape: 1.5, StormFactor: 25,
+		RepairTime: 2, RepairCost: 15000,
+		Cause: "equipment failure", StormCause: "storm damage",
+	},
+	CopperEquipment: {
+		MTBF: 730, Shape: 1, StormFactor: 5,
+		RepairTime: 1, RepairCost: 3000,
+		Cause: "cable cut", StormCause: "lines down in a storm",
+	},
+	FiberEquipment: {
+		MTBF: 1825, Shape: 1, StormFactor: 1,
+		RepairTime: 2, RepairCost: 10000,
+		Cause: "cable cut", StormCause: "cable cut",
+	},
+	MicrowaveEquipment: {
+		MTBF: 1460, Shape: 1.2, StormFactor: 25,
+		RepairTime: 1, RepairCost: 8000,
+		Cause: "radio failure", StormCause: "dish knocked out of alignment",
+	},
+}
+
+// GetNodeEquipment returns the Equipment of a Node, or "" if it can't fail
+func GetNodeEquipment(node *network.Node) Equipment {
+	return GetNodeTypeEquipment(node.Type)
+}
+
+// GetNodeTypeEquipment returns the Equipment of a NodeType, or "" for Subscribers
+func GetNodeTypeEquipment(t network.NodeType) Equipment {
+	switch t {
+	case network.Exchange:
+		return ExchangeEquipment
+	case network.Tower:
+		return TowerEquipment
+	}
+	return ""
+}
+
+// GetLinkEquipment returns the Equipment of a Link
+func GetLinkEquipment(link *network.Link) Equipment {
+	return GetLinkTypeEquipment(link.Type)
+}
+
+// GetLinkTypeEquipment returns the Equipment of a LinkType
+func GetLinkTypeEquipment(t network.LinkType) Equipment {
+	switch t {
+	case network.Fiber:
+		return FiberEquipment
+	case network.Microwave:
+		return MicrowaveEquipment
+	}
+	return CopperEquipment
+}
+
+// GetFailureRate returns the failures per day of Equipment age days after its
+// last repair, multiplied by scale
+func (r Reliability) GetFailureRate(age, scale float64) float64 {
+	if r.MTBF <= 0 {
+		return 0
+	}
+	shape := r.Shape
+	if shape <= 0 {
+		shape = 1
+	}
+	rate := shape / r.MTBF * math.Pow(math.Max(age, 0)/r.MTBF, shape-1)
+	return rate * scale
+}
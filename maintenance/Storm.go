@@ -0,0 +1,20 @@
+package maintenance
+
+import (
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// Storm is bad weather over part of the map, raising the failure rate of the
+// Equipment inside it by its StormFactor
+type Storm struct {
+	Center mgl32.Vec2
+	// Radius is in kilometers
+	Radius float32
+	// End is the simulated time the Storm passes
+	End float64
+}
+
+// Contains returns whether pos is inside the Storm
+func (s *Storm) Contains(pos mgl32.Vec2) bool {
+	return pos.Sub(s.Center).Len() <= s.Radius
+}
@@ -0,0 +1,51 @@
+package network
+
+import "math"
+
+// Antenna is the radio of a Tower
+type Antenna struct {
+	// Height is the height of the antenna above the ground, in meters
+	Height float32
+	// Azimuth is the direction the antenna faces, in degrees clockwise from north (-Y)
+	Azimuth float32
+	// Beamwidth is the width of the antenna's main lobe in degrees, 360 for an omnidirectional antenna
+	Beamwidth float32
+	// Gain is the gain of the main lobe, in dBi
+	Gain float32
+	// Power is the transmit power, in dBm
+	Power float32
+	// Frequency is the carrier frequency, in MHz
+	Frequency float32
+}
+
+const (
+	// FrontToBack is the most a sector antenna attenuates off its main lobe, in dB
+	FrontToBack = 20
+)
+
+// DefaultAntenna is the Antenna of a new Tower, a 30m omnidirectional mast at 900MHz
+var DefaultAntenna = Antenna{
+	Height:    30,
+	Azimuth:   0,
+	Beamwidth: 360,
+	Gain:      2,
+	Power:     43,
+	Frequency: 900,
+}
+
+// IsOmni returns whether the Antenna radiates the same in every direction
+func (a *Antenna) IsOmni() bool {
+	return a.Beamwidth <= 0 || a.Beamwidth >= 360
+}
+
+// GetGain returns the gain in dBi toward bearing, in degrees clockwise from north,
+// using the parabolic pattern of a sector antenna
+func (a *Antenna) GetGain(bearing float64) float64 {
+	if a.IsOmni() {
+		return float64(a.Gain)
+	}
+
+	off := math.Mod(bearing-float64(a.Azimuth)+540, 360) - 180
+	loss := 12 * math.Pow(off/float64(a.Beamwidth), 2)
+	return float64(a.Gain) - math.Min(loss, FrontToBack)
+}
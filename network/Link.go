@@ -0,0 +1,101 @@
+package network
+
+import (
+	"fmt"
+)
+
+// LinkID identifies a Link within its Network, 0 is never used
+type LinkID int
+
+// LinkType is the medium of a Link
+type LinkType int
+
+const (
+	// Copper is cheap and short range, with little capacity
+	Copper LinkType = iota
+	// Fiber is expensive to lay, with the most capacity
+	Fiber
+	// Microwave is a line-of-sight radio link, cheap over long distances
+	Microwave
+)
+
+// String returns the name of the LinkType
+func (t LinkType) String() string {
+	switch t {
+	case Copper:
+		return "Copper"
+	case Fiber:
+		return "Fiber"
+	case Microwave:
+		return "Microwave"
+	}
+	return fmt.Sprintf("LinkType(%d)", int(t))
+}
+
+// LinkSpec describes the properties of a LinkType
+type LinkSpec struct {
+	// Capacity is the number of simultaneous calls a Link carries
+	Capacity int
+	// LatencyPerKm is in milliseconds
+	LatencyPerKm float32
+	// BaseCost is paid once per Link, CostPerKm for each kilometer of it
+	BaseCost  float32
+	CostPerKm float32
+	// MaxLength is the longest Link that can be built in kilometers, 0 meaning unlimited
+	MaxLength float32
+}
+
+// LinkSpecs holds the LinkSpec of each LinkType, used when a Link is created
+var LinkSpecs = map[LinkType]LinkSpec{
+	Copper: {
+		Capacity:     24,
+		LatencyPerKm: 0.005,
+		BaseCost:     500,
+		CostPerKm:    2000,
+		MaxLength:    5,
+	},
+	Fiber: {
+		Capacity:     2016,
+		LatencyPerKm: 0.005,
+		BaseCost:     5000,
+		CostPerKm:    20000,
+	},
+	Microwave: {
+		Capacity:     672,
+		LatencyPerKm: 0.0034,
+		BaseCost:     50000,
+		CostPerKm:    100,
+		MaxLength:    50,
+	},
+}
+
+// Link connects two Nodes of a Network, carrying calls in both directions
+type Link struct {
+	ID   LinkID
+	Type LinkType
+	A    NodeID
+	B    NodeID
+
+	// Length is in kilometers
+	Length   float32
+	Capacity int
+	// Latency is in milliseconds
+	Latency float32
+	// Cost is what the Link cost to build
+	Cost float32
+	// Down is set while the Link is broken, and carries no calls
+	Down bool `json:",omitempty"`
+}
+
+// Other returns the end of the Link that isn't id
+func (l *Link) Other(id NodeID) NodeID {
+	if l.A == id {
+		return l.B
+	}
+	return l.A
+}
+
+// Connects returns whether id is either end of the Link
+func (l *Link) Connects(id NodeID) bool {
+	return l.A == id || l.B == id
+}
@@ -0,0 +1,473 @@
+package network
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// Network is a graph of Nodes connected by Links. Nodes and Links are always
+// returned in the order they were added, so anything driven by a Network is
+// deterministic.
+type Network struct {
+	nodes map[NodeID]*Node
+	links map[LinkID]*Link
+	// adjacent holds the Links of each Node
+	adjacent map[NodeID][]LinkID
+
+	nodeIDs []NodeID
+	linkIDs []LinkID
+
+	nextNodeID NodeID
+	nextLinkID LinkID
+}
+
+// NewNetwork returns a new empty Network
+func NewNetwork() *Network {
+	return &Network{
+		nodes:      map[NodeID]*Node{},
+		links:      map[LinkID]*Link{},
+		adjacent:   map[NodeID][]LinkID{},
+		nextNodeID: 1,
+		nextLinkID: 1,
+	}
+}
+
+// AddNode adds a Node to the Network, assigning it an ID
+func (n *Network) AddNode(node *Node) *Node {
+	node.ID = n.nextNodeID
+	n.nextNodeID++
+
+	n.nodes[node.ID] = node
+	n.nodeIDs = append(n.nodeIDs, node.ID)
+	return node
+}
+
+// AddExchange adds a new Exchange at pos
+func (n *Network) AddExchange(name string, pos mgl32.Vec2) *Node {
+	return n.AddNode(&Node{
+		Type:     Exchange,
+		Name:     name,
+		Position: pos,
+		Capacity: DefaultExchangeCapacity,
+		Cost:     NodeCosts[Exchange],
+	})
+}
+
+// AddTower adds a new Tower at pos
+func (n *Network) AddTower(name string, pos mgl32.Vec2) *Node {
+	antenna := DefaultAntenna
+	return n.AddNode(&Node{
+		Type:     Tower,
+		Name:     name,
+		Position: pos,
+		Capacity: DefaultTowerCapacity,
+		Range:    DefaultTowerRange,
+		Antenna:  &antenna,
+		Cost:     NodeCosts[Tower],
+	})
+}
+
+// AddSubscriber adds a new Subscriber at pos standing for count lines
+func (n *Network) AddSubscriber(name string, pos mgl32.Vec2, count int) *Node {
+	return n.AddNode(&Node{
+		Type:        Subscriber,
+		Name:        name,
+		Position:    pos,
+		Subscribers: count,
+		Cost:        NodeCosts[Subscriber],
+	})
+}
+
+// RemoveNode removes a Node and every Link connected to it
+func (n *Network) RemoveNode(id NodeID) error {
+	if _, found := n.nodes[id]; !found {
+		return fmt.Errorf("No such node [%v]", id)
+	}
+
+	for _, lid := range append([]LinkID{}, n.adjacent[id]...) {
+		n.RemoveLink(lid)
+	}
+
+	delete(n.nodes, id)
+	delete(n.adjacent, id)
+	for i, nid := range n.nodeIDs {
+		if nid == id {
+			n.nodeIDs = append(n.nodeIDs[:i], n.nodeIDs[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+// Connect adds a new Link of the given type between two Nodes, sized from LinkSpecs
+func (n *Network) Connect(a, b NodeID, t LinkType) (*Link, error) {
+	na, nb := n.nodes[a], n.nodes[b]
+	if na == nil {
+		return nil, fmt.Errorf("No such node [%v]", a)
+	}
+	if nb == nil {
+		return nil, fmt.Errorf("No such node [%v]", b)
+	}
+	if a == b {
+		return nil, fmt.Errorf("Cannot connect node [%v] to itself", a)
+	}
+	if na.Type == Subscriber && nb.Type == Subscriber {
+		return nil, fmt.Errorf("Cannot connect subscribers [%v] and [%v] directly", na.Name, nb.Name)
+	}
+
+	spec, found := LinkSpecs[t]
+	if !found {
+		return nil, fmt.Errorf("Unknown link type [%v]", t)
+	}
+
+	length := na.DistanceTo(nb)
+	if spec.MaxLength > 0 && length > spec.MaxLength {
+		return nil, fmt.Errorf("%v link of %.1fkm is longer than the %.1fkm maximum", t, length, spec.MaxLength)
+	}
+
+	return n.AddLink(&Link{
+		Type:     t,
+		A:        a,
+		B:        b,
+		Length:   length,
+		Capacity: spec.Capacity,
+		Latency:  spec.LatencyPerKm * length,
+		Cost:     spec.BaseCost + spec.CostPerKm*length,
+	})
+}
+
+// AddLink adds a Link between two existing Nodes, assigning it an ID
+func (n *Network) AddLink(link *Link) (*Link, error) {
+	if n.nodes[link.A] == nil {
+		return nil, fmt.Errorf("No such node [%v]", link.A)
+	}
+	if n.nodes[link.B] == nil {
+		return nil, fmt.Errorf("No such node [%v]", link.B)
+	}
+
+	link.ID = n.nextLinkID
+	n.nextLinkID++
+
+	n.links[link.ID] = link
+	n.linkIDs = append(n.linkIDs, link.ID)
+	n.adjacent[link.A] = append(n.adjacent[link.A], link.ID)
+	n.adjacent[link.B] = append(n.adjacent[link.B], link.ID)
+	return link, nil
+}
+
+// RemoveLink removes a Link
+func (n *Network) RemoveLink(id LinkID) error {
+	link, found := n.links[id]
+	if !found {
+		return fmt.Errorf("No such link [%v]", id)
+	}
+
+	delete(n.links, id)
+	n.adjacent[link.A] = removeLinkID(n.adjacent[link.A], id)
+	n.adjacent[link.B] = removeLinkID(n.adjacent[link.B], id)
+	n.linkIDs = removeLinkID(n.linkIDs, id)
+	return nil
+}
+
+func removeLinkID(ids []LinkID, id LinkID) []LinkID {
+	for i, lid := range ids {
+		if lid == id {
+			return append(ids[:i], ids[i+1:]...)
+		}
+	}
+	return ids
+}
+
+// GetNode returns the Node with the given ID, or nil
+func (n *Network) GetNode(id NodeID) *Node {
+	return n.nodes[id]
+}
+
+// GetLink returns the Link with the given ID, or nil
+func (n *Network) GetLink(id LinkID) *Link {
+	return n.links[id]
+}
+
+// FindNode returns the first Node with the given name, or nil
+func (n *Network) FindNode(name string) *Node {
+	for _, id := range n.nodeIDs {
+		if n.nodes[id].Name == name {
+			return n.nodes[id]
+		}
+	}
+	return nil
+}
+
+// GetNodes returns every Node
+func (n *Network) GetNodes() []*Node {
+	nodes := make([]*Node, 0, len(n.nodeIDs))
+	for _, id := range n.nodeIDs {
+		nodes = append(nodes, n.nodes[id])
+	}
+	return nodes
+}
+
+// GetNodesOfType returns every Node of the given type
+func (n *Network) GetNodesOfType(t NodeType) []*Node {
+	nodes := []*Node{}
+	for _, id := range n.nodeIDs {
+		if n.nodes[id].Type == t {
+			nodes = append(nodes, n.nodes[id])
+		}
+	}
+	return nodes
+}
+
+// GetLinks returns every Link
+func (n *Network) GetLinks() []*Link {
+	links := make([]*Link, 0, len(n.linkIDs))
+	for _, id := range n.linkIDs {
+		links = append(links, n.links[id])
+	}
+	return links
+}
+
+// GetLinksOf returns the Links connected to a Node
+func (n *Network) GetLinksOf(id NodeID) []*Link {
+	links := make([]*Link, 0, len(n.adjacent[id]))
+	for _, lid := range n.adjacent[id] {
+		links = append(links, n.links[lid])
+	}
+	return links
+}
+
+// GetLinksBetween returns the Links directly connecting two Nodes
+func (n *Network) GetLinksBetween(a, b NodeID) []*Link {
+	links := []*Link{}
+	for _, lid := range n.adjacent[a] {
+		if n.links[lid].Other(a) == b {
+			links = append(links, n.links[lid])
+		}
+	}
+	return links
+}
+
+// GetNeighbors returns the Nodes directly connected to a Node, once each
+func (n *Network) GetNeighbors(id NodeID) []*Node {
+	seen := map[NodeID]bool{}
+	nodes := []*Node{}
+	for _, lid := range n.adjacent[id] {
+		other := n.links[lid].Other(id)
+		if !seen[other] {
+			seen[other] = true
+			nodes = append(nodes, n.nodes[other])
+		}
+	}
+	return nodes
+}
+
+// GetNearest returns the Node of the given type closest to pos, or nil if there are none
+func (n *Network) GetNearest(pos mgl32.Vec2, t NodeType) *Node {
+	var nearest *Node
+	best := float32(0)
+	for _, id := range n.nodeIDs {
+		node := n.nodes[id]
+		if node.Type != t {
+			continue
+		}
+		d := node.Position.Sub(pos).Len()
+		if nearest == nil || d < best {
+			nearest = node
+			best = d
+		}
+	}
+	return nearest
+}
+
+// Walk visits every Node reachable from start breadth first, stopping early if fn returns false
+func (n *Network) Walk(start NodeID, fn func(node *Node, depth int) bool) {
+	if n.nodes[start] == nil {
+		return
+	}
+
+	visited := map[NodeID]bool{start: true}
+	queue := []NodeID{start}
+	depths := map[NodeID]int{start: 0}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+
+		if !fn(n.nodes[id], depths[id]) {
+			return
+		}
+
+		for _, lid := range n.adjacent[id] {
+			other := n.links[lid].Other(id)
+			if !visited[other] {
+				visited[other] = true
+				depths[other] = depths[id] + 1
+				queue = append(queue, other)
+			}
+		}
+	}
+}
+
+// IsReachable returns whether there is any path between two Nodes
+func (n *Network) IsReachable(a, b NodeID) bool {
+	found := false
+	n.Walk(a, func(node *Node, depth int) bool {
+		found = (node.ID == b)
+		return !found
+	})
+	return found
+}
+
+// GetPath returns the Links of a path with the fewest hops between two Nodes, or nil if there is none
+func (n *Network) GetPath(a, b NodeID) []*Link {
+	if n.nodes[a] == nil || n.nodes[b] == nil {
+		return nil
+	}
+	if a == b {
+		return []*Link{}
+	}
+
+	via := map[NodeID]LinkID{}
+	visited := map[NodeID]bool{a: true}
+	queue := []NodeID{a}
+	for len(queue) > 0 && !visited[b] {
+		id := queue[0]
+		queue = queue[1:]
+
+		for _, lid := range n.adjacent[id] {
+			other := n.links[lid].Other(id)
+			if !visited[other] {
+				visited[other] = true
+				via[other] = lid
+				queue = append(queue, other)
+			}
+		}
+	}
+
+	if !visited[b] {
+		return nil
+	}
+
+	path := []*Link{}
+	for id := b; id != a; {
+		link := n.links[via[id]]
+		path = append(path, link)
+		id = link.Other(id)
+	}
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return path
+}
+
+// GetComponents returns the groups of Nodes connected to each other, each sorted by ID
+func (n *Network) GetComponents() [][]*Node {
+	assigned := map[NodeID]bool{}
+	components := [][]*Node{}
+	for _, id := range n.nodeIDs {
+		if assigned[id] {
+			continue
+		}
+
+		component := []*Node{}
+		n.Walk(id, func(node *Node, depth int) bool {
+			assigned[node.ID] = true
+			component = append(component, node)
+			return true
+		})
+		sort.Slice(component, func(i, j int) bool {
+			return component[i].ID < component[j].ID
+		})
+		components = append(components, component)
+	}
+	return components
+}
+
+// GetTotalCost returns the cost of every Node and Link in the Network
+func (n *Network) GetTotalCost() float32 {
+	total := float32(0)
+	for _, id := range n.nodeIDs {
+		total += n.nodes[id].Cost
+	}
+	for _, id := range n.linkIDs {
+		total += n.links[id].Cost
+	}
+	return total
+}
+
+// networkJSON is the saved form of a Network
+type networkJSON struct {
+	Nodes      []*Node
+	Links      []*Link
+	NextNodeID NodeID
+	NextLinkID LinkID
+}
+
+// MarshalJSON writes the Nodes and Links in the order they were added
+func (n *Network) MarshalJSON() ([]byte, error) {
+	return json.Marshal(networkJSON{
+		Nodes:      n.GetNodes(),
+		Links:      n.GetLinks(),
+		NextNodeID: n.nextNodeID,
+		NextLinkID: n.nextLinkID,
+	})
+}
+
+// UnmarshalJSON replaces the Network with one written by MarshalJSON, keeping every ID.
+// The next IDs are kept past the highest loaded, so an edited save can't reuse one.
+func (n *Network) UnmarshalJSON(data []byte) error {
+	var nj networkJSON
+	err := json.Unmarshal(data, &nj)
+	if err != nil {
+		return err
+	}
+
+	loaded := NewNetwork()
+	if nj.NextNodeID < 1 {
+		nj.NextNodeID = 1
+	}
+	if nj.NextLinkID < 1 {
+		nj.NextLinkID = 1
+	}
+	for i, node := range nj.Nodes {
+		if node == nil {
+			return fmt.Errorf("Missing node at [%v]", i)
+		}
+		if node.ID <= 0 || loaded.nodes[node.ID] != nil {
+			return fmt.Errorf("Invalid node ID [%v]", node.ID)
+		}
+		loaded.nodes[node.ID] = node
+		loaded.nodeIDs = append(loaded.nodeIDs, node.ID)
+		if node.ID >= nj.NextNodeID {
+			nj.NextNodeID = node.ID + 1
+		}
+	}
+	for i, link := range nj.Links {
+		if link == nil {
+			return fmt.Errorf("Missing link at [%v]", i)
+		}
+		if link.ID <= 0 || loaded.links[link.ID] != nil {
+			return fmt.Errorf("Invalid link ID [%v]", link.ID)
+		}
+		if loaded.nodes[link.A] == nil {
+			return fmt.Errorf("No such node [%v]", link.A)
+		}
+		if loaded.nodes[link.B] == nil {
+			return fmt.Errorf("No such node [%v]", link.B)
+		}
+		loaded.links[link.ID] = link
+		loaded.linkIDs = append(loaded.linkIDs, link.ID)
+		loaded.adjacent[link.A] = append(loaded.adjacent[link.A], link.ID)
+		loaded.adjacent[link.B] = append(loaded.adjacent[link.B], link.ID)
+		if link.ID >= nj.NextLinkID {
+			nj.NextLinkID = link.ID + 1
+		}
+	}
+	loaded.nextNodeID = nj.NextNodeID
+	loaded.nextLinkID = nj.NextLinkID
+
+	*n = *loaded
+	return nil
+}
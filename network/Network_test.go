@@ -0,0 +1,306 @@
+package network
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// newTestNetwork returns a Network of two Exchanges 10km apart, a Tower 3km
+// from the first and a Subscriber 1km from the first
+func newTestNetwork() (net *Network, a, b, tower, sub *Node) {
+	net = NewNetwork()
+	a = net.AddExchange("A", mgl32.Vec2{0, 0})
+	b = net.AddExchange("B", mgl32.Vec2{10, 0})
+	tower = net.AddTower("T", mgl32.Vec2{0, 3})
+	sub = net.AddSubscriber("S", mgl32.Vec2{1, 0}, 100)
+	return
+}
+
+func TestConnect(t *testing.T) {
+	tests := []struct {
+		name string
+		// a and b pick the Nodes connected from those of newTestNetwork
+		a, b     string
+		linkType LinkType
+		wantErr  bool
+		capacity int
+		length   float32
+	}{
+		{name: "fiber between exchanges", a: "A", b: "B", linkType: Fiber, capacity: 2016, length: 10},
+		{name: "microwave between exchanges", a: "A", b: "B", linkType: Microwave, capacity: 672, length: 10},
+		{name: "copper to a subscriber", a: "S", b: "A", linkType: Copper, capacity: 24, length: 1},
+		{name: "copper to a tower", a: "T", b: "A", linkType: Copper, capacity: 24, length: 3},
+		{name: "copper longer than its maximum", a: "A", b: "B", linkType: Copper, wantErr: true},
+		{name: "a node to itself", a: "A", b: "A", linkType: Fiber, wantErr: true},
+		{name: "a missing node", a: "A", b: "missing", linkType: Fiber, wantErr: true},
+		{name: "an unknown link type", a: "A", b: "B", linkType: LinkType(99), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			net, _, _, _, _ := newTestNetwork()
+			a, b := NodeID(0), NodeID(0)
+			if n := net.FindNode(tt.a); n != nil {
+				a = n.ID
+			}
+			if n := net.FindNode(tt.b); n != nil {
+				b = n.ID
+			}
+
+			link, err := net.Connect(a, b, tt.linkType)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Connect(%v, %v, %v) succeeded, want an error", tt.a, tt.b, tt.linkType)
+				}
+				if len(net.GetLinks()) != 0 {
+					t.Errorf("failed Connect left %d links", len(net.GetLinks()))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Connect(%v, %v, %v) failed [%v]", tt.a, tt.b, tt.linkType, err)
+			}
+
+			if link.Capacity != tt.capacity {
+				t.Errorf("Capacity = %d, want %d", link.Capacity, tt.capacity)
+			}
+			if link.Length != tt.length {
+				t.Errorf("Length = %v, want %v", link.Length, tt.length)
+			}
+			if !net.IsReachable(a, b) {
+				t.Errorf("%v and %v aren't reachable once connected", tt.a, tt.b)
+			}
+			if got := net.GetLinksBetween(b, a); len(got) != 1 || got[0] != link {
+				t.Errorf("GetLinksBetween returned %v, want the new link", got)
+			}
+		})
+	}
+}
+
+func TestConnectSubscribers(t *testing.T) {
+	net, _, _, _, sub := newTestNetwork()
+	other := net.AddSubscriber("S2", mgl32.Vec2{1, 1}, 10)
+	if _, err := net.Connect(sub.ID, other.ID, Copper); err == nil {
+		t.Errorf("connecting two subscribers succeeded, want an error")
+	}
+}
+
+func TestNodeCapacity(t *testing.T) {
+	net, a, _, tower, sub := newTestNetwork()
+
+	tests := []struct {
+		node     *Node
+		capacity int
+		cost     float32
+	}{
+		{a, DefaultExchangeCapacity, NodeCosts[Exchange]},
+		{tower, DefaultTowerCapacity, NodeCosts[Tower]},
+		{sub, 0, NodeCosts[Subscriber]},
+	}
+	for _, tt := range tests {
+		if tt.node.Capacity != tt.capacity {
+			t.Errorf("%v Capacity = %d, want %d", tt.node.Name, tt.node.Capacity, tt.capacity)
+		}
+		if tt.node.Cost != tt.cost {
+			t.Errorf("%v Cost = %v, want %v", tt.node.Name, tt.node.Cost, tt.cost)
+		}
+	}
+	if sub.Subscribers != 100 {
+		t.Errorf("Subscribers = %d, want 100", sub.Subscribers)
+	}
+
+	l, _ := net.Connect(a.ID, tower.ID, Fiber)
+	want := 2*NodeCosts[Exchange] + NodeCosts[Tower] + NodeCosts[Subscriber] + l.Cost
+	if got := net.GetTotalCost(); got != want {
+		t.Errorf("GetTotalCost() = %v, want %v", got, want)
+	}
+}
+
+func TestRemoveLink(t *testing.T) {
+	net, a, b, tower, _ := newTestNetwork()
+	ab, _ := net.Connect(a.ID, b.ID, Fiber)
+	at, _ := net.Connect(a.ID, tower.ID, Fiber)
+	tb, _ := net.Connect(tower.ID, b.ID, Fiber)
+
+	tests := []struct {
+		name      string
+		link      LinkID
+		wantErr   bool
+		links     int
+		reachable bool
+		hops      int
+	}{
+		{name: "the direct link", link: ab.ID, links: 2, reachable: true, hops: 2},
+		{name: "the same link again", link: ab.ID, wantErr: true, links: 2, reachable: true, hops: 2},
+		{name: "the detour", link: tb.ID, links: 1, reachable: false},
+		{name: "a missing link", link: LinkID(99), wantErr: true, links: 1, reachable: false},
+	}
+
+	for _, tt := range tests {
+		err := net.RemoveLink(tt.link)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%v: RemoveLink error [%v], want error %v", tt.name, err, tt.wantErr)
+		}
+		if got := len(net.GetLinks()); got != tt.links {
+			t.Errorf("%v: %d links left, want %d", tt.name, got, tt.links)
+		}
+		if got := net.IsReachable(a.ID, b.ID); got != tt.reachable {
+			t.Errorf("%v: IsReachable = %v, want %v", tt.name, got, tt.reachable)
+		}
+		if got := len(net.GetPath(a.ID, b.ID)); got != tt.hops {
+			t.Errorf("%v: path of %d hops, want %d", tt.name, got, tt.hops)
+		}
+	}
+
+	if got := net.GetLinksOf(a.ID); len(got) != 1 || got[0] != at {
+		t.Errorf("GetLinksOf(A) = %v, want only the link to the tower", got)
+	}
+}
+
+func TestRemoveNode(t *testing.T) {
+	net, a, b, tower, sub := newTestNetwork()
+	net.Connect(a.ID, b.ID, Fiber)
+	net.Connect(a.ID, tower.ID, Fiber)
+	net.Connect(sub.ID, a.ID, Copper)
+	net.Connect(tower.ID, b.ID, Fiber)
+
+	err := net.RemoveNode(a.ID)
+	if err != nil {
+		t.Fatalf("RemoveNode failed [%v]", err)
+	}
+	if net.GetNode(a.ID) != nil || net.FindNode("A") != nil {
+		t.Errorf("removed node is still found")
+	}
+	if got := len(net.GetLinks()); got != 1 {
+		t.Errorf("%d links left, want only tower to B", got)
+	}
+	if got := len(net.GetLinksOf(sub.ID)); got != 0 {
+		t.Errorf("subscriber kept %d links to the removed node", got)
+	}
+	if !net.IsReachable(tower.ID, b.ID) || net.IsReachable(sub.ID, b.ID) {
+		t.Errorf("reachability is wrong after removing a node")
+	}
+
+	nodes := net.GetNodes()
+	want := []*Node{b, tower, sub}
+	if len(nodes) != len(want) {
+		t.Fatalf("%d nodes left, want %d", len(nodes), len(want))
+	}
+	for i := range want {
+		if nodes[i] != want[i] {
+			t.Errorf("node %d is %v, want %v in the order added", i, nodes[i].Name, want[i].Name)
+		}
+	}
+
+	if err := net.RemoveNode(a.ID); err == nil {
+		t.Errorf("removing a node twice succeeded, want an error")
+	}
+	if n := net.AddExchange("C", mgl32.Vec2{}); n.ID == a.ID {
+		t.Errorf("the ID of a removed node was reused")
+	}
+}
+
+func TestNetworkJSON(t *testing.T) {
+	net, a, b, tower, _ := newTestNetwork()
+	net.Connect(a.ID, b.ID, Fiber)
+	at, _ := net.Connect(a.ID, tower.ID, Fiber)
+	net.RemoveLink(at.ID)
+
+	data, err := json.Marshal(net)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var loaded Network
+	err = json.Unmarshal(data, &loaded)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := len(loaded.GetNodes()); got != 4 {
+		t.Errorf("loaded %d nodes, want 4", got)
+	}
+	if !loaded.IsReachable(a.ID, b.ID) || loaded.IsReachable(a.ID, tower.ID) {
+		t.Errorf("reachability is wrong after loading")
+	}
+	// The ID of the removed link isn't reused
+	if l, _ := loaded.Connect(a.ID, tower.ID, Fiber); l.ID <= at.ID {
+		t.Errorf("new link has ID %v, want one after %v", l.ID, at.ID)
+	}
+}
+
+func TestNetworkJSONInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		json string
+	}{
+		{name: "null node", json: `{"Nodes":[{"ID":1},null],"Links":[]}`},
+		{name: "null link", json: `{"Nodes":[{"ID":1},{"ID":2}],"Links":[null]}`},
+		{name: "node without an ID", json: `{"Nodes":[{"Name":"A"}],"Links":[]}`},
+		{name: "duplicate node", json: `{"Nodes":[{"ID":1},{"ID":1}],"Links":[]}`},
+		{name: "duplicate link", json: `{"Nodes":[{"ID":1},{"ID":2}],"Links":[{"ID":1,"A":1,"B":2},{"ID":1,"A":2,"B":1}]}`},
+		{name: "link to a missing node", json: `{"Nodes":[{"ID":1}],"Links":[{"ID":1,"A":1,"B":3}]}`},
+	}
+
+	for _, tt := range tests {
+		net, _, _, _, _ := newTestNetwork()
+		if err := json.Unmarshal([]byte(tt.json), net); err == nil {
+			t.Errorf("%v: loaded without an error", tt.name)
+		}
+		if got := len(net.GetNodes()); got != 4 {
+			t.Errorf("%v: a failed load left %d nodes, want the 4 from before", tt.name, got)
+		}
+	}
+}
+
+func TestNetworkJSONNextIDs(t *testing.T) {
+	tests := []struct {
+		name     string
+		json     string
+		nextNode NodeID
+		nextLink LinkID
+	}{
+		{
+			name:     "missing",
+			json:     `{"Nodes":[{"ID":3},{"ID":7}],"Links":[{"ID":5,"A":3,"B":7}]}`,
+			nextNode: 8,
+			nextLink: 6,
+		},
+		{
+			name:     "behind the loaded IDs",
+			json:     `{"Nodes":[{"ID":3},{"ID":7}],"Links":[{"ID":5,"A":3,"B":7}],"NextNodeID":2,"NextLinkID":5}`,
+			nextNode: 8,
+			nextLink: 6,
+		},
+		{
+			name:     "past the loaded IDs",
+			json:     `{"Nodes":[{"ID":3}],"Links":[],"NextNodeID":10,"NextLinkID":4}`,
+			nextNode: 10,
+			nextLink: 4,
+		},
+		{
+			name:     "empty",
+			json:     `{"Nodes":[],"Links":[]}`,
+			nextNode: 1,
+			nextLink: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		var net Network
+		if err := json.Unmarshal([]byte(tt.json), &net); err != nil {
+			t.Errorf("%v: load failed [%v]", tt.name, err)
+			continue
+		}
+		if got := net.AddExchange("New", mgl32.Vec2{}).ID; got != tt.nextNode {
+			t.Errorf("%v: new node has ID %v, want %v", tt.name, got, tt.nextNode)
+		}
+		if got := net.AddTower("New", mgl32.Vec2{}).ID; got != tt.nextNode+1 {
+			t.Errorf("%v: second node has ID %v, want %v", tt.name, got, tt.nextNode+1)
+		}
+		if l, err := net.Connect(tt.nextNode, tt.nextNode+1, Copper); err != nil || l.ID != tt.nextLink {
+			t.Errorf("%v: new link is %v [%v], want ID %v", tt.name, l, err, tt.nextLink)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package network
+
+import (
+	"fmt"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// NodeID identifies a Node within its Network, 0 is never used
+type NodeID int
+
+// NodeType is the kind of equipment a Node represents
+type NodeType int
+
+const (
+	// Exchange is a central office switching calls between links
+	Exchange NodeType = iota
+	// Tower is a cell tower serving subscribers within its range
+	Tower
+	// Subscriber is an endpoint standing for a group of subscriber lines
+	Subscriber
+)
+
+// String returns the name of the NodeType
+func (t NodeType) String() string {
+	switch t {
+	case Exchange:
+		return "Exchange"
+	case Tower:
+		return "Tower"
+	case Subscriber:
+		return "Subscriber"
+	}
+	return fmt.Sprintf("NodeType(%d)", int(t))
+}
+
+// Node is a piece of equipment or group of subscribers in a Network
+type Node struct {
+	ID   NodeID
+	Type NodeType
+	Name string
+	// Position is on the ground plane, in kilometers
+	Position mgl32.Vec2
+
+	// Capacity is the number of simultaneous calls an Exchange can switch, or
+	// the number of channels a Tower has
+	Capacity int
+	// Range is the radius in kilometers a Tower serves
+	Range float32
+	// Antenna is the radio of a Tower
+	Antenna *Antenna `json:",omitempty"`
+	// Subscribers is the number of lines a Subscriber stands for
+	Subscribers int
+	// Demand is the number of lines the people around a Subscriber would take,
+	// which Subscribers grows toward while it is connected
+	Demand int `json:",omitempty"`
+
+	// Cost is what the Node cost to build
+	Cost float32
+	// Down is set while the Node is broken, and switches no calls
+	Down bool `json:",omitempty"`
+}
+
+const (
+	// DefaultExchangeCapacity is the Capacity of a new Exchange
+	DefaultExchangeCapacity = 10000
+	// DefaultTowerCapacity is the Capacity of a new Tower
+	DefaultTowerCapacity = 60
+	// DefaultTowerRange is the Range of a new Tower
+	DefaultTowerRange float32 = 5
+)
+
+// NodeCosts holds what each NodeType costs to build, used when a Node is added
+var NodeCosts = map[NodeType]float32{
+	Exchange:   250000,
+	Tower:      80000,
+	Subscriber: 0,
+}
+
+// DistanceTo returns the distance in kilometers between two Nodes
+func (n *Node) DistanceTo(o *Node) float32 {
+	return n.Position.Sub(o.Position).Len()
+}
@@ -0,0 +1,74 @@
+package obj
+
+import (
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// objectBuilder accumulates the triangulated faces of an Object while the file is read
+type objectBuilder struct {
+	*Object
+	corners []faceVertex
+	// groups holds the smoothing group of each triangle, 0 meaning off
+	groups []int
+}
+
+func newObjectBuilder(name string) *objectBuilder {
+	return &objectBuilder{
+		Object: &Object{
+			Name:      name,
+			Vertices:  []mgl32.Vec3{},
+			Normals:   []mgl32.Vec3{},
+			TexCoords: []mgl32.Vec2{},
+			Indices:   []uint32{},
+		},
+	}
+}
+
+// build fills the Object with one vertex per unique (v, vt, vn) tuple and the
+// index list of its triangles
+func (b *objectBuilder) build(verts []mgl32.Vec3, txcds []mgl32.Vec2, norms []mgl32.Vec3, mode NormalMode) *Object {
+	o := b.Object
+
+	if mode != NormalsNone {
+		norms = b.generateNormals(verts, norms, mode)
+	}
+
+	hasTxcds := false
+	hasNorms := false
+	for _, c := range b.corners {
+		hasTxcds = hasTxcds || c.vt >= 0
+		hasNorms = hasNorms || c.vn >= 0
+	}
+
+	index := make(map[faceVertex]uint32, len(b.corners))
+	for _, c := range b.corners {
+		i, found := index[c]
+		if !found {
+			i = uint32(len(o.Vertices))
+			index[c] = i
+
+			o.Vertices = append(o.Vertices, verts[c.v])
+
+			// Corners missing an attribute the rest of the object has get a zero value,
+			// so the arrays stay the same length
+			if hasTxcds {
+				if c.vt >= 0 {
+					o.TexCoords = append(o.TexCoords, txcds[c.vt])
+				} else {
+					o.TexCoords = append(o.TexCoords, mgl32.Vec2{})
+				}
+			}
+
+			if hasNorms {
+				if c.vn >= 0 {
+					o.Normals = append(o.Normals, norms[c.vn])
+				} else {
+					o.Normals = append(o.Normals, mgl32.Vec3{})
+				}
+			}
+		}
+		o.Indices = append(o.Indices, i)
+	}
+
+	return o
+}
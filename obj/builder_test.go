@@ -0,0 +1,96 @@
+package obj
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+func TestBuild(t *testing.T) {
+	verts := []mgl32.Vec3{{0, 0, 0}, {1, 0, 0}, {1, 0, 1}, {0, 0, 1}}
+	txcds := []mgl32.Vec2{{0, 0}, {1, 0}, {1, 1}, {0, 1}}
+	norms := []mgl32.Vec3{{0, 1, 0}, {0, -1, 0}}
+
+	tests := []struct {
+		name    string
+		corners []faceVertex
+		want    *Object
+	}{
+		{
+			// Two triangles of a quad share the edge from 0 to 2, whose corners are only stored once
+			name: "shared edge",
+			corners: []faceVertex{
+				{0, 0, 0}, {1, 1, 0}, {2, 2, 0},
+				{0, 0, 0}, {2, 2, 0}, {3, 3, 0},
+			},
+			want: &Object{
+				Vertices:  []mgl32.Vec3{verts[0], verts[1], verts[2], verts[3]},
+				TexCoords: []mgl32.Vec2{txcds[0], txcds[1], txcds[2], txcds[3]},
+				Normals:   []mgl32.Vec3{norms[0], norms[0], norms[0], norms[0]},
+				Indices:   []uint32{0, 1, 2, 0, 2, 3},
+			},
+		},
+		{
+			// The same position with another normal is another vertex
+			name: "split by normal",
+			corners: []faceVertex{
+				{0, 0, 0}, {1, 1, 0}, {2, 2, 0},
+				{0, 0, 1}, {2, 2, 1}, {3, 3, 1},
+			},
+			want: &Object{
+				Vertices:  []mgl32.Vec3{verts[0], verts[1], verts[2], verts[0], verts[2], verts[3]},
+				TexCoords: []mgl32.Vec2{txcds[0], txcds[1], txcds[2], txcds[0], txcds[2], txcds[3]},
+				Normals:   []mgl32.Vec3{norms[0], norms[0], norms[0], norms[1], norms[1], norms[1]},
+				Indices:   []uint32{0, 1, 2, 3, 4, 5},
+			},
+		},
+		{
+			// Corners without an attribute others have get a zero value
+			name: "missing attributes",
+			corners: []faceVertex{
+				{0, -1, -1}, {1, 1, -1}, {2, -1, 0},
+				{0, -1, -1}, {2, -1, 0}, {3, -1, -1},
+			},
+			want: &Object{
+				Vertices:  []mgl32.Vec3{verts[0], verts[1], verts[2], verts[3]},
+				TexCoords: []mgl32.Vec2{{}, txcds[1], {}, {}},
+				Normals:   []mgl32.Vec3{{}, {}, norms[0], {}},
+				Indices:   []uint32{0, 1, 2, 0, 2, 3},
+			},
+		},
+		{
+			name: "positions only",
+			corners: []faceVertex{
+				{0, -1, -1}, {1, -1, -1}, {2, -1, -1},
+				{0, -1, -1}, {2, -1, -1}, {3, -1, -1},
+			},
+			want: &Object{
+				Vertices:  []mgl32.Vec3{verts[0], verts[1], verts[2], verts[3]},
+				TexCoords: []mgl32.Vec2{},
+				Normals:   []mgl32.Vec3{},
+				Indices:   []uint32{0, 1, 2, 0, 2, 3},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		b := newObjectBuilder("quad")
+		b.corners = tt.corners
+		b.groups = []int{0, 0}
+		o := b.build(verts, txcds, norms, NormalsNone)
+
+		if !reflect.DeepEqual(o.Vertices, tt.want.Vertices) {
+			t.Errorf("%v: Vertices = %v, want %v", tt.name, o.Vertices, tt.want.Vertices)
+		}
+		if !reflect.DeepEqual(o.TexCoords, tt.want.TexCoords) {
+			t.Errorf("%v: TexCoords = %v, want %v", tt.name, o.TexCoords, tt.want.TexCoords)
+		}
+		if !reflect.DeepEqual(o.Normals, tt.want.Normals) {
+			t.Errorf("%v: Normals = %v, want %v", tt.name, o.Normals, tt.want.Normals)
+		}
+		if !reflect.DeepEqual(o.Indices, tt.want.Indices) {
+			t.Errorf("%v: Indices = %v, want %v", tt.name, o.Indices, tt.want.Indices)
+		}
+	}
+}
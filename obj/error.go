@@ -0,0 +1,85 @@
+package obj
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// ParseError describes a malformed line in an OBJ or MTL file
+type ParseError struct {
+	File   string
+	Line   int
+	Column int
+	Msg    string
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("%v:%d:%d: %v", e.File, e.Line, e.Column, e.Msg)
+}
+
+// token is a whitespace separated word of a line and its one-based column
+type token struct {
+	text string
+	col  int
+}
+
+func tokenize(line string) []token {
+	tokens := []token{}
+	start := -1
+	for i := 0; i <= len(line); i++ {
+		if i == len(line) || line[i] == ' ' || line[i] == '\t' || line[i] == '\r' || line[i] == '\n' {
+			if start >= 0 {
+				tokens = append(tokens, token{text: line[start:i], col: start + 1})
+				start = -1
+			}
+		} else if start < 0 {
+			start = i
+		}
+	}
+	return tokens
+}
+
+// rest returns the remainder of the line starting at tok, used for filenames containing spaces
+func rest(line string, tok token) string {
+	end := len(line)
+	for end > 0 && (line[end-1] == ' ' || line[end-1] == '\t' || line[end-1] == '\r' || line[end-1] == '\n') {
+		end--
+	}
+	return line[tok.col-1 : end]
+}
+
+// parseFloats parses between min and max floats following the directive in tokens[0]
+func parseFloats(tokens []token, min, max int) ([]float32, *ParseError) {
+	args := tokens[1:]
+	if len(args) < min || len(args) > max {
+		col := tokens[0].col
+		if len(args) > max {
+			col = args[max].col
+		}
+		if min == max {
+			return nil, &ParseError{Column: col, Msg: fmt.Sprintf("[%v] expects %d values, got %d", tokens[0].text, min, len(args))}
+		}
+		return nil, &ParseError{Column: col, Msg: fmt.Sprintf("[%v] expects %d to %d values, got %d", tokens[0].text, min, max, len(args))}
+	}
+
+	values := make([]float32, len(args))
+	for i, arg := range args {
+		f, err := strconv.ParseFloat(arg.text, 32)
+		if err != nil {
+			return nil, &ParseError{Column: arg.col, Msg: fmt.Sprintf("Invalid number [%v]", arg.text)}
+		}
+		values[i] = float32(f)
+	}
+	return values, nil
+}
+
+// report records a ParseError, returning it when the reader is strict or nil when it is lenient
+func (rdr *reader) report(file string, line int, perr *ParseError) error {
+	perr.File = file
+	perr.Line = line
+	if rdr.lenient {
+		rdr.warnings = append(rdr.warnings, perr)
+		return nil
+	}
+	return perr
+}
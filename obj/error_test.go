@@ -0,0 +1,152 @@
+package obj
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestParseError(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  ParseError
+	}{
+		{
+			name:  "vertex with a bad number",
+			files: map[string]string{"test.obj": "# cube\nv 0 0 0\nv 1 x 0\n"},
+			want:  ParseError{File: "test.obj", Line: 3, Column: 5},
+		},
+		{
+			name:  "vertex with too few values",
+			files: map[string]string{"test.obj": "v 0 0\n"},
+			want:  ParseError{File: "test.obj", Line: 1, Column: 1},
+		},
+		{
+			name:  "normal with too many values",
+			files: map[string]string{"test.obj": "vn 0 0 1 1\n"},
+			want:  ParseError{File: "test.obj", Line: 1, Column: 10},
+		},
+		{
+			name:  "face out of range",
+			files: map[string]string{"test.obj": "v 0 0 0\nv 1 0 0\nv 0 1 0\n\nf 1 2  4\n"},
+			want:  ParseError{File: "test.obj", Line: 5, Column: 8},
+		},
+		{
+			name:  "bad smoothing group",
+			files: map[string]string{"test.obj": "s on\n"},
+			want:  ParseError{File: "test.obj", Line: 1, Column: 3},
+		},
+		{
+			name:  "unknown material",
+			files: map[string]string{"test.obj": "usemtl  missing\n"},
+			want:  ParseError{File: "test.obj", Line: 1, Column: 9},
+		},
+		{
+			name:  "missing material library",
+			files: map[string]string{"test.obj": "mtllib test.mtl\n"},
+			want:  ParseError{File: "test.obj", Line: 1, Column: 8},
+		},
+		{
+			name: "material color with a bad number",
+			files: map[string]string{
+				"test.obj": "mtllib test.mtl\n",
+				"test.mtl": "newmtl a\nKd 1 0.5 half\n",
+			},
+			want: ParseError{File: "test.mtl", Line: 2, Column: 10},
+		},
+		{
+			name: "material property before newmtl",
+			files: map[string]string{
+				"test.obj": "mtllib test.mtl\n",
+				"test.mtl": "\n  Ns 10\n",
+			},
+			want: ParseError{File: "test.mtl", Line: 2, Column: 3},
+		},
+		{
+			name: "texture option with a bad value",
+			files: map[string]string{
+				"test.obj": "mtllib test.mtl\n",
+				"test.mtl": "newmtl a\nmap_Kd -clamp maybe a.png\n",
+			},
+			want: ParseError{File: "test.mtl", Line: 2, Column: 15},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := readFiles(tt.files)
+			if err == nil {
+				t.Fatalf("read without an error, want one at %v:%d:%d", tt.want.File, tt.want.Line, tt.want.Column)
+			}
+			perr, ok := err.(*ParseError)
+			if !ok {
+				t.Fatalf("got %T %v, want a *ParseError", err, err)
+			}
+			if perr.File != tt.want.File || perr.Line != tt.want.Line || perr.Column != tt.want.Column {
+				t.Errorf("error at %v:%d:%d, want %v:%d:%d: %v",
+					perr.File, perr.Line, perr.Column, tt.want.File, tt.want.Line, tt.want.Column, perr.Msg)
+			}
+			if prefix := fmt.Sprintf("%v:%d:%d: ", perr.File, perr.Line, perr.Column); !strings.HasPrefix(perr.Error(), prefix) {
+				t.Errorf("Error() = %q, want it to start with %q", perr.Error(), prefix)
+			}
+		})
+	}
+}
+
+func TestLenient(t *testing.T) {
+	files := map[string]string{
+		"test.obj": "mtllib test.mtl\n" +
+			"v 0 0 0\n" +
+			"v 1 x 0\n" +
+			"v 1 1 0\n" +
+			"v 0 1 0\n" +
+			"f 1 2 9\n" +
+			"usemtl red\n" +
+			"f 1 2 3 4\n",
+		"test.mtl": "newmtl red\n" +
+			"Kd 1 0\n" +
+			"Ks 0.5 0.5 0.5\n",
+	}
+
+	objects, rdr, err := readFiles(files, Lenient())
+	if err != nil {
+		t.Fatalf("lenient read failed: %v", err)
+	}
+
+	want := []ParseError{
+		{File: "test.mtl", Line: 2, Column: 1},
+		{File: "test.obj", Line: 3, Column: 5},
+		{File: "test.obj", Line: 6, Column: 7},
+	}
+	warnings := rdr.Warnings()
+	if len(warnings) != len(want) {
+		t.Fatalf("got %d warnings %v, want %d", len(warnings), warnings, len(want))
+	}
+	for i, w := range warnings {
+		if w.File != want[i].File || w.Line != want[i].Line || w.Column != want[i].Column {
+			t.Errorf("warning %d at %v:%d:%d, want %v:%d:%d", i, w.File, w.Line, w.Column, want[i].File, want[i].Line, want[i].Column)
+		}
+	}
+
+	// The bad face is skipped, the bad vertex still counts towards the indices
+	if len(objects) != 1 {
+		t.Fatalf("read %d objects, want 1", len(objects))
+	}
+	o := objects[0]
+	if len(o.Indices) != 6 {
+		t.Errorf("got %d indices, want the 6 of the quad", len(o.Indices))
+	}
+	if o.Material == nil || o.Material.Name != "red" {
+		t.Fatalf("material is %v, want red", o.Material)
+	}
+	if o.Material.Specular.X() != 0.5 {
+		t.Errorf("specular is %v, want the line after the bad one read", o.Material.Specular)
+	}
+
+	// A strict read of the same files stops at the first problem
+	_, _, err = readFiles(files)
+	if perr, ok := err.(*ParseError); !ok || perr.File != "test.mtl" || perr.Line != 2 {
+		t.Errorf("strict read returned %v, want the error at test.mtl:2", err)
+	}
+}
@@ -0,0 +1,209 @@
+package obj
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// faceVertex holds the zero-based v/vt/vn indices of one face corner, -1 when absent
+type faceVertex struct {
+	v  int
+	vt int
+	vn int
+}
+
+// parseFace parses the arguments of an `f` directive in any of the forms
+// `v`, `v/vt`, `v//vn` or `v/vt/vn`, with any number of vertices
+func parseFace(tokens []token, numVerts, numTxcds, numNorms int) ([]faceVertex, *ParseError) {
+	args := tokens[1:]
+	if len(args) < 3 {
+		return nil, &ParseError{
+			Column: tokens[0].col,
+			Msg:    fmt.Sprintf("Face has %d vertices, expected at least 3", len(args)),
+		}
+	}
+
+	face := make([]faceVertex, 0, len(args))
+	for _, tok := range args {
+		parts := strings.Split(tok.text, "/")
+		if len(parts) > 3 || parts[0] == "" {
+			return nil, &ParseError{Column: tok.col, Msg: fmt.Sprintf("Invalid face vertex [%v]", tok.text)}
+		}
+
+		fv := faceVertex{v: -1, vt: -1, vn: -1}
+
+		var msg string
+		fv.v, msg = parseIndex(parts[0], numVerts, "vertex")
+		if msg != "" {
+			return nil, &ParseError{Column: tok.col, Msg: msg}
+		}
+
+		if len(parts) > 1 && parts[1] != "" {
+			fv.vt, msg = parseIndex(parts[1], numTxcds, "texture coordinate")
+			if msg != "" {
+				return nil, &ParseError{Column: tok.col + len(parts[0]) + 1, Msg: msg}
+			}
+		}
+
+		if len(parts) > 2 && parts[2] != "" {
+			fv.vn, msg = parseIndex(parts[2], numNorms, "normal")
+			if msg != "" {
+				return nil, &ParseError{Column: tok.col + len(parts[0]) + len(parts[1]) + 2, Msg: msg}
+			}
+		}
+
+		face = append(face, fv)
+	}
+
+	return face, nil
+}
+
+// parseIndex converts a one-based or negative (relative) OBJ index into a zero-based one,
+// returning a message describing the problem if it is invalid
+func parseIndex(s string, count int, kind string) (int, string) {
+	i, err := strconv.Atoi(s)
+	if err != nil {
+		return -1, fmt.Sprintf("Invalid %v index [%v]", kind, s)
+	}
+
+	if i < 0 {
+		i += count
+	} else {
+		i--
+	}
+
+	if i < 0 || i >= count {
+		return -1, fmt.Sprintf("The %v index [%v] is out of range, %d defined", kind, s, count)
+	}
+
+	return i, ""
+}
+
+// triangulate splits a polygon into triangles by ear clipping, returning
+// triples of indices into points. Degenerate polygons fall back to a fan.
+func triangulate(points []mgl32.Vec3) [][3]int {
+	n := len(points)
+	if n < 3 {
+		return nil
+	}
+	if n == 3 {
+		return [][3]int{{0, 1, 2}}
+	}
+
+	// Newell's method gives a stable normal for non-planar and concave polygons
+	var normal mgl32.Vec3
+	for i := range points {
+		a := points[i]
+		b := points[(i+1)%n]
+		normal[0] += (a[1] - b[1]) * (a[2] + b[2])
+		normal[1] += (a[2] - b[2]) * (a[0] + b[0])
+		normal[2] += (a[0] - b[0]) * (a[1] + b[1])
+	}
+
+	// Project onto the plane most perpendicular to the normal
+	ax, ay := 0, 1
+	nx, ny, nz := abs32(normal[0]), abs32(normal[1]), abs32(normal[2])
+	if nx >= ny && nx >= nz {
+		ax, ay = 1, 2
+	} else if ny >= nz {
+		ax, ay = 2, 0
+	}
+	if nx+ny+nz < 1e-12 {
+		return fan(n)
+	}
+
+	flat := make([]mgl32.Vec2, n)
+	area := float32(0)
+	for i := range points {
+		flat[i] = mgl32.Vec2{points[i][ax], points[i][ay]}
+	}
+	for i := range flat {
+		area += cross2D(mgl32.Vec2{}, flat[i], flat[(i+1)%n])
+	}
+	winding := float32(1)
+	if area < 0 {
+		winding = -1
+	}
+
+	remaining := make([]int, n)
+	for i := range remaining {
+		remaining[i] = i
+	}
+
+	tris := make([][3]int, 0, n-2)
+	for guard := 0; len(remaining) > 3 && guard < n*n; guard++ {
+		clipped := false
+		for i := range remaining {
+			m := len(remaining)
+			prev := remaining[(i+m-1)%m]
+			cur := remaining[i]
+			next := remaining[(i+1)%m]
+
+			if cross2D(flat[prev], flat[cur], flat[next])*winding <= 0 {
+				continue
+			}
+
+			ear := true
+			for _, j := range remaining {
+				if j == prev || j == cur || j == next {
+					continue
+				}
+				if pointInTriangle(flat[j], flat[prev], flat[cur], flat[next], winding) {
+					ear = false
+					break
+				}
+			}
+			if !ear {
+				continue
+			}
+
+			tris = append(tris, [3]int{prev, cur, next})
+			remaining = append(remaining[:i], remaining[i+1:]...)
+			clipped = true
+			break
+		}
+
+		if !clipped {
+			break
+		}
+	}
+
+	// Whatever could not be clipped (collinear or self-intersecting) is fanned
+	for i := 1; i+1 < len(remaining); i++ {
+		tris = append(tris, [3]int{remaining[0], remaining[i], remaining[i+1]})
+	}
+
+	return tris
+}
+
+func fan(n int) [][3]int {
+	tris := make([][3]int, 0, n-2)
+	for i := 1; i+1 < n; i++ {
+		tris = append(tris, [3]int{0, i, i + 1})
+	}
+	return tris
+}
+
+func cross2D(a, b, c mgl32.Vec2) float32 {
+	return (b[0]-a[0])*(c[1]-a[1]) - (b[1]-a[1])*(c[0]-a[0])
+}
+
+// pointInTriangle returns whether p is inside abc or on its edges. A vertex at
+// one of its corners, like a duplicate vertex, doesn't block an ear, but one on
+// an edge does, as the polygon may fold back through it.
+func pointInTriangle(p, a, b, c mgl32.Vec2, winding float32) bool {
+	if p == a || p == b || p == c {
+		return false
+	}
+	return cross2D(a, b, p)*winding >= 0 &&
+		cross2D(b, c, p)*winding >= 0 &&
+		cross2D(c, a, p)*winding >= 0
+}
+
+func abs32(f float32) float32 {
+	return float32(math.Abs(float64(f)))
+}
@@ -0,0 +1,298 @@
+package obj
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// readFiles reads the OBJ file "test.obj" from files, which holds the contents
+// of every file by name
+func readFiles(files map[string]string, opts ...Option) ([]*Object, Reader, error) {
+	load := func(filename string) ([]byte, error) {
+		if s, found := files[filename]; found {
+			return []byte(s), nil
+		}
+		return nil, fmt.Errorf("No such file [%v]", filename)
+	}
+	rdr := NewReaderEx("test.obj", load, opts...)
+	objects, err := rdr.Read()
+	return objects, rdr, err
+}
+
+// polygonArea returns the signed area of a polygon in the XY plane
+func polygonArea(points []mgl32.Vec3) float32 {
+	area := float32(0)
+	for i := range points {
+		a, b := points[i], points[(i+1)%len(points)]
+		area += a[0]*b[1] - b[0]*a[1]
+	}
+	return area / 2
+}
+
+func TestTriangulate(t *testing.T) {
+	tests := []struct {
+		name   string
+		points []mgl32.Vec3
+	}{
+		{
+			name:   "triangle",
+			points: []mgl32.Vec3{{0, 0, 0}, {1, 0, 0}, {0, 1, 0}},
+		},
+		{
+			name:   "quad",
+			points: []mgl32.Vec3{{0, 0, 0}, {1, 0, 0}, {1, 1, 0}, {0, 1, 0}},
+		},
+		{
+			name:   "clockwise quad",
+			points: []mgl32.Vec3{{0, 0, 0}, {0, 1, 0}, {1, 1, 0}, {1, 0, 0}},
+		},
+		{
+			name:   "hexagon",
+			points: []mgl32.Vec3{{2, 0, 0}, {1, 1.7, 0}, {-1, 1.7, 0}, {-2, 0, 0}, {-1, -1.7, 0}, {1, -1.7, 0}},
+		},
+		{
+			name:   "concave L",
+			points: []mgl32.Vec3{{0, 0, 0}, {2, 0, 0}, {2, 1, 0}, {1, 1, 0}, {1, 2, 0}, {0, 2, 0}},
+		},
+		{
+			// The notch at (1, 0.2) is a reflex vertex next to the first vertex
+			name:   "concave arrow",
+			points: []mgl32.Vec3{{0, 0, 0}, {1, 0.2, 0}, {2, 0, 0}, {1, 2, 0}},
+		},
+		{
+			name:   "collinear points",
+			points: []mgl32.Vec3{{0, 0, 0}, {1, 0, 0}, {2, 0, 0}, {2, 2, 0}, {1, 2, 0}, {0, 2, 0}},
+		},
+		{
+			name:   "duplicate vertex",
+			points: []mgl32.Vec3{{0, 0, 0}, {2, 0, 0}, {2, 2, 0}, {2, 2, 0}, {0, 2, 0}},
+		},
+		{
+			name:   "duplicate vertex of a concave polygon",
+			points: []mgl32.Vec3{{0, 0, 0}, {2, 0, 0}, {2, 1, 0}, {1, 1, 0}, {1, 1, 0}, {1, 2, 0}, {0, 2, 0}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tris := triangulate(tt.points)
+			if len(tris) != len(tt.points)-2 {
+				t.Fatalf("got %d triangles, want %d", len(tris), len(tt.points)-2)
+			}
+
+			// Every triangle winds the same way as the polygon and together they
+			// cover it exactly, so none can lie outside it or overlap
+			want := polygonArea(tt.points)
+			total := float32(0)
+			for _, tri := range tris {
+				p := []mgl32.Vec3{tt.points[tri[0]], tt.points[tri[1]], tt.points[tri[2]]}
+				area := polygonArea(p)
+				if area*want < 0 {
+					t.Errorf("triangle %v winds against the polygon", tri)
+				}
+				total += area
+			}
+			if math.Abs(float64(total-want)) > 1e-5 {
+				t.Errorf("triangles cover an area of %v, want %v", total, want)
+			}
+		})
+	}
+}
+
+func TestTriangulateNonPlanar(t *testing.T) {
+	// A quad with one corner lifted out of the plane of the others
+	points := []mgl32.Vec3{{0, 0, 0}, {1, 0, 0}, {1, 1, 0.3}, {0, 1, 0}}
+	tris := triangulate(points)
+	if len(tris) != 2 {
+		t.Fatalf("got %d triangles, want 2", len(tris))
+	}
+
+	used := map[int]int{}
+	for _, tri := range tris {
+		a, b, c := points[tri[0]], points[tri[1]], points[tri[2]]
+		normal := b.Sub(a).Cross(c.Sub(a))
+		if normal.Len() < 1e-6 {
+			t.Errorf("triangle %v is degenerate", tri)
+		}
+		if normal.Z() <= 0 {
+			t.Errorf("triangle %v faces away from the quad, normal %v", tri, normal)
+		}
+		for _, i := range tri {
+			used[i]++
+		}
+	}
+	if len(used) != 4 {
+		t.Errorf("triangles use corners %v, want all 4", used)
+	}
+}
+
+func TestParseFace(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+		want []faceVertex
+		// err is the column of the error, 0 if there is none
+		err int
+	}{
+		{
+			name: "v",
+			line: "f 1 2 3",
+			want: []faceVertex{{0, -1, -1}, {1, -1, -1}, {2, -1, -1}},
+		},
+		{
+			name: "v/vt",
+			line: "f 1/4 2/5 3/6",
+			want: []faceVertex{{0, 3, -1}, {1, 4, -1}, {2, 5, -1}},
+		},
+		{
+			name: "v//vn",
+			line: "f 1//2 2//2 3//2",
+			want: []faceVertex{{0, -1, 1}, {1, -1, 1}, {2, -1, 1}},
+		},
+		{
+			name: "v/vt/vn",
+			line: "f 1/1/1 2/2/2 3/3/3 4/4/4",
+			want: []faceVertex{{0, 0, 0}, {1, 1, 1}, {2, 2, 2}, {3, 3, 3}},
+		},
+		{
+			name: "negative v",
+			line: "f -3 -2 -1",
+			want: []faceVertex{{7, -1, -1}, {8, -1, -1}, {9, -1, -1}},
+		},
+		{
+			name: "negative v/vt/vn",
+			line: "f -1/-1/-1 -2/-2/-2 -3/-3/-3",
+			want: []faceVertex{{9, 5, 3}, {8, 4, 2}, {7, 3, 1}},
+		},
+		{
+			name: "negative v//vn",
+			line: "f -1//-4 1//1 2//2",
+			want: []faceVertex{{9, -1, 0}, {0, -1, 0}, {1, -1, 1}},
+		},
+		{
+			name: "too few vertices",
+			line: "f 1 2",
+			err:  1,
+		},
+		{
+			name: "too many parts",
+			line: "f 1 2 3/1/1/1",
+			err:  7,
+		},
+		{
+			name: "zero index",
+			line: "f 0 1 2",
+			err:  3,
+		},
+		{
+			name: "vertex out of range",
+			line: "f 1 2 11",
+			err:  7,
+		},
+		{
+			name: "negative index out of range",
+			line: "f 1 2 -11",
+			err:  7,
+		},
+		{
+			name: "texture coordinate out of range",
+			line: "f 1/7 2/1 3/1",
+			err:  5,
+		},
+		{
+			name: "normal out of range",
+			line: "f 1/1/5 2/1/1 3/1/1",
+			err:  7,
+		},
+		{
+			name: "not a number",
+			line: "f 1 x 3",
+			err:  5,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 10 vertices, 6 texture coordinates and 4 normals are defined
+			face, perr := parseFace(tokenize(tt.line), 10, 6, 4)
+			if tt.err != 0 {
+				if perr == nil {
+					t.Fatalf("parsed %v, want an error", face)
+				}
+				if perr.Column != tt.err {
+					t.Errorf("error at column %d, want %d: %v", perr.Column, tt.err, perr.Msg)
+				}
+				return
+			}
+			if perr != nil {
+				t.Fatalf("unexpected error: %v", perr.Msg)
+			}
+			if !reflect.DeepEqual(face, tt.want) {
+				t.Errorf("parsed %v, want %v", face, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadFaces(t *testing.T) {
+	tests := []struct {
+		name  string
+		faces string
+		// vertices is the number of unique corners, triangles the number of triangles
+		vertices  int
+		triangles int
+		txcds     bool
+		norms     bool
+	}{
+		{name: "triangle", faces: "f 1 2 3", vertices: 3, triangles: 1},
+		{name: "quad", faces: "f 1 2 3 4", vertices: 4, triangles: 2},
+		{name: "n-gon", faces: "f 1 2 5 3 4", vertices: 5, triangles: 3},
+		{name: "v/vt", faces: "f 1/1 2/2 3/3 4/4", vertices: 4, triangles: 2, txcds: true},
+		{name: "v//vn", faces: "f 1//1 2//1 3//1 4//1", vertices: 4, triangles: 2, norms: true},
+		{name: "v/vt/vn", faces: "f 1/1/1 2/2/1 3/3/1 4/4/1", vertices: 4, triangles: 2, txcds: true, norms: true},
+		{name: "negative", faces: "f -5 -4 -3 -2", vertices: 4, triangles: 2},
+		{name: "negative v/vt/vn", faces: "f -5/-4/-1 -4/-3/-1 -3/-2/-1 -2/-1/-1", vertices: 4, triangles: 2, txcds: true, norms: true},
+		{name: "shared corners", faces: "f 1 2 3\nf 1 3 4", vertices: 4, triangles: 2},
+		{name: "split by texture coordinates", faces: "f 1/1 2/2 3/3\nf 1/4 3/3 4/4", vertices: 5, triangles: 2, txcds: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			objects, _, err := readFiles(map[string]string{
+				"test.obj": "v 0 0 0\nv 1 0 0\nv 1 1 0\nv 0 1 0\nv 2 0.5 0\n" +
+					"vt 0 0\nvt 1 0\nvt 1 1\nvt 0 1\n" +
+					"vn 0 0 1\n" +
+					tt.faces + "\n",
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(objects) != 1 {
+				t.Fatalf("read %d objects, want 1", len(objects))
+			}
+
+			o := objects[0]
+			if len(o.Vertices) != tt.vertices {
+				t.Errorf("got %d vertices, want %d", len(o.Vertices), tt.vertices)
+			}
+			if len(o.Indices) != tt.triangles*3 {
+				t.Errorf("got %d indices, want %d", len(o.Indices), tt.triangles*3)
+			}
+			for _, i := range o.Indices {
+				if int(i) >= len(o.Vertices) {
+					t.Errorf("index %d is out of range", i)
+				}
+			}
+			if got := len(o.TexCoords) > 0; got != tt.txcds {
+				t.Errorf("has texture coordinates = %v, want %v", got, tt.txcds)
+			}
+			if got := len(o.Normals) > 0; got != tt.norms {
+				t.Errorf("has normals = %v, want %v", got, tt.norms)
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package obj
+
+import (
+	"math"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// NormalMode selects how normals are generated for faces without `vn` data
+type NormalMode int
+
+const (
+	// NormalsNone leaves faces without normals as they are
+	NormalsNone NormalMode = iota
+	// NormalsAuto smooths faces within the same `s` smoothing group, faces with `s off` are flat
+	NormalsAuto
+	// NormalsFlat gives every face its own normal, ignoring smoothing groups
+	NormalsFlat
+	// NormalsSmooth smooths every face, ignoring smoothing groups
+	NormalsSmooth
+)
+
+// smoothKey identifies the vertices that share a smooth normal
+type smoothKey struct {
+	v     int
+	group int
+}
+
+// generateNormals computes normals for all corners without a `vn` index, pointing
+// them at new entries appended to a copy of norms which is returned
+func (b *objectBuilder) generateNormals(verts []mgl32.Vec3, norms []mgl32.Vec3, mode NormalMode) []mgl32.Vec3 {
+	// Never append into the shared array, it belongs to every object in the file
+	norms = norms[:len(norms):len(norms)]
+
+	smooth := map[smoothKey]mgl32.Vec3{}
+	smoothIndex := map[smoothKey]int{}
+
+	for t := 0; t+2 < len(b.corners); t += 3 {
+		tri := b.corners[t : t+3]
+		if tri[0].vn >= 0 && tri[1].vn >= 0 && tri[2].vn >= 0 {
+			continue
+		}
+
+		p := [3]mgl32.Vec3{verts[tri[0].v], verts[tri[1].v], verts[tri[2].v]}
+		normal := p[1].Sub(p[0]).Cross(p[2].Sub(p[0]))
+		if normal.Len() < 1e-12 {
+			// Degenerate, any direction will do
+			normal = mgl32.Vec3{0, 1, 0}
+		}
+		normal = normal.Normalize()
+
+		group := b.groups[t/3]
+		if mode == NormalsFlat {
+			group = 0
+		} else if mode == NormalsSmooth {
+			group = 1
+		}
+
+		if group == 0 {
+			flat := len(norms)
+			norms = append(norms, normal)
+			for i := range tri {
+				if tri[i].vn < 0 {
+					tri[i].vn = flat
+				}
+			}
+			continue
+		}
+
+		// Weight each face by the angle at the corner, so a vertex's normal doesn't
+		// depend on how its neighbouring faces were triangulated
+		for i := range tri {
+			if tri[i].vn >= 0 {
+				continue
+			}
+			e1 := p[(i+1)%3].Sub(p[i])
+			e2 := p[(i+2)%3].Sub(p[i])
+			angle := float32(0)
+			if e1.Len() > 0 && e2.Len() > 0 {
+				cos := float64(e1.Normalize().Dot(e2.Normalize()))
+				angle = float32(math.Acos(math.Max(-1, math.Min(1, cos))))
+			}
+
+			key := smoothKey{v: tri[i].v, group: group}
+			smooth[key] = smooth[key].Add(normal.Mul(angle))
+		}
+	}
+
+	for t := 0; t+2 < len(b.corners); t += 3 {
+		tri := b.corners[t : t+3]
+
+		group := b.groups[t/3]
+		if mode == NormalsSmooth {
+			group = 1
+		}
+
+		for i := range tri {
+			if tri[i].vn >= 0 {
+				continue
+			}
+
+			key := smoothKey{v: tri[i].v, group: group}
+			index, found := smoothIndex[key]
+			if !found {
+				normal := smooth[key]
+				if normal.Len() < 1e-12 {
+					normal = mgl32.Vec3{0, 1, 0}
+				}
+				index = len(norms)
+				smoothIndex[key] = index
+				norms = append(norms, normal.Normalize())
+			}
+			tri[i].vn = index
+		}
+	}
+
+	return norms
+}
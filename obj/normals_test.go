@@ -0,0 +1,166 @@
+package obj
+
+import (
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// roof is two triangles meeting at a right angle along the edge from v1 to v2,
+// the first facing +Y and the second +X, with the smoothing lines to put before each
+func roof(first, second string) string {
+	return "v 0 0 0\nv 0 0 1\nv 1 0 0\nv 0 1 0\n" +
+		first + "\nf 1 2 3\n" +
+		second + "\nf 1 4 2\n"
+}
+
+// getNormalsAt returns the normals of every vertex of o at pos
+func getNormalsAt(o *Object, pos mgl32.Vec3) []mgl32.Vec3 {
+	normals := []mgl32.Vec3{}
+	for i, v := range o.Vertices {
+		if v == pos {
+			normals = append(normals, o.Normals[i])
+		}
+	}
+	return normals
+}
+
+func TestGenerateNormals(t *testing.T) {
+	var (
+		up      = mgl32.Vec3{0, 1, 0}
+		right   = mgl32.Vec3{1, 0, 0}
+		between = mgl32.Vec3{1, 1, 0}.Normalize()
+	)
+
+	tests := []struct {
+		name string
+		mode NormalMode
+		obj  string
+		// shared are the normals at the vertices on the shared edge, and up and
+		// right those at the other corner of each triangle
+		shared []mgl32.Vec3
+	}{
+		{
+			name:   "no smoothing group is flat",
+			mode:   NormalsAuto,
+			obj:    roof("", ""),
+			shared: []mgl32.Vec3{up, right},
+		},
+		{
+			name:   "s off is flat",
+			mode:   NormalsAuto,
+			obj:    roof("s off", ""),
+			shared: []mgl32.Vec3{up, right},
+		},
+		{
+			name:   "s 0 is flat",
+			mode:   NormalsAuto,
+			obj:    roof("s 1", "s 0"),
+			shared: []mgl32.Vec3{up, right},
+		},
+		{
+			name:   "one group is smooth",
+			mode:   NormalsAuto,
+			obj:    roof("s 1", ""),
+			shared: []mgl32.Vec3{between},
+		},
+		{
+			name:   "different groups are split",
+			mode:   NormalsAuto,
+			obj:    roof("s 1", "s 2"),
+			shared: []mgl32.Vec3{up, right},
+		},
+		{
+			name:   "the same group after another is smooth",
+			mode:   NormalsAuto,
+			obj:    roof("s 3", "s 2\ns 3"),
+			shared: []mgl32.Vec3{between},
+		},
+		{
+			name:   "flat ignores groups",
+			mode:   NormalsFlat,
+			obj:    roof("s 1", ""),
+			shared: []mgl32.Vec3{up, right},
+		},
+		{
+			name:   "smooth ignores groups",
+			mode:   NormalsSmooth,
+			obj:    roof("s off", "s 2"),
+			shared: []mgl32.Vec3{between},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			objects, _, err := readFiles(map[string]string{"test.obj": tt.obj}, GenerateNormals(tt.mode))
+			if err != nil {
+				t.Fatal(err)
+			}
+			o := objects[0]
+			if len(o.Normals) != len(o.Vertices) {
+				t.Fatalf("got %d normals for %d vertices", len(o.Normals), len(o.Vertices))
+			}
+
+			for _, pos := range []mgl32.Vec3{{0, 0, 0}, {0, 0, 1}} {
+				got := getNormalsAt(o, pos)
+				if !sameNormals(got, tt.shared) {
+					t.Errorf("normals at %v are %v, want %v", pos, got, tt.shared)
+				}
+			}
+			if got := getNormalsAt(o, mgl32.Vec3{1, 0, 0}); !sameNormals(got, []mgl32.Vec3{up}) {
+				t.Errorf("normals of the first triangle's corner are %v, want %v", got, up)
+			}
+			if got := getNormalsAt(o, mgl32.Vec3{0, 1, 0}); !sameNormals(got, []mgl32.Vec3{right}) {
+				t.Errorf("normals of the second triangle's corner are %v, want %v", got, right)
+			}
+		})
+	}
+}
+
+func TestGenerateNormalsKeepsGiven(t *testing.T) {
+	obj := "v 0 0 0\nv 0 0 1\nv 1 0 0\nvn 0 0 1\ns 1\nf 1//1 2//1 3//1\nf 1 3 2\n"
+
+	objects, _, err := readFiles(map[string]string{"test.obj": obj}, GenerateNormals(NormalsAuto))
+	if err != nil {
+		t.Fatal(err)
+	}
+	o := objects[0]
+
+	// The first face keeps its vn, the second is facing -Y
+	want := []mgl32.Vec3{{0, 0, 1}, {0, -1, 0}}
+	if got := getNormalsAt(o, mgl32.Vec3{0, 0, 0}); !sameNormals(got, want) {
+		t.Errorf("normals are %v, want %v", got, want)
+	}
+}
+
+func TestGenerateNormalsNone(t *testing.T) {
+	objects, _, err := readFiles(map[string]string{"test.obj": roof("s 1", "")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := len(objects[0].Normals); n != 0 {
+		t.Errorf("got %d normals, want none without GenerateNormals", n)
+	}
+}
+
+// sameNormals returns whether a and b hold the same normals in any order
+func sameNormals(a, b []mgl32.Vec3) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	used := make([]bool, len(b))
+	for _, n := range a {
+		found := false
+		for j, m := range b {
+			if !used[j] && n.ApproxEqualThreshold(m, 1e-5) {
+				used[j] = true
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
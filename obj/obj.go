@@ -6,7 +6,7 @@ import (
 	"io"
 	"io/ioutil"
 	"path/filepath"
-	"strings"
+	"strconv"
 
 	"github.com/go-gl/mathgl/mgl32"
 )
@@ -16,6 +16,7 @@ type Object struct {
 	Vertices  []mgl32.Vec3
 	Normals   []mgl32.Vec3
 	TexCoords []mgl32.Vec2
+	Indices   []uint32
 	Material  *Material
 }
 
@@ -24,48 +25,111 @@ type Material struct {
 	Ambient              mgl32.Vec3
 	Diffuse              mgl32.Vec3
 	Specular             mgl32.Vec3
+	Emissive             mgl32.Vec3
 	Shininess            float32
 	Dissolve             float32
 	AmbientMap           string
 	DiffuseMap           string
 	SpecularMap          string
+	EmissiveMap          string
 	SpecularHighlightMap string
 	BumpMap              string
 	AlphaMap             string
 	DisplacementMap      string
 	ReflectionMap        string
+
+	AmbientMapOptions           TextureOptions
+	DiffuseMapOptions           TextureOptions
+	SpecularMapOptions          TextureOptions
+	EmissiveMapOptions          TextureOptions
+	SpecularHighlightMapOptions TextureOptions
+	BumpMapOptions              TextureOptions
+	AlphaMapOptions             TextureOptions
+	DisplacementMapOptions      TextureOptions
+	ReflectionMapOptions        TextureOptions
+}
+
+// NewMaterial returns a Material with the defaults of an empty `newmtl` block
+func NewMaterial(name string) *Material {
+	opts := DefaultTextureOptions()
+	return &Material{
+		Name:     name,
+		Ambient:  mgl32.Vec3{0, 0, 0},
+		Diffuse:  mgl32.Vec3{0, 0, 0},
+		Specular: mgl32.Vec3{0, 0, 0},
+		Emissive: mgl32.Vec3{0, 0, 0},
+		Dissolve: 1,
+
+		AmbientMapOptions:           opts,
+		DiffuseMapOptions:           opts,
+		SpecularMapOptions:          opts,
+		EmissiveMapOptions:          opts,
+		SpecularHighlightMapOptions: opts,
+		BumpMapOptions:              opts,
+		AlphaMapOptions:             opts,
+		DisplacementMapOptions:      opts,
+		ReflectionMapOptions:        opts,
+	}
 }
 
 type LoadFunc func(string) ([]byte, error)
 
 type Reader interface {
 	Read() ([]*Object, error)
+	Warnings() []*ParseError
 }
 
-func NewReader(filename string) Reader {
-	return &reader{
-		filename: filename,
-		load:     ioutil.ReadFile,
+// Option configures a Reader
+type Option func(*reader)
+
+// Lenient makes Read record malformed lines as warnings and skip them instead of failing
+func Lenient() Option {
+	return func(rdr *reader) {
+		rdr.lenient = true
+	}
+}
+
+// GenerateNormals sets how normals are generated for faces without `vn` data, the default is NormalsNone
+func GenerateNormals(mode NormalMode) Option {
+	return func(rdr *reader) {
+		rdr.normals = mode
 	}
 }
 
-func NewReaderEx(filename string, load LoadFunc) Reader {
-	return &reader{
+func NewReader(filename string, opts ...Option) Reader {
+	return NewReaderEx(filename, ioutil.ReadFile, opts...)
+}
+
+func NewReaderEx(filename string, load LoadFunc, opts ...Option) Reader {
+	rdr := &reader{
 		filename: filename,
 		load:     load,
 	}
+	for _, opt := range opts {
+		opt(rdr)
+	}
+	return rdr
 }
 
 type reader struct {
 	filename string
 	load     LoadFunc
+	lenient  bool
+	normals  NormalMode
+	warnings []*ParseError
+}
+
+// Warnings returns the problems skipped by the last call to Read in lenient mode
+func (rdr *reader) Warnings() []*ParseError {
+	return rdr.warnings
 }
 
 func (rdr *reader) Read() ([]*Object, error) {
 	rdr.filename = filepath.Clean(rdr.filename)
+	rdr.warnings = nil
 	dir := filepath.Dir(rdr.filename)
 
-	objects := []*Object{}
+	builders := []*objectBuilder{}
 	materials := map[string]*Material{}
 
 	file, err := rdr.load(rdr.filename)
@@ -73,18 +137,15 @@ func (rdr *reader) Read() ([]*Object, error) {
 		return nil, err
 	}
 
-	if file[len(file)-1] != '\n' {
+	if len(file) == 0 || file[len(file)-1] != '\n' {
 		file = append(file, '\n')
 	}
 
 	buf := bytes.NewBuffer(file)
 
-	var o *Object
-	var x, y, z, u, v float32
-	var f [3][3]int
-
-	var hasNorm bool
-	var hasTxcd bool
+	var o *objectBuilder
+	var lineNum int
+	var group int
 
 	verts := []mgl32.Vec3{}
 	norms := []mgl32.Vec3{}
@@ -99,110 +160,153 @@ func (rdr *reader) Read() ([]*Object, error) {
 				return nil, err
 			}
 		}
+		lineNum++
 
 		line := string(bytes)
+		tokens := tokenize(line)
 
-		if len(line) < 2 || line[0] == '#' || line[0] == '\n' {
+		if len(tokens) == 0 || tokens[0].text[0] == '#' {
 			continue
 		}
 
-		if line[0] == 'v' {
-			if line[1] == 'n' {
-				fmt.Sscanf(line[3:], "%f %f %f", &x, &y, &z)
-				norms = append(norms, mgl32.Vec3{x, y, z})
-			} else if line[1] == 't' {
-				fmt.Sscanf(line[3:], "%f %f", &u, &v)
-				txcds = append(txcds, mgl32.Vec2{u, v})
-			} else {
-				fmt.Sscanf(line[2:], "%f %f %f", &x, &y, &z)
-				verts = append(verts, mgl32.Vec3{x, y, z})
+		switch tokens[0].text {
+		case "v":
+			values, perr := parseFloats(tokens, 3, 6)
+			if perr != nil {
+				if err := rdr.report(rdr.filename, lineNum, perr); err != nil {
+					return nil, err
+				}
+				// Keep later indices valid
+				values = []float32{0, 0, 0}
 			}
-		} else if line[0] == 'f' {
-			if o == nil {
-				o = &Object{
-					Name: "default",
+			verts = append(verts, mgl32.Vec3{values[0], values[1], values[2]})
+		case "vt":
+			values, perr := parseFloats(tokens, 1, 3)
+			if perr != nil {
+				if err := rdr.report(rdr.filename, lineNum, perr); err != nil {
+					return nil, err
 				}
-				objects = append(objects, o)
+				values = []float32{0, 0}
 			}
-
-			hasNorm = false
-			hasTxcd = false
-			if strings.Contains(line, "//") {
-				hasNorm = true
-				fmt.Sscanf(line[2:], "%d//%d %d//%d %d//%d",
-					&f[0][0], &f[0][2],
-					&f[1][0], &f[1][2],
-					&f[2][0], &f[2][2])
-			} else {
-				if strings.Count(line, "/") == 3 {
-					hasTxcd = true
-					fmt.Sscanf(line[2:], "%d/%d %d/%d %d/%d",
-						&f[0][0], &f[0][1],
-						&f[1][0], &f[1][1],
-						&f[2][0], &f[2][1])
-				} else {
-					hasNorm = true
-					hasTxcd = true
-					fmt.Sscanf(line[2:], "%d/%d/%d %d/%d/%d %d/%d/%d",
-						&f[0][0], &f[0][1], &f[0][2],
-						&f[1][0], &f[1][1], &f[1][2],
-						&f[2][0], &f[2][1], &f[2][2])
+			values = append(values, 0)
+			txcds = append(txcds, mgl32.Vec2{values[0], values[1]})
+		case "vn":
+			values, perr := parseFloats(tokens, 3, 3)
+			if perr != nil {
+				if err := rdr.report(rdr.filename, lineNum, perr); err != nil {
+					return nil, err
 				}
+				values = []float32{0, 0, 0}
+			}
+			norms = append(norms, mgl32.Vec3{values[0], values[1], values[2]})
+		case "f":
+			if o == nil {
+				o = newObjectBuilder("default")
+				builders = append(builders, o)
 			}
-			// TODO: Handle `f %d %d %d`
 
-			for i := 0; i < 3; i++ {
-				if f[i][0] < 0 {
-					f[i][0] += len(verts)
-				}
-				if f[i][1] < 0 {
-					f[i][1] += len(txcds)
-				}
-				if f[i][2] < 0 {
-					f[i][2] += len(norms)
+			face, perr := parseFace(tokens, len(verts), len(txcds), len(norms))
+			if perr != nil {
+				if err := rdr.report(rdr.filename, lineNum, perr); err != nil {
+					return nil, err
 				}
+				continue
+			}
 
-				o.Vertices = append(o.Vertices, verts[f[i][0]-1])
+			points := make([]mgl32.Vec3, len(face))
+			for i := range face {
+				points[i] = verts[face[i].v]
+			}
 
-				if hasTxcd {
-					o.TexCoords = append(o.TexCoords, txcds[f[i][1]-1])
+			for _, tri := range triangulate(points) {
+				for _, i := range tri {
+					o.corners = append(o.corners, face[i])
+				}
+				o.groups = append(o.groups, group)
+			}
+		case "s":
+			if len(tokens) != 2 {
+				perr := &ParseError{Column: tokens[0].col, Msg: "[s] expects a group number or off"}
+				if err := rdr.report(rdr.filename, lineNum, perr); err != nil {
+					return nil, err
 				}
+				continue
+			}
 
-				if hasNorm {
-					o.Normals = append(o.Normals, norms[f[i][2]-1])
+			if tokens[1].text == "off" {
+				group = 0
+			} else if n, err := strconv.Atoi(tokens[1].text); err == nil && n >= 0 {
+				group = n
+			} else {
+				perr := &ParseError{Column: tokens[1].col, Msg: fmt.Sprintf("Invalid smoothing group [%v]", tokens[1].text)}
+				if err := rdr.report(rdr.filename, lineNum, perr); err != nil {
+					return nil, err
 				}
 			}
-		} else if line[0] == 'o' {
-			o = &Object{
-				Name:      strings.TrimSpace(line[2:]),
-				Vertices:  []mgl32.Vec3{},
-				Normals:   []mgl32.Vec3{},
-				TexCoords: []mgl32.Vec2{},
+		case "o":
+			name := "default"
+			if len(tokens) > 1 {
+				name = rest(line, tokens[1])
+			}
+			o = newObjectBuilder(name)
+			builders = append(builders, o)
+		case "mtllib":
+			if len(tokens) < 2 {
+				perr := &ParseError{Column: tokens[0].col, Msg: "[mtllib] expects a filename"}
+				if err := rdr.report(rdr.filename, lineNum, perr); err != nil {
+					return nil, err
+				}
+				continue
 			}
-			objects = append(objects, o)
-		} else if strings.HasPrefix(line, "mtllib") {
-			tmp, err := rdr.readMaterial(filepath.Join(dir, strings.TrimSpace(line[7:])))
+
+			tmp, err := rdr.readMaterial(filepath.Join(dir, rest(line, tokens[1])))
 			if err != nil {
-				return nil, err
+				if perr, ok := err.(*ParseError); ok {
+					return nil, perr
+				}
+				perr := &ParseError{
+					Column: tokens[1].col,
+					Msg:    fmt.Sprintf("Failed to load material library [%v]: %v", rest(line, tokens[1]), err),
+				}
+				if err := rdr.report(rdr.filename, lineNum, perr); err != nil {
+					return nil, err
+				}
+				continue
 			}
 			for k, v := range tmp {
 				materials[k] = v
 			}
-		} else if strings.HasPrefix(line, "usemtl") {
+		case "usemtl":
 			if o == nil {
-				o = &Object{
-					Name: "default",
+				o = newObjectBuilder("default")
+				builders = append(builders, o)
+			}
+
+			if len(tokens) < 2 {
+				perr := &ParseError{Column: tokens[0].col, Msg: "[usemtl] expects a material name"}
+				if err := rdr.report(rdr.filename, lineNum, perr); err != nil {
+					return nil, err
 				}
-				objects = append(objects, o)
+				continue
 			}
 
-			name := strings.TrimSpace(line[7:])
+			name := rest(line, tokens[1])
 			if m, ok := materials[name]; ok {
 				o.Material = m
+			} else {
+				perr := &ParseError{Column: tokens[1].col, Msg: fmt.Sprintf("Unknown material [%v]", name)}
+				if err := rdr.report(rdr.filename, lineNum, perr); err != nil {
+					return nil, err
+				}
 			}
 		}
 	}
 
+	objects := make([]*Object, 0, len(builders))
+	for _, b := range builders {
+		objects = append(objects, b.build(verts, txcds, norms, rdr.normals))
+	}
+
 	return objects, nil
 }
 
@@ -217,13 +321,14 @@ func (rdr *reader) readMaterial(filename string) (map[string]*Material, error) {
 		return nil, err
 	}
 
-	if file[len(file)-1] != '\n' {
+	if len(file) == 0 || file[len(file)-1] != '\n' {
 		file = append(file, '\n')
 	}
 
 	buf := bytes.NewBuffer(file)
 
 	var m *Material
+	var lineNum int
 
 	for {
 		bytes, err := buf.ReadBytes('\n')
@@ -234,73 +339,116 @@ func (rdr *reader) readMaterial(filename string) (map[string]*Material, error) {
 				return nil, err
 			}
 		}
+		lineNum++
 
 		line := string(bytes)
+		tokens := tokenize(line)
 
-		if len(line) < 2 || line[0] == '#' || line[0] == '\n' {
+		if len(tokens) == 0 || tokens[0].text[0] == '#' {
 			continue
 		}
 
-		if strings.HasPrefix(line, "newmtl") { // newmtl
-			name := strings.TrimSpace(line[7:])
-			m = &Material{
-				Name:     name,
-				Ambient:  mgl32.Vec3{0, 0, 0},
-				Diffuse:  mgl32.Vec3{0, 0, 0},
-				Specular: mgl32.Vec3{0, 0, 0},
+		key := tokens[0].text
+		if key == "newmtl" {
+			if len(tokens) < 2 {
+				perr := &ParseError{Column: tokens[0].col, Msg: "[newmtl] expects a material name"}
+				if err := rdr.report(filename, lineNum, perr); err != nil {
+					return nil, err
+				}
+				m = nil
+				continue
 			}
+
+			name := rest(line, tokens[1])
+			m = NewMaterial(name)
 			materials[name] = m
-		} else if line[0] == 'K' {
-			if line[1] == 'a' {
-				if m != nil {
-					// Ka
-					fmt.Sscanf(line[3:], "%f %f %f", &m.Ambient[0], &m.Ambient[1], &m.Ambient[2])
-				}
-			} else if line[1] == 'd' {
-				if m != nil {
-					// Kd
-					fmt.Sscanf(line[3:], "%f %f %f", &m.Diffuse[0], &m.Diffuse[1], &m.Diffuse[2])
-				}
-			} else if line[1] == 's' {
-				if m != nil {
-					// Ks
-					fmt.Sscanf(line[3:], "%f %f %f", &m.Specular[0], &m.Specular[1], &m.Specular[2])
+			continue
+		}
+
+		var perr *ParseError
+		if m == nil {
+			perr = &ParseError{Column: tokens[0].col, Msg: fmt.Sprintf("[%v] before [newmtl]", key)}
+		} else if color := materialColor(m, key); color != nil {
+			// Ka, Kd, Ks, Ke
+			var values []float32
+			values, perr = parseFloats(tokens, 3, 3)
+			if perr == nil {
+				*color = mgl32.Vec3{values[0], values[1], values[2]}
+			}
+		} else if key == "Ns" {
+			var values []float32
+			values, perr = parseFloats(tokens, 1, 1)
+			if perr == nil {
+				m.Shininess = values[0]
+			}
+		} else if key == "d" || key == "Tr" {
+			var values []float32
+			values, perr = parseFloats(tokens, 1, 1)
+			if perr == nil {
+				m.Dissolve = values[0]
+				if key == "Tr" {
+					// Tr is transparency, the inverse of d
+					m.Dissolve = 1 - values[0]
 				}
 			}
-		} else if line[0] == 'N' && line[1] == 's' {
-			// Ns
-			fmt.Sscanf(line[3:], "%f", &m.Shininess)
-		} else if strings.HasPrefix(line, "map_K") {
-			if line[5] == 'a' {
-				// map_Ka
-				m.AmbientMap = filepath.Join(dir, strings.TrimSpace(line[7:]))
-			} else if line[5] == 'd' {
-				// map_Kd
-				m.DiffuseMap = filepath.Join(dir, strings.TrimSpace(line[7:]))
-			} else if line[5] == 's' {
-				// map_Ks
-				m.SpecularMap = filepath.Join(dir, strings.TrimSpace(line[7:]))
+		} else if path, opts := materialMap(m, key); path != nil {
+			// map_Ka, map_Kd, ...
+			var n int
+			*opts, n, perr = parseTextureOptions(tokens)
+			if perr == nil && n >= len(tokens) {
+				perr = &ParseError{Column: tokens[0].col, Msg: fmt.Sprintf("[%v] expects a filename", key)}
+			}
+			if perr == nil {
+				*path = filepath.Join(dir, rest(line, tokens[n]))
+			}
+		}
+
+		if perr != nil {
+			if err := rdr.report(filename, lineNum, perr); err != nil {
+				return nil, err
 			}
-		} else if strings.HasPrefix(line, "map_Ns") {
-			// map_Ns
-			m.SpecularHighlightMap = filepath.Join(dir, strings.TrimSpace(line[7:]))
-		} else if strings.HasPrefix(line, "bump") {
-			// bump
-			m.BumpMap = filepath.Join(dir, strings.TrimSpace(line[5:]))
-		} else if strings.HasPrefix(line, "map_bump") {
-			// map_bump
-			m.BumpMap = filepath.Join(dir, strings.TrimSpace(line[7:]))
-		} else if strings.HasPrefix(line, "disp") {
-			// disp
-			m.DisplacementMap = filepath.Join(dir, strings.TrimSpace(line[5:]))
-		} else if strings.HasPrefix(line, "refl") {
-			// refl
-			m.ReflectionMap = filepath.Join(dir, strings.TrimSpace(line[5:]))
-		} else if strings.HasPrefix(line, "map_d") {
-			// map_d
-			m.AlphaMap = filepath.Join(dir, strings.TrimSpace(line[6:]))
 		}
 	}
 
 	return materials, nil
 }
+
+// materialColor returns the color set by an MTL directive, or nil
+func materialColor(m *Material, key string) *mgl32.Vec3 {
+	switch key {
+	case "Ka":
+		return &m.Ambient
+	case "Kd":
+		return &m.Diffuse
+	case "Ks":
+		return &m.Specular
+	case "Ke":
+		return &m.Emissive
+	}
+	return nil
+}
+
+// materialMap returns the texture map filename and options set by an MTL directive, or nil
+func materialMap(m *Material, key string) (*string, *TextureOptions) {
+	switch key {
+	case "map_Ka":
+		return &m.AmbientMap, &m.AmbientMapOptions
+	case "map_Kd":
+		return &m.DiffuseMap, &m.DiffuseMapOptions
+	case "map_Ks":
+		return &m.SpecularMap, &m.SpecularMapOptions
+	case "map_Ke":
+		return &m.EmissiveMap, &m.EmissiveMapOptions
+	case "map_Ns":
+		return &m.SpecularHighlightMap, &m.SpecularHighlightMapOptions
+	case "bump", "map_bump", "map_Bump":
+		return &m.BumpMap, &m.BumpMapOptions
+	case "map_d":
+		return &m.AlphaMap, &m.AlphaMapOptions
+	case "disp":
+		return &m.DisplacementMap, &m.DisplacementMapOptions
+	case "refl":
+		return &m.ReflectionMap, &m.ReflectionMapOptions
+	}
+	return nil, nil
+}
@@ -0,0 +1,142 @@
+package obj
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// TextureOptions holds the options that can precede the filename of an MTL texture map
+type TextureOptions struct {
+	// Offset is the UVW origin offset, from `-o u [v [w]]`
+	Offset mgl32.Vec3
+	// Scale is the UVW scale, from `-s u [v [w]]`
+	Scale mgl32.Vec3
+	// Turbulence is the UVW turbulence, from `-t u [v [w]]`
+	Turbulence mgl32.Vec3
+	// Clamp restricts UVs to [0,1] instead of repeating, from `-clamp on|off`
+	Clamp bool
+	// BlendU and BlendV enable horizontal and vertical texture blending, from `-blendu on|off` and `-blendv on|off`
+	BlendU bool
+	BlendV bool
+	// BumpMultiplier scales the values of a bump map, from `-bm mult`
+	BumpMultiplier float32
+	// Base and Gain modify the texture values, from `-mm base gain`
+	Base float32
+	Gain float32
+}
+
+// DefaultTextureOptions returns the TextureOptions used when none are given
+func DefaultTextureOptions() TextureOptions {
+	return TextureOptions{
+		Scale:          mgl32.Vec3{1, 1, 1},
+		BlendU:         true,
+		BlendV:         true,
+		BumpMultiplier: 1,
+		Gain:           1,
+	}
+}
+
+// parseTextureOptions parses the options following a map directive in tokens[0],
+// returning them and the index of the token the filename starts at
+func parseTextureOptions(tokens []token) (TextureOptions, int, *ParseError) {
+	opts := DefaultTextureOptions()
+
+	i := 1
+	for i < len(tokens) && len(tokens[i].text) > 1 && tokens[i].text[0] == '-' {
+		opt := tokens[i]
+		i++
+
+		var perr *ParseError
+		switch opt.text {
+		case "-o":
+			i, perr = parseOptionVec3(tokens, i, opt, &opts.Offset)
+		case "-s":
+			i, perr = parseOptionVec3(tokens, i, opt, &opts.Scale)
+		case "-t":
+			i, perr = parseOptionVec3(tokens, i, opt, &opts.Turbulence)
+		case "-clamp":
+			i, perr = parseOptionBool(tokens, i, opt, &opts.Clamp)
+		case "-blendu":
+			i, perr = parseOptionBool(tokens, i, opt, &opts.BlendU)
+		case "-blendv":
+			i, perr = parseOptionBool(tokens, i, opt, &opts.BlendV)
+		case "-bm":
+			i, perr = parseOptionFloats(tokens, i, opt, &opts.BumpMultiplier)
+		case "-mm":
+			i, perr = parseOptionFloats(tokens, i, opt, &opts.Base, &opts.Gain)
+		case "-cc":
+			// Color correction, ignored
+			var cc bool
+			i, perr = parseOptionBool(tokens, i, opt, &cc)
+		case "-boost", "-texres":
+			// Mipmap sharpness and resolution, ignored
+			var f float32
+			i, perr = parseOptionFloats(tokens, i, opt, &f)
+		case "-imfchan", "-type":
+			// Channel and reflection type, ignored
+			if i >= len(tokens) {
+				perr = &ParseError{Column: opt.col, Msg: fmt.Sprintf("[%v] expects a value", opt.text)}
+			}
+			i++
+		default:
+			// Not an option, but a filename starting with -
+			return opts, i - 1, nil
+		}
+
+		if perr != nil {
+			return opts, i, perr
+		}
+	}
+
+	return opts, i, nil
+}
+
+// parseOptionVec3 parses one to three numbers starting at tokens[i], unset components keep their value
+func parseOptionVec3(tokens []token, i int, opt token, v *mgl32.Vec3) (int, *ParseError) {
+	n := 0
+	for ; n < 3 && i+n < len(tokens); n++ {
+		f, err := strconv.ParseFloat(tokens[i+n].text, 32)
+		if err != nil {
+			break
+		}
+		v[n] = float32(f)
+	}
+	if n == 0 {
+		return i, &ParseError{Column: opt.col, Msg: fmt.Sprintf("[%v] expects 1 to 3 values", opt.text)}
+	}
+	return i + n, nil
+}
+
+// parseOptionFloats parses exactly len(values) numbers starting at tokens[i]
+func parseOptionFloats(tokens []token, i int, opt token, values ...*float32) (int, *ParseError) {
+	for _, v := range values {
+		if i >= len(tokens) {
+			return i, &ParseError{Column: opt.col, Msg: fmt.Sprintf("[%v] expects %d values", opt.text, len(values))}
+		}
+		f, err := strconv.ParseFloat(tokens[i].text, 32)
+		if err != nil {
+			return i, &ParseError{Column: tokens[i].col, Msg: fmt.Sprintf("Invalid number [%v]", tokens[i].text)}
+		}
+		*v = float32(f)
+		i++
+	}
+	return i, nil
+}
+
+// parseOptionBool parses `on` or `off` at tokens[i]
+func parseOptionBool(tokens []token, i int, opt token, b *bool) (int, *ParseError) {
+	if i >= len(tokens) {
+		return i, &ParseError{Column: opt.col, Msg: fmt.Sprintf("[%v] expects on or off", opt.text)}
+	}
+	switch tokens[i].text {
+	case "on":
+		*b = true
+	case "off":
+		*b = false
+	default:
+		return i, &ParseError{Column: tokens[i].col, Msg: fmt.Sprintf("[%v] expects on or off, got [%v]", opt.text, tokens[i].text)}
+	}
+	return i + 1, nil
+}
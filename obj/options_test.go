@@ -0,0 +1,192 @@
+package obj
+
+import (
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+func TestParseTextureOptions(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+		// want is changed from the defaults by set
+		set func(o *TextureOptions)
+		// file is the index of the token the filename starts at
+		file int
+		// err is the column of the error, 0 if there is none
+		err int
+	}{
+		{
+			name: "no options",
+			line: "map_Kd wood.png",
+			file: 1,
+		},
+		{
+			name: "offset of one value",
+			line: "map_Kd -o 0.5 wood.png",
+			set:  func(o *TextureOptions) { o.Offset = mgl32.Vec3{0.5, 0, 0} },
+			file: 3,
+		},
+		{
+			name: "offset of three values",
+			line: "map_Kd -o 0.5 0.25 0.125 wood.png",
+			set:  func(o *TextureOptions) { o.Offset = mgl32.Vec3{0.5, 0.25, 0.125} },
+			file: 5,
+		},
+		{
+			// Unset components keep their default of 1
+			name: "scale of two values",
+			line: "map_Kd -s 2 4 wood.png",
+			set:  func(o *TextureOptions) { o.Scale = mgl32.Vec3{2, 4, 1} },
+			file: 4,
+		},
+		{
+			name: "turbulence",
+			line: "map_Kd -t 0.1 0.2 wood.png",
+			set:  func(o *TextureOptions) { o.Turbulence = mgl32.Vec3{0.1, 0.2, 0} },
+			file: 4,
+		},
+		{
+			name: "clamp on",
+			line: "map_Kd -clamp on wood.png",
+			set:  func(o *TextureOptions) { o.Clamp = true },
+			file: 3,
+		},
+		{
+			name: "clamp off",
+			line: "map_Kd -clamp off wood.png",
+			file: 3,
+		},
+		{
+			name: "blend off",
+			line: "map_Kd -blendu off -blendv off wood.png",
+			set: func(o *TextureOptions) {
+				o.BlendU = false
+				o.BlendV = false
+			},
+			file: 5,
+		},
+		{
+			name: "bump multiplier",
+			line: "map_bump -bm 0.3 normal.png",
+			set:  func(o *TextureOptions) { o.BumpMultiplier = 0.3 },
+			file: 3,
+		},
+		{
+			name: "base and gain",
+			line: "map_Kd -mm 0.2 0.8 wood.png",
+			set: func(o *TextureOptions) {
+				o.Base = 0.2
+				o.Gain = 0.8
+			},
+			file: 4,
+		},
+		{
+			name: "every option",
+			line: "map_Kd -o 1 2 -s 3 -clamp on -bm 2 -blendu off wood.png",
+			set: func(o *TextureOptions) {
+				o.Offset = mgl32.Vec3{1, 2, 0}
+				o.Scale = mgl32.Vec3{3, 1, 1}
+				o.Clamp = true
+				o.BumpMultiplier = 2
+				o.BlendU = false
+			},
+			file: 12,
+		},
+		{
+			name: "ignored options",
+			line: "map_Kd -cc on -boost 2 -texres 512 -imfchan r wood.png",
+			file: 9,
+		},
+		{
+			name: "filename starting with a dash",
+			line: "map_Kd -clamp on -wood.png",
+			set:  func(o *TextureOptions) { o.Clamp = true },
+			file: 3,
+		},
+		{
+			name: "offset without values",
+			line: "map_Kd -o wood.png",
+			err:  8,
+		},
+		{
+			name: "clamp without a value",
+			line: "map_Kd -clamp",
+			err:  8,
+		},
+		{
+			name: "clamp with a bad value",
+			line: "map_Kd -clamp yes wood.png",
+			err:  15,
+		},
+		{
+			name: "bump multiplier with a bad value",
+			line: "map_bump -bm x normal.png",
+			err:  14,
+		},
+		{
+			name: "base without gain",
+			line: "map_Kd -mm 0.2",
+			err:  8,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts, n, perr := parseTextureOptions(tokenize(tt.line))
+			if tt.err != 0 {
+				if perr == nil {
+					t.Fatalf("parsed %+v, want an error", opts)
+				}
+				if perr.Column != tt.err {
+					t.Errorf("error at column %d, want %d: %v", perr.Column, tt.err, perr.Msg)
+				}
+				return
+			}
+			if perr != nil {
+				t.Fatalf("unexpected error: %v", perr.Msg)
+			}
+
+			want := DefaultTextureOptions()
+			if tt.set != nil {
+				tt.set(&want)
+			}
+			if opts != want {
+				t.Errorf("parsed %+v, want %+v", opts, want)
+			}
+			if n != tt.file {
+				t.Errorf("filename at token %d, want %d", n, tt.file)
+			}
+		})
+	}
+}
+
+func TestReadTextureOptions(t *testing.T) {
+	objects, _, err := readFiles(map[string]string{
+		"test.obj": "mtllib test.mtl\nv 0 0 0\nv 1 0 0\nv 0 1 0\nusemtl brick\nf 1 2 3\n",
+		"test.mtl": "newmtl brick\n" +
+			"map_Kd -s 4 4 -o 0.5 0.5 textures/brick wall.png\n" +
+			"map_Bump -bm 0.5 -clamp on brick_normal.png\n",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := objects[0].Material
+	if m.DiffuseMap != "textures/brick wall.png" {
+		t.Errorf("DiffuseMap = %q, want the filename after the options", m.DiffuseMap)
+	}
+	if m.DiffuseMapOptions.Scale != (mgl32.Vec3{4, 4, 1}) || m.DiffuseMapOptions.Offset != (mgl32.Vec3{0.5, 0.5, 0}) {
+		t.Errorf("DiffuseMapOptions = %+v, want scale 4 and offset 0.5", m.DiffuseMapOptions)
+	}
+	if m.BumpMap != "brick_normal.png" {
+		t.Errorf("BumpMap = %q, want brick_normal.png", m.BumpMap)
+	}
+	if m.BumpMapOptions.BumpMultiplier != 0.5 || !m.BumpMapOptions.Clamp {
+		t.Errorf("BumpMapOptions = %+v, want a multiplier of 0.5, clamped", m.BumpMapOptions)
+	}
+	if m.SpecularMapOptions != DefaultTextureOptions() {
+		t.Errorf("SpecularMapOptions = %+v, want the defaults", m.SpecularMapOptions)
+	}
+}
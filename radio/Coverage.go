@@ -0,0 +1,183 @@
+package radio
+
+import (
+	"math"
+	"sort"
+
+	"github.com/WhoBrokeTheBuild/TelcomSim/network"
+	"github.com/WhoBrokeTheBuild/TelcomSim/terrain"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+const (
+	// MobileHeight is the height of a mobile antenna above the ground, in meters
+	MobileHeight = 1.5
+	// NoiseFloor is the thermal noise of a 200kHz channel plus the receiver's noise figure, in dBm
+	NoiseFloor = -113
+	// MinSignal is the weakest signal a mobile can receive, in dBm
+	MinSignal = -104
+	// MinSINR is the lowest signal to interference and noise ratio a call holds at, in dB
+	MinSINR = 0
+	// terrainSamples is the number of points along a path checked for obstacles
+	terrainSamples = 16
+)
+
+// Coverage is the signal of every Tower in each cell of a Map
+type Coverage struct {
+	Width    int
+	Depth    int
+	CellSize float32
+
+	// Towers are the Towers covering the Map, in order of ID
+	Towers []network.NodeID
+	// Received holds the signal in dBm of each Tower in each cell, in the order of Towers
+	Received [][]float32
+	// Server is the Tower with the strongest signal in each cell, 0 if none reaches it
+	Server []network.NodeID
+	// SINR is the signal to interference and noise ratio of the Server in each cell, in dB
+	SINR []float32
+}
+
+// NewCoverage computes the Coverage of the Towers of net over m. Every Tower
+// shares its frequency with every other, so each is interference to the rest.
+// Towers that are down transmit nothing and are left out.
+func NewCoverage(net *network.Network, m *terrain.Map, model PathLoss) *Coverage {
+	c := &Coverage{
+		Width:    m.Width,
+		Depth:    m.Depth,
+		CellSize: m.CellSize,
+		Towers:   []network.NodeID{},
+		Received: [][]float32{},
+		Server:   make([]network.NodeID, m.Width*m.Depth),
+		SINR:     make([]float32, m.Width*m.Depth),
+	}
+
+	towers := net.GetNodesOfType(network.Tower)
+	sort.Slice(towers, func(i, j int) bool { return towers[i].ID < towers[j].ID })
+	for _, t := range towers {
+		if t.Antenna == nil || t.Down {
+			continue
+		}
+		c.Towers = append(c.Towers, t.ID)
+		c.Received = append(c.Received, getReceived(t, m, model))
+	}
+
+	noise := dBmToMilliwatts(NoiseFloor)
+	for i := range c.Server {
+		best := -1
+		total := noise
+		for t, rx := range c.Received {
+			total += dBmToMilliwatts(float64(rx[i]))
+			if rx[i] >= MinSignal && (best < 0 || rx[i] > c.Received[best][i]) {
+				best = t
+			}
+		}
+		if best < 0 {
+			c.SINR[i] = float32(math.Inf(-1))
+			continue
+		}
+
+		signal := dBmToMilliwatts(float64(c.Received[best][i]))
+		c.Server[i] = c.Towers[best]
+		c.SINR[i] = float32(10 * math.Log10(signal/(total-signal)))
+	}
+
+	return c
+}
+
+// getReceived returns the signal of a Tower in each cell of m, in dBm
+func getReceived(t *network.Node, m *terrain.Map, model PathLoss) []float32 {
+	a := t.Antenna
+	// Heights on the Map are in kilometers, antennas in meters
+	ground := float64(m.GetHeight(t.Position)) * 1000
+	top := ground + float64(a.Height)
+
+	rx := make([]float32, 0, m.Width*m.Depth)
+	for z := 0; z < m.Depth; z++ {
+		for x := 0; x < m.Width; x++ {
+			pos := m.GetCellCenter(x, z)
+			d := pos.Sub(t.Position)
+			dist := float64(d.Len())
+
+			// Hata takes the height of the base above the mobile's ground, so towers on hills reach further
+			mobile := float64(m.GetCellHeight(x, z)) * 1000
+			loss := model.Loss(dist, float64(a.Frequency), top-mobile, MobileHeight)
+			loss += getObstruction(m, t.Position, pos, top, mobile+MobileHeight, float64(a.Frequency))
+
+			bearing := math.Atan2(float64(d[0]), -float64(d[1])) * 180 / math.Pi
+			rx = append(rx, float32(float64(a.Power)+a.GetGain(bearing)-loss))
+		}
+	}
+	return rx
+}
+
+// getObstruction returns the diffraction loss over the highest point of the
+// terrain between from and to, above the line between antennas at the given heights in meters
+func getObstruction(m *terrain.Map, from, to mgl32.Vec2, fromHeight, toHeight, freq float64) float64 {
+	dist := float64(to.Sub(from).Len())
+	if dist < float64(m.CellSize) {
+		return 0
+	}
+
+	worst := math.Inf(-1)
+	worstAt := 0.0
+	for i := 1; i < terrainSamples; i++ {
+		f := float64(i) / terrainSamples
+		p := from.Add(to.Sub(from).Mul(float32(f)))
+		sight := fromHeight + (toHeight-fromHeight)*f
+		h := float64(m.GetHeight(p))*1000 - sight
+		if h > worst {
+			worst = h
+			worstAt = f
+		}
+	}
+	return KnifeEdge(worst, dist*worstAt, dist*(1-worstAt), freq)
+}
+
+// GetCell returns the index of the cell containing pos, clamped to the Coverage
+func (c *Coverage) GetCell(pos mgl32.Vec2) int {
+	x := int(pos[0] / c.CellSize)
+	z := int(pos[1] / c.CellSize)
+	if x < 0 {
+		x = 0
+	} else if x >= c.Width {
+		x = c.Width - 1
+	}
+	if z < 0 {
+		z = 0
+	} else if z >= c.Depth {
+		z = c.Depth - 1
+	}
+	return z*c.Width + x
+}
+
+// GetServer returns the Tower serving pos, and its SINR in dB, or 0 if none reaches it
+func (c *Coverage) GetServer(pos mgl32.Vec2) (network.NodeID, float32) {
+	i := c.GetCell(pos)
+	return c.Server[i], c.SINR[i]
+}
+
+// GetSignal returns the signal of a Tower at pos in dBm, or -Inf if it isn't in the Coverage
+func (c *Coverage) GetSignal(tower network.NodeID, pos mgl32.Vec2) float32 {
+	for t, id := range c.Towers {
+		if id == tower {
+			return c.Received[t][c.GetCell(pos)]
+		}
+	}
+	return float32(math.Inf(-1))
+}
+
+// GetCoveredShare returns the share of cells where a call can be held
+func (c *Coverage) GetCoveredShare() float64 {
+	covered := 0
+	for i := range c.Server {
+		if c.Server[i] != 0 && c.SINR[i] >= MinSINR {
+			covered++
+		}
+	}
+	return float64(covered) / float64(len(c.Server))
+}
+
+func dBmToMilliwatts(dbm float64) float64 {
+	return math.Pow(10, dbm/10)
+}
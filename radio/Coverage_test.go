@@ -0,0 +1,107 @@
+package radio
+
+import (
+	"image"
+	"math"
+	"testing"
+
+	"github.com/WhoBrokeTheBuild/TelcomSim/network"
+	"github.com/WhoBrokeTheBuild/TelcomSim/terrain"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// newFlatMap returns a Map of 1km cells at sea level
+func newFlatMap(t *testing.T, width, depth int) *terrain.Map {
+	m, err := terrain.NewMap(image.NewGray(image.Rect(0, 0, width, depth)), nil, terrain.MapConfig{
+		CellSize:  1,
+		MaxHeight: 1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func TestNewCoverage(t *testing.T) {
+	m := newFlatMap(t, 20, 10)
+	net := network.NewNetwork()
+	west := net.AddTower("West", mgl32.Vec2{5, 5})
+	east := net.AddTower("East", mgl32.Vec2{15, 5})
+	down := net.AddTower("Down", mgl32.Vec2{10, 5})
+	down.Down = true
+
+	c := NewCoverage(net, m, FreeSpace{})
+	if len(c.Towers) != 2 || c.Towers[0] != west.ID || c.Towers[1] != east.ID {
+		t.Fatalf("Towers = %v, want %v and %v without the one down", c.Towers, west.ID, east.ID)
+	}
+
+	// Cells are served by the nearer tower, splitting between x of 9 and 10
+	tests := []struct {
+		pos    mgl32.Vec2
+		server network.NodeID
+	}{
+		{pos: mgl32.Vec2{0.5, 0.5}, server: west.ID},
+		{pos: mgl32.Vec2{5.5, 5.5}, server: west.ID},
+		{pos: mgl32.Vec2{9.5, 5.5}, server: west.ID},
+		{pos: mgl32.Vec2{10.5, 5.5}, server: east.ID},
+		{pos: mgl32.Vec2{19.5, 9.5}, server: east.ID},
+	}
+	for _, tt := range tests {
+		if server, _ := c.GetServer(tt.pos); server != tt.server {
+			t.Errorf("GetServer(%v) = %v, want %v", tt.pos, server, tt.server)
+		}
+	}
+
+	// The SINR is the server's signal over the other's and the noise
+	for _, pos := range []mgl32.Vec2{{5.5, 5.5}, {9.5, 5.5}, {2.5, 8.5}} {
+		_, sinr := c.GetServer(pos)
+		signal := dBmToMilliwatts(float64(c.GetSignal(west.ID, pos)))
+		other := dBmToMilliwatts(float64(c.GetSignal(east.ID, pos)))
+		want := 10 * math.Log10(signal/(other+dBmToMilliwatts(NoiseFloor)))
+		if math.Abs(float64(sinr)-want) > 0.01 {
+			t.Errorf("SINR at %v = %.2f, want %.2f", pos, sinr, want)
+		}
+	}
+
+	// Next to a tower the signal is the power plus the gain, less free-space loss
+	pos := mgl32.Vec2{5.5, 5.5}
+	want := 43 + 2 - FreeSpace{}.Loss(math.Sqrt(0.5), 900, 30, MobileHeight)
+	if got := c.GetSignal(west.ID, pos); math.Abs(float64(got)-want) > 0.01 {
+		t.Errorf("GetSignal(West, %v) = %.2f, want %.2f", pos, got, want)
+	}
+
+	// Flat terrain is symmetric, so the towers are equal at mirrored cells
+	_, a := c.GetServer(mgl32.Vec2{9.5, 5.5})
+	_, b := c.GetServer(mgl32.Vec2{10.5, 5.5})
+	if math.Abs(float64(a-b)) > 0.01 {
+		t.Errorf("SINR either side of the middle is %.2f and %.2f", a, b)
+	}
+	if a < MinSINR || a > 3 {
+		t.Errorf("SINR between the towers is %.2f, want just above %v", a, MinSINR)
+	}
+	if _, sinr := c.GetServer(mgl32.Vec2{5.5, 5.5}); sinr < 20 {
+		t.Errorf("SINR next to a tower is %.2f, want it well above the interference", sinr)
+	}
+	if share := c.GetCoveredShare(); share != 1 {
+		t.Errorf("GetCoveredShare() = %v, want every cell", share)
+	}
+}
+
+func TestNewCoverageOutOfRange(t *testing.T) {
+	m := newFlatMap(t, 40, 2)
+	net := network.NewNetwork()
+	net.AddTower("T", mgl32.Vec2{0.5, 0.5})
+
+	// The suburban Hata model falls below MinSignal well within 40km
+	c := NewCoverage(net, m, Hata{Environment: Suburban})
+	server, sinr := c.GetServer(mgl32.Vec2{39.5, 0.5})
+	if server != 0 || !math.IsInf(float64(sinr), -1) {
+		t.Errorf("GetServer() at the far edge = %v, %v, want nothing", server, sinr)
+	}
+	if server, _ := c.GetServer(mgl32.Vec2{1.5, 0.5}); server == 0 {
+		t.Errorf("cell next to the tower isn't served")
+	}
+	if share := c.GetCoveredShare(); share <= 0 || share >= 1 {
+		t.Errorf("GetCoveredShare() = %v, want part of the Map", share)
+	}
+}
@@ -0,0 +1,53 @@
+package radio
+
+// heatmapStops are the colors of the heatmap at increasing SINR, in dB
+var heatmapStops = []struct {
+	sinr  float32
+	color [4]uint8
+}{
+	{MinSINR - 6, [4]uint8{0, 0, 0, 0}},
+	{MinSINR, [4]uint8{220, 40, 20, 160}},
+	{MinSINR + 9, [4]uint8{240, 200, 40, 160}},
+	{MinSINR + 20, [4]uint8{40, 200, 60, 160}},
+	{MinSINR + 30, [4]uint8{40, 120, 240, 160}},
+}
+
+// GetHeatmap returns the SINR of each cell as RGBA pixels, row by row, fading
+// from blue where it is strongest through red to black where no call can be held
+func (c *Coverage) GetHeatmap() []uint8 {
+	pixels := make([]uint8, 0, len(c.SINR)*4)
+	for i, sinr := range c.SINR {
+		if c.Server[i] == 0 {
+			pixels = append(pixels, 0, 0, 0, 0)
+			continue
+		}
+		col := getHeatmapColor(sinr)
+		pixels = append(pixels, col[:]...)
+	}
+	return pixels
+}
+
+// getHeatmapColor interpolates between the heatmapStops around sinr
+func getHeatmapColor(sinr float32) [4]uint8 {
+	first, last := heatmapStops[0], heatmapStops[len(heatmapStops)-1]
+	if sinr <= first.sinr {
+		return first.color
+	}
+	if sinr >= last.sinr {
+		return last.color
+	}
+
+	for i := 1; i < len(heatmapStops); i++ {
+		a, b := heatmapStops[i-1], heatmapStops[i]
+		if sinr > b.sinr {
+			continue
+		}
+		t := (sinr - a.sinr) / (b.sinr - a.sinr)
+		col := [4]uint8{}
+		for j := range col {
+			col[j] = uint8(float32(a.color[j]) + (float32(b.color[j])-float32(a.color[j]))*t)
+		}
+		return col
+	}
+	return last.color
+}
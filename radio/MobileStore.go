@@ -0,0 +1,64 @@
+package radio
+
+import (
+	"github.com/WhoBrokeTheBuild/TelcomSim/ecs"
+)
+
+// MobileStore is a Store of Mobile components
+type MobileStore struct {
+	Set  ecs.SparseSet
+	Data []Mobile
+}
+
+// NewMobileStore returns a new empty MobileStore
+func NewMobileStore() *MobileStore {
+	return &MobileStore{
+		Data: []Mobile{},
+	}
+}
+
+// GetSet returns the Entities with a Mobile
+func (s *MobileStore) GetSet() *ecs.SparseSet {
+	return &s.Set
+}
+
+// Add sets the Mobile of e
+func (s *MobileStore) Add(e ecs.Entity, m Mobile) {
+	i, added := s.Set.Insert(e)
+	if added {
+		s.Data = append(s.Data, m)
+	} else {
+		s.Data[i] = m
+	}
+}
+
+// Get returns the Mobile of e, valid until the Store is next added to or removed from, or nil
+func (s *MobileStore) Get(e ecs.Entity) *Mobile {
+	if i := s.Set.IndexOf(e); i >= 0 {
+		return &s.Data[i]
+	}
+	return nil
+}
+
+// Remove removes the Mobile of e, returning false if it had none
+func (s *MobileStore) Remove(e ecs.Entity) bool {
+	i, ok := s.Set.Delete(e)
+	if !ok {
+		return false
+	}
+	last := len(s.Data) - 1
+	s.Data[i] = s.Data[last]
+	s.Data = s.Data[:last]
+	return true
+}
+
+// MarshalJSON writes the Entities of the Store and their Mobiles
+func (s *MobileStore) MarshalJSON() ([]byte, error) {
+	return ecs.MarshalStore(&s.Set, s.Data)
+}
+
+// UnmarshalJSON replaces the Store with one written by MarshalJSON
+func (s *MobileStore) UnmarshalJSON(b []byte) error {
+	s.Data = []Mobile{}
+	return ecs.UnmarshalStore(b, &s.Set, &s.Data)
+}
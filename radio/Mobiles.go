@@ -0,0 +1,206 @@
+package radio
+
+import (
+	"github.com/WhoBrokeTheBuild/TelcomSim/ecs"
+	"github.com/WhoBrokeTheBuild/TelcomSim/network"
+	"github.com/WhoBrokeTheBuild/TelcomSim/rng"
+	"github.com/WhoBrokeTheBuild/TelcomSim/terrain"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// Mobile is a subscriber travelling the Map with a phone, an Entity with a
+// position in the Positions of Mobiles
+type Mobile struct {
+	// Waypoint is where the Mobile is heading on the Map, in kilometers
+	Waypoint mgl32.Vec2
+	// Serving is the Tower the Mobile is on, 0 if it has no service
+	Serving network.NodeID
+	// Waiting is the stronger Tower a handover to was refused, so it is only counted once
+	Waiting network.NodeID `json:",omitempty"`
+}
+
+// HandoverStats counts what happened to the Mobiles
+type HandoverStats struct {
+	// Attached counts the times a Mobile without service found a Tower
+	Attached int64
+	// Handovers counts the times a Mobile moved to a stronger Tower
+	Handovers int64
+	// Failed counts the handovers refused because the stronger Tower had no free channel,
+	// once for each Tower a Mobile waits on
+	Failed int64
+	// Dropped counts the times a Mobile lost its Tower
+	Dropped int64
+}
+
+// Mobiles moves Mobiles between the populated parts of a Map and hands them
+// over between Towers. Each Mobile holds a channel of the Tower serving it.
+type Mobiles struct {
+	// Store and Positions are the Stores of the Mobile Entities
+	Store     *MobileStore   `json:"-"`
+	Positions *ecs.Vec2Store `json:"-"`
+
+	Rand *rng.Rand
+	// Speed is how far a Mobile travels in a simulated second, in kilometers
+	Speed float32
+	// Hysteresis is how much stronger in dB another Tower must be to hand over to it
+	Hysteresis float32
+
+	Stats HandoverStats
+}
+
+const (
+	// MobileStoreName is the name the MobileStore is registered under
+	MobileStoreName = "mobile"
+	// PositionStoreName is the name the positions of Mobiles are registered under
+	PositionStoreName = "mobile_position"
+
+	// DefaultMobileSpeed is the Speed of new Mobiles
+	DefaultMobileSpeed = 0.2
+	// DefaultHysteresis is the Hysteresis of new Mobiles
+	DefaultHysteresis = 3
+)
+
+// NewMobiles returns a new Mobiles with none spawned, registering its Stores with world
+func NewMobiles(world *ecs.World, seed int64) *Mobiles {
+	ms := &Mobiles{
+		Store:      NewMobileStore(),
+		Positions:  ecs.NewVec2Store(),
+		Rand:       rng.New(seed),
+		Speed:      DefaultMobileSpeed,
+		Hysteresis: DefaultHysteresis,
+	}
+
+	world.Register(MobileStoreName, ms.Store)
+	world.Register(PositionStoreName, ms.Positions)
+	return ms
+}
+
+// Spawn adds count Mobiles to world, placed where people live on m
+func (ms *Mobiles) Spawn(world *ecs.World, count int, m *terrain.Map) {
+	for i := 0; i < count; i++ {
+		e := world.Create()
+		ms.Positions.Add(e, ms.getDestination(m))
+		ms.Store.Add(e, Mobile{Waypoint: ms.getDestination(m)})
+	}
+}
+
+// GetCount returns the number of Mobiles
+func (ms *Mobiles) GetCount() int {
+	return ms.Store.Set.Len()
+}
+
+// getDestination returns a random point on m, where more people live being more likely
+func (ms *Mobiles) getDestination(m *terrain.Map) mgl32.Vec2 {
+	total := 0
+	for _, p := range m.Population {
+		total += int(p)
+	}
+
+	cell := 0
+	if total > 0 {
+		pick := ms.Rand.Intn(total)
+		for i, p := range m.Population {
+			pick -= int(p)
+			if pick < 0 {
+				cell = i
+				break
+			}
+		}
+	} else {
+		cell = ms.Rand.Intn(m.Width * m.Depth)
+	}
+
+	x, z := cell%m.Width, cell/m.Width
+	return mgl32.Vec2{
+		(float32(x) + float32(ms.Rand.Float64())) * m.CellSize,
+		(float32(z) + float32(ms.Rand.Float64())) * m.CellSize,
+	}
+}
+
+// Update moves the Mobiles for dt seconds, then attaches, hands over and drops
+// them by the Coverage. Without a Coverage, Mobiles only move.
+func (ms *Mobiles) Update(dt float64, m *terrain.Map, net *network.Network, cov *Coverage) {
+	step := ms.Speed * float32(dt)
+	entities := ms.Store.Set.GetEntities()
+	for i := range ms.Store.Data {
+		mob, pos := &ms.Store.Data[i], ms.Positions.Get(entities[i])
+		to := mob.Waypoint.Sub(*pos)
+		if to.Len() <= step {
+			*pos = mob.Waypoint
+			mob.Waypoint = ms.getDestination(m)
+		} else {
+			*pos = pos.Add(to.Normalize().Mul(step))
+		}
+	}
+
+	if cov == nil {
+		return
+	}
+
+	used := ms.getUsedChannels()
+	for i := range ms.Store.Data {
+		mob, pos := &ms.Store.Data[i], *ms.Positions.Get(entities[i])
+		best, sinr := cov.GetServer(pos)
+
+		if mob.Serving != 0 {
+			signal := cov.GetSignal(mob.Serving, pos)
+			if net.GetNode(mob.Serving) == nil || signal < MinSignal {
+				used[mob.Serving]--
+				mob.Serving = 0
+				ms.Stats.Dropped++
+				continue
+			}
+
+			if best == 0 || best == mob.Serving || cov.GetSignal(best, pos) < signal+ms.Hysteresis {
+				mob.Waiting = 0
+				continue
+			}
+			if !hasFreeChannel(net, best, used) {
+				if mob.Waiting != best {
+					mob.Waiting = best
+					ms.Stats.Failed++
+				}
+				continue
+			}
+			used[mob.Serving]--
+			used[best]++
+			mob.Serving = best
+			mob.Waiting = 0
+			ms.Stats.Handovers++
+			continue
+		}
+
+		if best != 0 && sinr >= MinSINR && hasFreeChannel(net, best, used) {
+			used[best]++
+			mob.Serving = best
+			ms.Stats.Attached++
+		}
+	}
+}
+
+// getUsedChannels returns the number of Mobiles on each Tower
+func (ms *Mobiles) getUsedChannels() map[network.NodeID]int {
+	used := map[network.NodeID]int{}
+	for _, mob := range ms.Store.Data {
+		if mob.Serving != 0 {
+			used[mob.Serving]++
+		}
+	}
+	return used
+}
+
+func hasFreeChannel(net *network.Network, tower network.NodeID, used map[network.NodeID]int) bool {
+	node := net.GetNode(tower)
+	return node != nil && used[tower] < node.Capacity
+}
+
+// GetServed returns the number of Mobiles with service
+func (ms *Mobiles) GetServed() int {
+	served := 0
+	for _, mob := range ms.Store.Data {
+		if mob.Serving != 0 {
+			served++
+		}
+	}
+	return served
+}
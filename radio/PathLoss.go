@@ -0,0 +1,126 @@
+package radio
+
+import (
+	"fmt"
+	"math"
+)
+
+// PathLoss is a model of how much a signal weakens over distance
+type PathLoss interface {
+	// Loss returns the loss in dB over dist kilometers at freq MHz, between a base
+	// station antenna and a mobile antenna at the given heights in meters
+	Loss(dist, freq, baseHeight, mobileHeight float64) float64
+
+	// GetName returns the name the PathLoss is created with by NewPathLoss
+	GetName() string
+}
+
+// PathLossNames are the names accepted by NewPathLoss
+var PathLossNames = []string{
+	"free_space",
+	"hata_urban",
+	"hata_suburban",
+	"hata_open",
+}
+
+// DefaultPathLoss is the name of the PathLoss used for coverage
+const DefaultPathLoss = "hata_suburban"
+
+// NewPathLoss returns the PathLoss of the given name
+func NewPathLoss(name string) (PathLoss, error) {
+	switch name {
+	case "free_space":
+		return FreeSpace{}, nil
+	case "hata_urban":
+		return Hata{Environment: Urban}, nil
+	case "hata_suburban":
+		return Hata{Environment: Suburban}, nil
+	case "hata_open":
+		return Hata{Environment: Open}, nil
+	}
+	return nil, fmt.Errorf("Unknown path loss model [%v]", name)
+}
+
+// minDistance keeps the models finite right under an antenna, in kilometers
+const minDistance = 0.01
+
+// FreeSpace is the loss of a signal with nothing in its way
+type FreeSpace struct{}
+
+// Loss returns the free-space path loss, which ignores the antenna heights
+func (FreeSpace) Loss(dist, freq, baseHeight, mobileHeight float64) float64 {
+	dist = math.Max(dist, minDistance)
+	return 20*math.Log10(dist) + 20*math.Log10(freq) + 32.44
+}
+
+// GetName returns "free_space"
+func (FreeSpace) GetName() string {
+	return "free_space"
+}
+
+// Environment is the kind of area a Hata model is fitted to
+type Environment int
+
+const (
+	// Urban is a large city
+	Urban Environment = iota
+	// Suburban is a town or the edge of a city
+	Suburban
+	// Open is farmland and open country
+	Open
+)
+
+// Hata is the Okumura-Hata model, fitted to measurements in cities and corrected
+// for suburban and open areas. It is valid from 150 to 1500MHz and 1 to 20km,
+// and is clamped to free-space loss where it would predict less.
+type Hata struct {
+	Environment Environment
+}
+
+// Loss returns the Okumura-Hata path loss
+func (h Hata) Loss(dist, freq, baseHeight, mobileHeight float64) float64 {
+	dist = math.Max(dist, minDistance)
+	baseHeight = math.Max(baseHeight, 1)
+	logf := math.Log10(freq)
+
+	// Mobile antenna correction for a small or medium city
+	a := (1.1*logf-0.7)*mobileHeight - (1.56*logf - 0.8)
+	loss := 69.55 + 26.16*logf - 13.82*math.Log10(baseHeight) - a +
+		(44.9-6.55*math.Log10(baseHeight))*math.Log10(dist)
+
+	switch h.Environment {
+	case Suburban:
+		loss -= 2*math.Pow(math.Log10(freq/28), 2) + 5.4
+	case Open:
+		loss -= 4.78*logf*logf - 18.33*logf + 40.94
+	}
+
+	return math.Max(loss, FreeSpace{}.Loss(dist, freq, baseHeight, mobileHeight))
+}
+
+// GetName returns the name of the Hata model for its Environment
+func (h Hata) GetName() string {
+	switch h.Environment {
+	case Urban:
+		return "hata_urban"
+	case Open:
+		return "hata_open"
+	}
+	return "hata_suburban"
+}
+
+// KnifeEdge returns the loss in dB of diffraction over an obstacle h meters above
+// the line of sight, d1 and d2 kilometers from either end, at freq MHz
+func KnifeEdge(h, d1, d2, freq float64) float64 {
+	if d1 <= 0 || d2 <= 0 {
+		return 0
+	}
+
+	// Fresnel-Kirchhoff parameter, with the wavelength in meters
+	lambda := 299.792458 / freq
+	v := h * math.Sqrt(2/lambda*(d1+d2)/(d1*d2*1000))
+	if v <= -0.78 {
+		return 0
+	}
+	return 6.9 + 20*math.Log10(math.Sqrt((v-0.1)*(v-0.1)+1)+v-0.1)
+}
@@ -0,0 +1,94 @@
+package radio
+
+import (
+	"math"
+	"testing"
+)
+
+func TestFreeSpace(t *testing.T) {
+	tests := []struct {
+		name string
+		dist float64
+		freq float64
+		want float64
+	}{
+		{name: "1km at 900MHz", dist: 1, freq: 900, want: 91.52},
+		{name: "10km at 900MHz", dist: 10, freq: 900, want: 111.52},
+		{name: "1km at 1800MHz", dist: 1, freq: 1800, want: 97.55},
+		{name: "under the antenna", dist: 0, freq: 900, want: 51.52},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := (FreeSpace{}).Loss(tt.dist, tt.freq, 30, MobileHeight); math.Abs(got-tt.want) > 0.01 {
+				t.Errorf("Loss() = %.2f, want %.2f", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHata(t *testing.T) {
+	tests := []struct {
+		name string
+		env  Environment
+		dist float64
+		freq float64
+		want float64
+	}{
+		{name: "urban", env: Urban, dist: 1, freq: 900, want: 126.40},
+		{name: "suburban", env: Suburban, dist: 1, freq: 900, want: 116.46},
+		{name: "open", env: Open, dist: 1, freq: 900, want: 97.89},
+		{name: "urban at 10km", env: Urban, dist: 10, freq: 900, want: 161.63},
+		// Open country this close predicts less than free space
+		{name: "clamped to free space", env: Open, dist: 0.01, freq: 900, want: 51.52},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := (Hata{Environment: tt.env}).Loss(tt.dist, tt.freq, 30, MobileHeight); math.Abs(got-tt.want) > 0.01 {
+				t.Errorf("Loss() = %.2f, want %.2f", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewPathLoss(t *testing.T) {
+	for _, name := range PathLossNames {
+		model, err := NewPathLoss(name)
+		if err != nil {
+			t.Errorf("NewPathLoss(%q) failed: %v", name, err)
+			continue
+		}
+		if model.GetName() != name {
+			t.Errorf("NewPathLoss(%q).GetName() = %q", name, model.GetName())
+		}
+	}
+	if _, err := NewPathLoss("hata"); err == nil {
+		t.Errorf("NewPathLoss accepted an unknown name")
+	}
+}
+
+func TestKnifeEdge(t *testing.T) {
+	// At 900MHz a 1km path split evenly has v = h * 0.155
+	tests := []struct {
+		name string
+		h    float64
+		d1   float64
+		d2   float64
+		want float64
+	}{
+		{name: "far below the line of sight", h: -20, d1: 0.5, d2: 0.5, want: 0},
+		{name: "at v of -0.78", h: -0.78 / 0.15497, d1: 0.5, d2: 0.5, want: 0},
+		{name: "grazing", h: 0, d1: 0.5, d2: 0.5, want: 6.03},
+		{name: "above the line of sight", h: 10, d1: 0.5, d2: 0.5, want: 17.03},
+		{name: "at an end", h: 10, d1: 0, d2: 1, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := KnifeEdge(tt.h, tt.d1, tt.d2, 900); math.Abs(got-tt.want) > 0.01 {
+				t.Errorf("KnifeEdge() = %.2f, want %.2f", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package replay
+
+import (
+	"encoding/json"
+
+	"github.com/WhoBrokeTheBuild/TelcomSim/sim"
+)
+
+// Recorder records the Commands applied to a World into a Replay
+type Recorder struct {
+	Replay *Replay
+
+	world     *sim.World
+	startTick uint64
+	// next is the World's OnCommand from before recording, still called for every Command
+	next func(sim.Command, error)
+}
+
+// NewRecorder returns a new Recorder starting from the current state of world,
+// which is updated every step simulated seconds
+func NewRecorder(world *sim.World, step float64) (*Recorder, error) {
+	// Pending Commands are recorded when they are applied, so they are left out
+	// of the start, or they would be applied twice when played
+	snapshot := *world
+	snapshot.Pending = nil
+	start, err := json.Marshal(&snapshot)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &Recorder{
+		Replay: &Replay{
+			Version:  Version,
+			Step:     step,
+			Start:    start,
+			Commands: []sim.Command{},
+		},
+		world:     world,
+		startTick: world.Tick,
+		next:      world.OnCommand,
+	}
+	world.OnCommand = r.record
+	return r, nil
+}
+
+// record adds a Command to the Replay, failed ones too as they fail the same way when replayed
+func (r *Recorder) record(c sim.Command, err error) {
+	r.Replay.Commands = append(r.Replay.Commands, c)
+	if r.next != nil {
+		r.next(c, err)
+	}
+}
+
+// Stop detaches the Recorder from its World and returns the Replay up to the current tick
+func (r *Recorder) Stop() *Replay {
+	r.world.OnCommand = r.next
+	r.Replay.Ticks = r.world.Tick - r.startTick
+	return r.Replay
+}
@@ -0,0 +1,80 @@
+package replay
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/WhoBrokeTheBuild/TelcomSim/sim"
+)
+
+// Version is the version of the replay format written by this build
+const Version = 1
+
+// Replay is a recorded game: the World it started from, and every Command
+// applied to it. Playing it back reproduces the game exactly.
+type Replay struct {
+	Version int
+	// Step is the simulated seconds per tick
+	Step float64
+	// Start is the World when recording started, as JSON
+	Start json.RawMessage
+	// Ticks is the number of ticks recorded
+	Ticks    uint64
+	Commands []sim.Command
+}
+
+// ReadFile reads a Replay from a file
+func ReadFile(filename string) (*Replay, error) {
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &Replay{}
+	err = json.Unmarshal(b, r)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read replay [%v]: %v", filename, err)
+	}
+	if r.Version != Version {
+		return nil, fmt.Errorf("Unsupported replay version [%v] in [%v]", r.Version, filename)
+	}
+	return r, nil
+}
+
+// WriteFile writes the Replay to a file
+func (r *Replay) WriteFile(filename string) error {
+	b, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filename, b, 0644)
+}
+
+// Play runs the Replay from its Start, applying each Command on the tick it was
+// recorded on, and returns the World at the end
+func (r *Replay) Play() (*sim.World, error) {
+	w := &sim.World{}
+	err := json.Unmarshal(r.Start, w)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to load replay start: %v", err)
+	}
+
+	end := w.Tick + r.Ticks
+	next := 0
+	for w.Tick < end {
+		for next < len(r.Commands) && r.Commands[next].Tick == w.Tick {
+			w.Queue(r.Commands[next])
+			next++
+		}
+		if next < len(r.Commands) && r.Commands[next].Tick < w.Tick {
+			return nil, fmt.Errorf("Replay command [%v] is out of order at tick [%v]", next, r.Commands[next].Tick)
+		}
+		w.Update(r.Step)
+	}
+
+	if next < len(r.Commands) {
+		return nil, fmt.Errorf("Replay has [%v] commands after its last tick", len(r.Commands)-next)
+	}
+	return w, nil
+}
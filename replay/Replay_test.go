@@ -0,0 +1,112 @@
+package replay
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/WhoBrokeTheBuild/TelcomSim/network"
+	"github.com/WhoBrokeTheBuild/TelcomSim/sim"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+const testStep = 1.0
+
+// newTestWorld returns a World of two exchanges with subscribers, placing calls
+func newTestWorld(t *testing.T) *sim.World {
+	w := sim.NewWorld(7)
+	net := w.Network
+	a := net.AddExchange("A", mgl32.Vec2{0, 0})
+	b := net.AddExchange("B", mgl32.Vec2{8, 0})
+	sa := net.AddSubscriber("SA", mgl32.Vec2{0, 1}, 400)
+	sb := net.AddSubscriber("SB", mgl32.Vec2{8, 1}, 300)
+	for _, ends := range [][2]*network.Node{{a, b}, {sa, a}, {sb, b}} {
+		if _, err := net.Connect(ends[0].ID, ends[1].ID, network.Fiber); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return w
+}
+
+func TestPlay(t *testing.T) {
+	tests := []struct {
+		name string
+		// before are queued before recording starts, during on the given tick of the recording
+		before []sim.Command
+		during map[int]sim.Command
+	}{
+		{
+			name: "no commands",
+		},
+		{
+			name: "commands while recording",
+			during: map[int]sim.Command{
+				10:  {Type: sim.BuildTower, Name: "T", Position: mgl32.Vec2{4, 2}},
+				50:  {Type: sim.SetTariff, PerMinute: 0.05, Subscription: 20},
+				51:  {Type: sim.RemoveLink, Link: 99},
+				200: {Type: sim.Borrow, Amount: 100000},
+			},
+		},
+		{
+			name: "commands pending when recording starts",
+			before: []sim.Command{
+				{Type: sim.BuildTower, Name: "T", Position: mgl32.Vec2{4, 2}},
+				{Type: sim.Borrow, Amount: 100000},
+			},
+			during: map[int]sim.Command{
+				50: {Type: sim.SetTariff, PerMinute: 0.05, Subscription: 20},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := newTestWorld(t)
+			for i := 0; i < 100; i++ {
+				w.Update(testStep)
+			}
+			for _, c := range tt.before {
+				w.Queue(c)
+			}
+
+			rec, err := NewRecorder(w, testStep)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i := 0; i < 2*30*sim.SecondsPerDay; i++ {
+				if c, found := tt.during[i]; found {
+					w.Queue(c)
+				}
+				w.Update(testStep)
+			}
+			r := rec.Stop()
+
+			// The Replay is played from a file, as it would be headless
+			filename := filepath.Join(t.TempDir(), "test.replay")
+			err = r.WriteFile(filename)
+			if err != nil {
+				t.Fatal(err)
+			}
+			r, err = ReadFile(filename)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			played, err := r.Play()
+			if err != nil {
+				t.Fatalf("Play failed [%v]", err)
+			}
+			got, err := played.GetHashes()
+			if err != nil {
+				t.Fatal(err)
+			}
+			want, err := w.GetHashes()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("played hashes are\n%v\nwant\n%v", got, want)
+			}
+		})
+	}
+}
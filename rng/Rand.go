@@ -0,0 +1,43 @@
+package rng
+
+import (
+	"math"
+)
+
+// Rand is a small deterministic random number generator (SplitMix64). Its whole
+// state is the exported State, so it can be saved and restored exactly.
+type Rand struct {
+	State uint64
+}
+
+// New returns a new Rand seeded with seed
+func New(seed int64) *Rand {
+	return &Rand{State: uint64(seed)}
+}
+
+// Uint64 returns a uniformly distributed 64 bit value
+func (r *Rand) Uint64() uint64 {
+	r.State += 0x9E3779B97F4A7C15
+	z := r.State
+	z = (z ^ (z >> 30)) * 0xBF58476D1CE4E5B9
+	z = (z ^ (z >> 27)) * 0x94D049BB133111EB
+	return z ^ (z >> 31)
+}
+
+// Float64 returns a uniformly distributed value in [0, 1)
+func (r *Rand) Float64() float64 {
+	return float64(r.Uint64()>>11) / (1 << 53)
+}
+
+// Intn returns a uniformly distributed value in [0, n), n must be positive
+func (r *Rand) Intn(n int) int {
+	if n <= 0 {
+		panic("rng: Intn called with n <= 0")
+	}
+	return int(r.Uint64() % uint64(n))
+}
+
+// ExpFloat64 returns an exponentially distributed value with a mean of 1
+func (r *Rand) ExpFloat64() float64 {
+	return -math.Log(1 - r.Float64())
+}
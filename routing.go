@@ -0,0 +1,210 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+
+	"github.com/WhoBrokeTheBuild/TelcomSim/context"
+	"github.com/WhoBrokeTheBuild/TelcomSim/network"
+	"github.com/WhoBrokeTheBuild/TelcomSim/sim"
+	"github.com/WhoBrokeTheBuild/TelcomSim/traffic"
+	"github.com/WhoBrokeTheBuild/TelcomSim/ui"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+var (
+	routingColor         = color.RGBA{40, 80, 160, 230}
+	routingSelectedColor = color.RGBA{170, 130, 20, 230}
+)
+
+// routingOrigin is where the first exchange is shown, routingCell the space each takes
+var (
+	routingOrigin = mgl32.Vec2{20, 50}
+	routingCell   = mgl32.Vec2{330, 34}
+)
+
+// routingRows is how many exchanges are shown in each column
+const routingRows = 18
+
+// routingPanel shows the routing policy of each exchange and tower of a World,
+// with the share of the calls from it that are blocked. Clicking an exchange
+// selects it, clicking it again or pressing next_policy switches it to the next
+// policy in traffic.RouterNames.
+type routingPanel struct {
+	World *sim.World
+	Shown bool
+
+	Buttons map[network.NodeID]*ui.Button
+	Info    *ui.Text
+
+	nodes    []network.NodeID
+	selected network.NodeID
+}
+
+// newRoutingPanel returns a hidden routingPanel of world
+func newRoutingPanel(world *sim.World) *routingPanel {
+	return &routingPanel{
+		World:   world,
+		Buttons: map[network.NodeID]*ui.Button{},
+	}
+}
+
+// Delete frees all resources owned by the routingPanel
+func (c *routingPanel) Delete() {
+	for _, b := range c.Buttons {
+		b.Delete()
+	}
+	c.Buttons = map[network.NodeID]*ui.Button{}
+	c.nodes = nil
+	if c.Info != nil {
+		c.Info.Delete()
+		c.Info = nil
+	}
+}
+
+// getExchanges returns the Nodes calls are routed from, in the order they were built
+func (c *routingPanel) getExchanges() []network.NodeID {
+	ids := []network.NodeID{}
+	for _, node := range c.World.Network.GetNodes() {
+		if node.Type != network.Subscriber {
+			ids = append(ids, node.ID)
+		}
+	}
+	return ids
+}
+
+// build creates a Button for each exchange and tower of the World
+func (c *routingPanel) build(ids []network.NodeID) {
+	selected := c.selected
+	c.Delete()
+	c.nodes = ids
+	c.selected = 0
+
+	for i, id := range ids {
+		id := id
+		b := ui.NewButton(" ", "ui/default.ttf", 16.0, color.White, routingColor)
+		if b == nil {
+			continue
+		}
+		col, row := i/routingRows, i%routingRows
+		b.SetPosition(routingOrigin.Add(mgl32.Vec2{float32(col) * routingCell.X(), float32(row) * routingCell.Y()}))
+		b.SetSize(mgl32.Vec2{routingCell.X() - 10, b.GetSize().Y()})
+		b.OnClick = func() { c.click(id) }
+		c.Buttons[id] = b
+		if id == selected {
+			c.selected = id
+		}
+	}
+
+	// Text can't render an empty string
+	c.Info = ui.NewText(" ", "ui/default.ttf", 16.0, color.White)
+	if c.Info != nil {
+		c.Info.SetPosition(mgl32.Vec2{routingOrigin.X(), float32(windowHeight) - 60})
+	}
+}
+
+// click selects an exchange, or switches its policy if it is already selected
+func (c *routingPanel) click(id network.NodeID) {
+	if c.selected == id {
+		c.nextPolicy()
+		return
+	}
+	c.selected = id
+}
+
+// nextPolicy switches the selected exchange to the policy after its current one
+func (c *routingPanel) nextPolicy() {
+	if c.World.Network.GetNode(c.selected) == nil {
+		return
+	}
+	c.World.Queue(sim.Command{Type: sim.SetPolicy, Node: c.selected, Policy: getNextPolicy(c.getPolicy(c.selected))})
+}
+
+// getPolicy returns the name of the routing policy of an exchange
+func (c *routingPanel) getPolicy(id network.NodeID) string {
+	return c.World.Traffic.GetPolicy(id).GetName()
+}
+
+// getNextPolicy returns the policy after name in traffic.RouterNames
+func getNextPolicy(name string) string {
+	for i, n := range traffic.RouterNames {
+		if n == name {
+			return traffic.RouterNames[(i+1)%len(traffic.RouterNames)]
+		}
+	}
+	return traffic.RouterNames[0]
+}
+
+// Update handles clicks and keys while the routingPanel is shown, and keeps it up to date with the World
+func (c *routingPanel) Update(ctx *context.Update) {
+	if !c.Shown {
+		return
+	}
+	if ids := c.getExchanges(); !isSameNodes(ids, c.nodes) {
+		c.build(ids)
+	}
+
+	if ctx.Input.IsActionPressed("next_policy") {
+		c.nextPolicy()
+	}
+
+	for _, id := range c.nodes {
+		b, found := c.Buttons[id]
+		if !found {
+			continue
+		}
+		stats := c.World.Traffic.GetExchangeStats(id)
+		b.SetText(fmt.Sprintf("%v  %v  %.1f%% blocked", c.World.Network.GetNode(id).Name, c.getPolicy(id), stats.GetBlockingRate()*100))
+		if id == c.selected {
+			b.SetColor(routingSelectedColor)
+		} else {
+			b.SetColor(routingColor)
+		}
+		b.Update(ctx)
+	}
+
+	if c.Info != nil {
+		if text := c.describe(c.selected); text != c.Info.Text {
+			c.Info.SetText(text)
+		}
+	}
+}
+
+// describe returns the calls routed from an exchange and how to change its policy
+func (c *routingPanel) describe(id network.NodeID) string {
+	node := c.World.Network.GetNode(id)
+	if node == nil {
+		return "Click an exchange to select it"
+	}
+	stats := c.World.Traffic.GetExchangeStats(id)
+	return fmt.Sprintf("%v routes by %v. %d calls, %d blocked, %d crankbacks. Click again for the next policy",
+		node.Name, c.getPolicy(id), stats.Attempted, stats.Blocked, stats.Crankbacks)
+}
+
+// Draw renders the routingPanel, if it is shown
+func (c *routingPanel) Draw(ctx *context.Render) {
+	if !c.Shown {
+		return
+	}
+	for _, id := range c.nodes {
+		if b, found := c.Buttons[id]; found {
+			b.Draw(ctx)
+		}
+	}
+	if c.Info != nil {
+		c.Info.Draw(ctx)
+	}
+}
+
+// isSameNodes returns whether a and b hold the same Nodes in the same order
+func isSameNodes(a, b []network.NodeID) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,145 @@
+// Package save reads and writes saved games.
+//
+// A save file is gzip compressed JSON of a single object:
+//
+//	{
+//	    "Magic": "TELCOMSIM",
+//	    "Version": 2,
+//	    "Created": "1990-01-01T00:00:00Z",
+//	    "Game": { ... }
+//	}
+//
+// Game holds a Game, whose layout depends on Version. When the layout changes,
+// Version is incremented and a Migration is added that rewrites a Game of the
+// previous version, so older files still load.
+//
+// Version 1:
+//   - World is a sim.World: the Calendar, Network, traffic Engine (including its
+//     random number generator and calls in progress) and Economy, the Map the
+//     Network is built on, the Mobiles listed with their positions and the
+//     PathLoss of the radio coverage, the Entities holding the Stores of the
+//     Systems, the Maintenance of the equipment and the Research of Techs
+//   - Tick is the number of simulation ticks run
+//   - Camera is the position of the camera and its controller
+//   - Nodes are the transforms of the named scene nodes
+//   - Scenario is the progress through the scenario played, if any
+//
+// Version 2:
+//   - Mobiles are Entities of the World, with Stores for them and their positions
+package save
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+const (
+	// Magic identifies a save file
+	Magic = "TELCOMSIM"
+	// Version is the version of the Game layout written by this build
+	Version = 2
+)
+
+// header is the outermost object of a save file
+type header struct {
+	Magic   string
+	Version int
+	Created time.Time
+	Game    json.RawMessage
+}
+
+// Write writes game to w in the current Version
+func Write(w io.Writer, game *Game) error {
+	b, err := json.Marshal(game)
+	if err != nil {
+		return err
+	}
+
+	zw := gzip.NewWriter(w)
+	err = json.NewEncoder(zw).Encode(header{
+		Magic:   Magic,
+		Version: Version,
+		Created: time.Now().UTC(),
+		Game:    b,
+	})
+	if err != nil {
+		zw.Close()
+		return err
+	}
+	return zw.Close()
+}
+
+// Read reads a Game from r, migrating it from older versions
+func Read(r io.Reader) (*Game, error) {
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("Not a save file: %v", err)
+	}
+	defer zr.Close()
+
+	h := header{}
+	err = json.NewDecoder(zr).Decode(&h)
+	if err != nil {
+		return nil, err
+	}
+	if h.Magic != Magic {
+		return nil, fmt.Errorf("Not a save file, bad magic [%v]", h.Magic)
+	}
+	if h.Version > Version {
+		return nil, fmt.Errorf("Save file version [%v] is newer than the supported version [%v]", h.Version, Version)
+	}
+
+	raw, err := migrate(h.Game, h.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	game := &Game{}
+	err = json.Unmarshal(raw, game)
+	if err != nil {
+		return nil, err
+	}
+	return game, nil
+}
+
+// WriteFile writes game to a file, replacing it only once the whole file is written
+func WriteFile(filename string, game *Game) error {
+	tmp := filename + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+
+	err = Write(f, game)
+	if err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return fmt.Errorf("Failed to write save [%v]: %v", filename, err)
+	}
+
+	err = f.Close()
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, filename)
+}
+
+// ReadFile reads a Game from a file
+func ReadFile(filename string) (*Game, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	game, err := Read(f)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read save [%v]: %v", filename, err)
+	}
+	return game, nil
+}
@@ -0,0 +1,316 @@
+package save
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"image"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/WhoBrokeTheBuild/TelcomSim/network"
+	"github.com/WhoBrokeTheBuild/TelcomSim/radio"
+	"github.com/WhoBrokeTheBuild/TelcomSim/sim"
+	"github.com/WhoBrokeTheBuild/TelcomSim/terrain"
+	"github.com/WhoBrokeTheBuild/TelcomSim/traffic"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// newTestGame returns a Game whose World has run for two game months, with
+// calls in progress and a Command pending
+func newTestGame(t *testing.T) *Game {
+	w := sim.NewWorld(42)
+	net := w.Network
+	a := net.AddExchange("A", mgl32.Vec2{0, 0})
+	b := net.AddExchange("B", mgl32.Vec2{8, 0})
+	sa := net.AddSubscriber("SA", mgl32.Vec2{0, 1}, 400)
+	sb := net.AddSubscriber("SB", mgl32.Vec2{8, 1}, 300)
+	sa.Demand, sb.Demand = 600, 500
+	for _, ends := range [][2]*network.Node{{a, b}, {sa, a}, {sb, b}} {
+		if _, err := net.Connect(ends[0].ID, ends[1].ID, network.Fiber); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := w.Maintenance.Hire(w.Entities, a.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	w.Queue(sim.Command{Type: sim.BuildTower, Name: "T", Position: mgl32.Vec2{4, 2}})
+	w.Queue(sim.Command{Type: sim.Borrow, Amount: 100000})
+	for i := 0; i < 2*30*sim.SecondsPerDay; i++ {
+		w.Update(1)
+	}
+	w.Queue(sim.Command{Type: sim.SetTariff, PerMinute: 0.05, Subscription: 20})
+
+	return &Game{
+		World: w,
+		Tick:  w.Tick,
+		Camera: Camera{
+			Controller: "orbit",
+			Position:   mgl32.Vec3{1, 2, 3},
+			Target:     mgl32.Vec3{4, 0, 4},
+			Distance:   12,
+			Yaw:        0.5,
+			Pitch:      -0.25,
+		},
+		Nodes: []Node{
+			{
+				Name:        "Terrain",
+				Translation: mgl32.Vec3{-8, 0, -8},
+				Rotation:    mgl32.QuatRotate(0.3, mgl32.Vec3{0, 1, 0}),
+				Scale:       mgl32.Vec3{1, 2, 1},
+			},
+		},
+	}
+}
+
+// checkSameGame fails t unless got holds the same state as want, and both
+// Worlds continue identically
+func checkSameGame(t *testing.T, got, want *Game) {
+	if got.Tick != want.Tick {
+		t.Errorf("Tick = %d, want %d", got.Tick, want.Tick)
+	}
+	if got.Camera != want.Camera {
+		t.Errorf("Camera = %+v, want %+v", got.Camera, want.Camera)
+	}
+	if !reflect.DeepEqual(got.Nodes, want.Nodes) {
+		t.Errorf("Nodes = %+v, want %+v", got.Nodes, want.Nodes)
+	}
+
+	// The random number generators must be restored too, to stay identical
+	for i := 0; i < 3; i++ {
+		gh, err := got.World.GetHashes()
+		if err != nil {
+			t.Fatal(err)
+		}
+		wh, err := want.World.GetHashes()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(gh, wh) {
+			t.Fatalf("after %d more ticks, hashes are\n%v\nwant\n%v", i*100, gh, wh)
+		}
+		for j := 0; j < 100; j++ {
+			got.World.Update(1)
+			want.World.Update(1)
+		}
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	game := newTestGame(t)
+	buf := &bytes.Buffer{}
+	err := Write(buf, game)
+	if err != nil {
+		t.Fatalf("Write failed [%v]", err)
+	}
+
+	loaded, err := Read(buf)
+	if err != nil {
+		t.Fatalf("Read failed [%v]", err)
+	}
+	checkSameGame(t, loaded, game)
+}
+
+func TestReadInvalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		header header
+	}{
+		{"bad magic", header{Magic: "NOTASAVE", Version: Version, Game: json.RawMessage("{}")}},
+		{"newer version", header{Magic: Magic, Version: Version + 1, Game: json.RawMessage("{}")}},
+		{"no migration", header{Magic: Magic, Version: -1, Game: json.RawMessage("{}")}},
+	}
+
+	for _, tt := range tests {
+		if _, err := Read(writeHeader(t, tt.header)); err == nil {
+			t.Errorf("%v: Read succeeded, want an error", tt.name)
+		}
+	}
+	if _, err := Read(bytes.NewBufferString("{}")); err == nil {
+		t.Errorf("Read of uncompressed JSON succeeded, want an error")
+	}
+}
+
+// TestMigration reads a Game from a version before the current one, that
+// called Tick "Ticks"
+func TestMigration(t *testing.T) {
+	const old = Version - 1
+	defer func(m Migration) { Migrations[old] = m }(Migrations[old])
+	Migrations[old] = func(game map[string]interface{}) error {
+		game["Tick"] = game["Ticks"]
+		delete(game, "Ticks")
+		return nil
+	}
+
+	game := newTestGame(t)
+	raw, err := json.Marshal(game)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fields := map[string]json.RawMessage{}
+	err = json.Unmarshal(raw, &fields)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fields["Ticks"] = fields["Tick"]
+	delete(fields, "Tick")
+	raw, err = json.Marshal(fields)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := Read(writeHeader(t, header{Magic: Magic, Version: old, Game: raw}))
+	if err != nil {
+		t.Fatalf("Read failed [%v]", err)
+	}
+	checkSameGame(t, loaded, game)
+}
+
+// TestMigrateMobiles reads a version 1 Game, whose Mobiles were a list holding
+// their positions rather than Entities
+func TestMigrateMobiles(t *testing.T) {
+	heights := image.NewGray(image.Rect(0, 0, 8, 8))
+	population := image.NewGray(image.Rect(0, 0, 8, 8))
+	for i := range population.Pix {
+		population.Pix[i] = 255
+	}
+	m, err := terrain.NewMap(heights, population, terrain.MapConfig{CellSize: 1, MaxPopulation: 40})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w := sim.NewWorld(7)
+	w.SetMap(m, traffic.DefaultSpawnConfig)
+	a := w.Network.AddExchange("A", mgl32.Vec2{4, 4})
+	for _, pos := range []mgl32.Vec2{{2, 2}, {6, 6}} {
+		tower := w.Network.AddTower("T", pos)
+		if _, err := w.Network.Connect(tower.ID, a.ID, network.Fiber); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i := 0; i < 600; i++ {
+		w.Update(1)
+	}
+	if w.Mobiles.GetServed() == 0 {
+		t.Fatalf("no Mobiles are served to be migrated")
+	}
+	game := &Game{World: w, Tick: w.Tick}
+
+	raw, err := json.Marshal(game)
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, err = json.Marshal(toVersion1(t, raw))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := Read(writeHeader(t, header{Magic: Magic, Version: 1, Game: raw}))
+	if err != nil {
+		t.Fatalf("Read failed [%v]", err)
+	}
+	if got := loaded.World.Mobiles.GetCount(); got != w.Mobiles.GetCount() {
+		t.Fatalf("%d Mobiles migrated, want %d", got, w.Mobiles.GetCount())
+	}
+	checkSameGame(t, loaded, game)
+}
+
+// toVersion1 rewrites the JSON of a Game whose only Entities are Mobiles to
+// version 1, listing the Mobiles with their positions
+func toVersion1(t *testing.T, raw []byte) map[string]interface{} {
+	var game map[string]interface{}
+	d := json.NewDecoder(bytes.NewReader(raw))
+	d.UseNumber()
+	err := d.Decode(&game)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	world := game["World"].(map[string]interface{})
+	entities := world["Entities"].(map[string]interface{})
+	stores := entities["Stores"].(map[string]interface{})
+	mobiles := stores[radio.MobileStoreName].(map[string]interface{})
+	positions := stores[radio.PositionStoreName].(map[string]interface{})
+
+	list := []interface{}{}
+	for i, mob := range mobiles["Data"].([]interface{}) {
+		mob := mob.(map[string]interface{})
+		mob["ID"] = i + 1
+		mob["Position"] = positions["Data"].([]interface{})[i]
+		list = append(list, mob)
+	}
+	world["Mobiles"].(map[string]interface{})["Mobiles"] = list
+
+	delete(stores, radio.MobileStoreName)
+	delete(stores, radio.PositionStoreName)
+	entities["Entities"] = []interface{}{}
+	entities["Next"] = 1
+	return game
+}
+
+// writeHeader returns h as a save file
+func writeHeader(t *testing.T, h header) *bytes.Buffer {
+	buf := &bytes.Buffer{}
+	zw := gzip.NewWriter(buf)
+	err := json.NewEncoder(zw).Encode(h)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = zw.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return buf
+}
+
+func TestAutosave(t *testing.T) {
+	// Saves go under the user config directory, which is moved to a temporary one
+	dir := t.TempDir()
+	for _, name := range []string{"XDG_CONFIG_HOME", "HOME", "AppData"} {
+		old, found := os.LookupEnv(name)
+		os.Setenv(name, dir)
+		if found {
+			defer os.Setenv(name, old)
+		} else {
+			defer os.Unsetenv(name)
+		}
+	}
+
+	game := newTestGame(t)
+	want := []string{"autosave1", "autosave2", "autosave3", "autosave1", "autosave2", "autosave3", "autosave1"}
+	modified := time.Now().Add(-time.Hour)
+	for i, name := range want {
+		s, err := Autosave(game)
+		if err != nil {
+			t.Fatalf("Autosave %d failed [%v]", i, err)
+		}
+		if s.Name != name {
+			t.Errorf("Autosave %d wrote [%v], want [%v]", i, s.Name, name)
+		}
+
+		// File times may be too coarse to order saves made this quickly
+		modified = modified.Add(time.Minute)
+		err = os.Chtimes(s.Filename, modified, modified)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, name := range want[:AutosaveSlots] {
+		s, err := GetSlot(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !s.Exists() {
+			t.Fatalf("slot [%v] wasn't saved to", name)
+		}
+		loaded, err := s.Load()
+		if err != nil {
+			t.Fatalf("Load of [%v] failed [%v]", name, err)
+		}
+		checkSameGame(t, loaded, newTestGame(t))
+	}
+}
@@ -0,0 +1,38 @@
+package save
+
+import (
+	"github.com/WhoBrokeTheBuild/TelcomSim/scenario"
+	"github.com/WhoBrokeTheBuild/TelcomSim/sim"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// Game is everything a save file holds
+type Game struct {
+	World  *sim.World
+	Tick   uint64
+	Camera Camera
+	Nodes  []Node
+	// Scenario is the progress through the scenario played, if any
+	Scenario *scenario.Run `json:",omitempty"`
+}
+
+// Camera is the saved state of a camera and its controller
+type Camera struct {
+	// Controller is "orbit", "fly", "top_down" or empty for none
+	Controller string
+	Position   mgl32.Vec3
+	// Target is the point the controller looks at, X and Z for "top_down"
+	Target   mgl32.Vec3
+	Distance float32
+	Height   float32
+	Yaw      float32
+	Pitch    float32
+}
+
+// Node is the saved transform of a scene node
+type Node struct {
+	Name        string
+	Translation mgl32.Vec3
+	Rotation    mgl32.Quat
+	Scale       mgl32.Vec3
+}
@@ -0,0 +1,104 @@
+package save
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/WhoBrokeTheBuild/TelcomSim/radio"
+)
+
+// Migration rewrites the Game of a save file from one version to the next, as
+// generic JSON so it doesn't depend on the types of either version. Numbers are
+// json.Numbers, so random number generator states keep every bit.
+type Migration func(game map[string]interface{}) error
+
+// Migrations holds the Migration from each version to the one after it
+var Migrations = map[int]Migration{
+	1: migrateMobiles,
+}
+
+// migrate applies every Migration from version up to the current Version
+func migrate(raw json.RawMessage, version int) (json.RawMessage, error) {
+	if version == Version {
+		return raw, nil
+	}
+
+	game := map[string]interface{}{}
+	d := json.NewDecoder(bytes.NewReader(raw))
+	d.UseNumber()
+	err := d.Decode(&game)
+	if err != nil {
+		return nil, err
+	}
+
+	for v := version; v < Version; v++ {
+		m, found := Migrations[v]
+		if !found {
+			return nil, fmt.Errorf("No migration from save version [%v]", v)
+		}
+		err = m(game)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to migrate save from version [%v]: %v", v, err)
+		}
+	}
+
+	return json.Marshal(game)
+}
+
+// migrateMobiles moves the Mobiles of a version 1 World, held in a list with
+// their positions, into Entities of the World with a Store each for the Mobiles
+// and their positions
+func migrateMobiles(game map[string]interface{}) error {
+	world, _ := game["World"].(map[string]interface{})
+	mobiles, _ := world["Mobiles"].(map[string]interface{})
+	if mobiles == nil {
+		return nil
+	}
+	list, _ := mobiles["Mobiles"].([]interface{})
+	delete(mobiles, "Mobiles")
+
+	entities, _ := world["Entities"].(map[string]interface{})
+	if entities == nil {
+		entities = map[string]interface{}{}
+		world["Entities"] = entities
+	}
+	next := int64(1)
+	if n, found := entities["Next"].(json.Number); found {
+		var err error
+		next, err = n.Int64()
+		if err != nil {
+			return err
+		}
+	}
+	all, _ := entities["Entities"].([]interface{})
+	stores, _ := entities["Stores"].(map[string]interface{})
+	if stores == nil {
+		stores = map[string]interface{}{}
+	}
+
+	ids := []interface{}{}
+	data := []interface{}{}
+	positions := []interface{}{}
+	for _, v := range list {
+		mob, ok := v.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("Mobile is not an object")
+		}
+		ids = append(ids, next)
+		all = append(all, next)
+		next++
+
+		positions = append(positions, mob["Position"])
+		delete(mob, "Position")
+		delete(mob, "ID")
+		data = append(data, mob)
+	}
+
+	stores[radio.MobileStoreName] = map[string]interface{}{"Entities": ids, "Data": data}
+	stores[radio.PositionStoreName] = map[string]interface{}{"Entities": ids, "Data": positions}
+	entities["Next"] = next
+	entities["Entities"] = all
+	entities["Stores"] = stores
+	return nil
+}
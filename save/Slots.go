@@ -0,0 +1,93 @@
+package save
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// AutosaveSlots is the number of autosaves kept, the oldest being replaced
+const AutosaveSlots = 3
+
+// Quicksave is the name of the quicksave slot
+const Quicksave = "quicksave"
+
+// Slot is a save file in the save directory
+type Slot struct {
+	Name     string
+	Filename string
+	Modified time.Time
+}
+
+// GetDir returns the directory saves are kept in, creating it if needed
+func GetDir() (string, error) {
+	base, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	dir := filepath.Join(base, "TelcomSim", "saves")
+	err = os.MkdirAll(dir, 0755)
+	if err != nil {
+		return "", err
+	}
+	return dir, nil
+}
+
+// GetSlot returns the Slot with the given name, which may not exist yet
+func GetSlot(name string) (*Slot, error) {
+	dir, err := GetDir()
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Slot{
+		Name:     name,
+		Filename: filepath.Join(dir, name+".sav"),
+	}
+	if info, err := os.Stat(s.Filename); err == nil {
+		s.Modified = info.ModTime()
+	}
+	return s, nil
+}
+
+// Exists returns whether the Slot has been saved to
+func (s *Slot) Exists() bool {
+	return !s.Modified.IsZero()
+}
+
+// Save writes game to the Slot
+func (s *Slot) Save(game *Game) error {
+	err := WriteFile(s.Filename, game)
+	if err != nil {
+		return err
+	}
+	s.Modified = time.Now()
+	return nil
+}
+
+// Load reads the Game in the Slot
+func (s *Slot) Load() (*Game, error) {
+	return ReadFile(s.Filename)
+}
+
+// Autosave writes game to the autosave slot that is empty or was saved longest ago
+func Autosave(game *Game) (*Slot, error) {
+	var oldest *Slot
+	for i := 1; i <= AutosaveSlots; i++ {
+		s, err := GetSlot(fmt.Sprintf("autosave%d", i))
+		if err != nil {
+			return nil, err
+		}
+		if !s.Exists() {
+			oldest = s
+			break
+		}
+		if oldest == nil || s.Modified.Before(oldest.Modified) {
+			oldest = s
+		}
+	}
+
+	return oldest, oldest.Save(game)
+}
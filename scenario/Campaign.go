@@ -0,0 +1,27 @@
+package scenario
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Campaign is a series of Scenarios, each played once the one before it is won
+type Campaign struct {
+	Name        string
+	Description string
+	// Scenarios are the names of the Scenarios, in the order they are played
+	Scenarios []string
+}
+
+// ParseCampaign reads a Campaign from JSON
+func ParseCampaign(b []byte) (*Campaign, error) {
+	c := &Campaign{}
+	err := json.Unmarshal(b, c)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse campaign [%v]", err)
+	}
+	if len(c.Scenarios) == 0 {
+		return nil, fmt.Errorf("Campaign [%v] has no scenarios", c.Name)
+	}
+	return c, nil
+}
@@ -0,0 +1,156 @@
+package scenario
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/WhoBrokeTheBuild/TelcomSim/network"
+	"github.com/WhoBrokeTheBuild/TelcomSim/sim"
+)
+
+// Condition holds when a metric of the World is within Min and Max, either of
+// which may be unset
+type Condition struct {
+	// Metric is the name of what is tested, like "served", "blocking" or "balance"
+	Metric string
+	Min    *float64 `json:",omitempty"`
+	Max    *float64 `json:",omitempty"`
+	// Tech is the Tech the "tech" Metric is 1 for once known, 0 before
+	Tech string `json:",omitempty"`
+}
+
+// Objective is met when all its Conditions hold at once. Every Objective must
+// be met to win a Scenario, unless it is a Lose Objective, which loses the
+// Scenario when met. A Lose Objective with a By is survived, counting as met,
+// once By has passed, and one without a By doesn't count toward winning.
+type Objective struct {
+	Description string
+	Conditions  []Condition
+	// By is the year an Objective must be met by, failing once it has passed,
+	// or a Lose Objective survived until, 0 for no deadline
+	By   int  `json:",omitempty"`
+	Lose bool `json:",omitempty"`
+}
+
+// metric returns the value of a Metric in the World, with the blocking rate of
+// the last whole month from the Run
+type metric func(w *sim.World, c Condition, r *Run) float64
+
+// metrics holds every metric a Condition can test, by name
+var metrics = map[string]metric{
+	// served is the share of the demand of the Map in blocks connected to an Exchange
+	"served": func(w *sim.World, c Condition, r *Run) float64 {
+		return GetServedShare(w.Network)
+	},
+	// lines is the number of subscriber lines
+	"lines": func(w *sim.World, c Condition, r *Run) float64 {
+		lines := 0
+		for _, n := range w.Network.GetNodesOfType(network.Subscriber) {
+			lines += n.Subscribers
+		}
+		return float64(lines)
+	},
+	// blocking is the share of calls blocked in the last whole month, or so far in the first
+	"blocking": func(w *sim.World, c Condition, r *Run) float64 {
+		if r.LastMonth.Attempted == 0 {
+			return w.Traffic.Stats.GetBlockingRate()
+		}
+		return r.LastMonth.GetBlockingRate()
+	},
+	// coverage is the share of the Map with radio coverage
+	"coverage": func(w *sim.World, c Condition, r *Run) float64 {
+		if cov := w.GetCoverage(); cov != nil {
+			return cov.GetCoveredShare()
+		}
+		return 0
+	},
+	// mobiles_served is the share of Mobiles with a Tower
+	"mobiles_served": func(w *sim.World, c Condition, r *Run) float64 {
+		if w.Mobiles.GetCount() == 0 {
+			return 0
+		}
+		return float64(w.Mobiles.GetServed()) / float64(w.Mobiles.GetCount())
+	},
+	"balance": func(w *sim.World, c Condition, r *Run) float64 {
+		return w.Economy.Balance
+	},
+	"debt": func(w *sim.World, c Condition, r *Run) float64 {
+		return w.Economy.GetDebt()
+	},
+	// years is the game years played
+	"years": func(w *sim.World, c Condition, r *Run) float64 {
+		return w.Calendar.Time / w.Calendar.SecondsPerDay / 365
+	},
+	"tech": func(w *sim.World, c Condition, r *Run) float64 {
+		if w.Research.Known[c.Tech] {
+			return 1
+		}
+		return 0
+	},
+}
+
+// IsMet returns whether the Condition holds in the World
+func (c Condition) IsMet(w *sim.World, r *Run) bool {
+	v := metrics[c.Metric](w, c, r)
+	return (c.Min == nil || v >= *c.Min) && (c.Max == nil || v <= *c.Max)
+}
+
+// IsMet returns whether every Condition of the Objective holds in the World
+func (o *Objective) IsMet(w *sim.World, r *Run) bool {
+	for _, c := range o.Conditions {
+		if !c.IsMet(w, r) {
+			return false
+		}
+	}
+	return true
+}
+
+// GetServedShare returns the share of the demand of every block that is
+// connected to an Exchange, through any number of Links
+func GetServedShare(net *network.Network) float64 {
+	total, served := 0, 0
+	for _, component := range net.GetComponents() {
+		demand, exchange := 0, false
+		for _, n := range component {
+			demand += n.Demand
+			exchange = exchange || n.Type == network.Exchange
+		}
+		total += demand
+		if exchange {
+			served += demand
+		}
+	}
+	if total == 0 {
+		return 0
+	}
+	return float64(served) / float64(total)
+}
+
+// GetDescription returns the Description of the Objective, or its Conditions if it has none
+func (o *Objective) GetDescription() string {
+	if o.Description != "" {
+		return o.Description
+	}
+	parts := make([]string, 0, len(o.Conditions))
+	for _, c := range o.Conditions {
+		parts = append(parts, c.String())
+	}
+	return strings.Join(parts, ", ")
+}
+
+// String returns the Condition as it is shown to the player
+func (c Condition) String() string {
+	name := c.Metric
+	if c.Metric == "tech" {
+		name = c.Tech
+	}
+	switch {
+	case c.Min != nil && c.Max != nil:
+		return fmt.Sprintf("%g <= %v <= %g", *c.Min, name, *c.Max)
+	case c.Min != nil:
+		return fmt.Sprintf("%v >= %g", name, *c.Min)
+	case c.Max != nil:
+		return fmt.Sprintf("%v <= %g", name, *c.Max)
+	}
+	return name
+}
@@ -0,0 +1,131 @@
+package scenario
+
+import (
+	"github.com/WhoBrokeTheBuild/TelcomSim/events"
+	"github.com/WhoBrokeTheBuild/TelcomSim/sim"
+	"github.com/WhoBrokeTheBuild/TelcomSim/traffic"
+)
+
+// Status is how an Objective or Run stands
+type Status string
+
+const (
+	// Playing is an Objective or Run not yet decided
+	Playing Status = ""
+	// Won is an Objective met, or a Run with every Objective met
+	Won Status = "won"
+	// Lost is an Objective failed, or a Run with one failed
+	Lost Status = "lost"
+)
+
+// Run is the progress of a game through a Scenario, and through its Campaign if
+// it is part of one
+type Run struct {
+	// ID is the name the Scenario was loaded by
+	ID       string
+	Scenario *Scenario
+	// Objectives holds the Status of each Objective of the Scenario
+	Objectives []Status
+	Status     Status
+
+	// Campaign is the Campaign being played, and Stage the index of the Scenario in it
+	Campaign *Campaign `json:",omitempty"`
+	Stage    int       `json:",omitempty"`
+
+	// LastMonth is the calls of the last whole month, MonthStart the calls before this one
+	LastMonth  traffic.Stats
+	MonthStart traffic.Stats
+	Month      int
+
+	// Events receives ObjectiveCompleted, ObjectiveFailed and ScenarioEnded, if set
+	Events *events.Bus `json:"-"`
+}
+
+// NewRun returns a new Run of s loaded by the name id, with no Objective met
+func NewRun(id string, s *Scenario) *Run {
+	return &Run{
+		ID:         id,
+		Scenario:   s,
+		Objectives: make([]Status, len(s.Objectives)),
+	}
+}
+
+// Update evaluates every Objective not yet decided against the World, ending
+// the Run once every Objective is met or one is failed. Lose Objectives without
+// a By are never met, so a Run with only those is never won. It is called after each tick.
+func (r *Run) Update(w *sim.World) {
+	if month := w.Calendar.GetMonth(); month != r.Month {
+		r.LastMonth = w.Traffic.Stats.Sub(r.MonthStart)
+		r.MonthStart = w.Traffic.Stats
+		r.Month = month
+	}
+	if r.Status != Playing {
+		return
+	}
+
+	year := w.Calendar.GetYear()
+	for i := range r.Scenario.Objectives {
+		o := &r.Scenario.Objectives[i]
+		if r.Objectives[i] != Playing {
+			continue
+		}
+
+		switch {
+		case o.Lose && o.IsMet(w, r):
+			r.setObjective(i, Lost)
+		case o.Lose && o.By != 0 && year > o.By:
+			r.setObjective(i, Won)
+		case o.Lose:
+		case o.IsMet(w, r):
+			r.setObjective(i, Won)
+		case o.By != 0 && year > o.By:
+			r.setObjective(i, Lost)
+		}
+	}
+
+	for _, s := range r.Objectives {
+		if s == Lost {
+			r.end(Lost)
+			return
+		}
+	}
+	won := false
+	for i, o := range r.Scenario.Objectives {
+		if o.Lose && o.By == 0 {
+			continue
+		}
+		if r.Objectives[i] != Won {
+			return
+		}
+		won = true
+	}
+	if won {
+		r.end(Won)
+	}
+}
+
+// setObjective decides an Objective and publishes it
+func (r *Run) setObjective(i int, s Status) {
+	r.Objectives[i] = s
+	desc := r.Scenario.Objectives[i].GetDescription()
+	if s == Won {
+		r.Events.Publish(events.ObjectiveCompleted{Objective: desc})
+	} else {
+		r.Events.Publish(events.ObjectiveFailed{Objective: desc})
+	}
+}
+
+// end decides the Run and publishes it
+func (r *Run) end(s Status) {
+	r.Status = s
+	r.Events.Publish(events.ScenarioEnded{Scenario: r.Scenario.Name, Won: s == Won})
+}
+
+// GetNext returns the name of the next Scenario of the Campaign, or "" if the
+// Run isn't won or was the last
+func (r *Run) GetNext() string {
+	if r.Status != Won || r.Campaign == nil || r.Stage+1 >= len(r.Campaign.Scenarios) {
+		return ""
+	}
+	return r.Campaign.Scenarios[r.Stage+1]
+}
@@ -0,0 +1,205 @@
+package scenario
+
+import (
+	"testing"
+	"time"
+
+	"github.com/WhoBrokeTheBuild/TelcomSim/events"
+	"github.com/WhoBrokeTheBuild/TelcomSim/sim"
+	"github.com/WhoBrokeTheBuild/TelcomSim/traffic"
+)
+
+// value returns a pointer to v, for the Min and Max of a Condition
+func value(v float64) *float64 {
+	return &v
+}
+
+// setDate moves the Calendar of w to the start of month (0-11) of year
+func setDate(w *sim.World, year, month int) {
+	start := time.Date(w.Calendar.StartYear, time.January, 1, 0, 0, 0, 0, time.UTC)
+	days := time.Date(year, time.Month(month+1), 1, 0, 0, 0, 0, time.UTC).Sub(start).Hours() / 24
+	w.Calendar.Time = days * w.Calendar.SecondsPerDay
+}
+
+func TestRunUpdate(t *testing.T) {
+	rich := Objective{Conditions: []Condition{{Metric: "balance", Min: value(1000000)}}}
+	bankrupt := Objective{Conditions: []Condition{{Metric: "balance", Max: value(0)}}, Lose: true}
+
+	// step is the World at one Update, and how the Run should stand after it
+	type step struct {
+		year    int
+		balance float64
+		want    Status
+	}
+	tests := []struct {
+		name       string
+		objectives []Objective
+		steps      []step
+		// statuses is how each Objective stands after the last step
+		statuses []Status
+	}{
+		{
+			name:       "won once met",
+			objectives: []Objective{rich},
+			steps:      []step{{1990, 500000, Playing}, {1991, 2000000, Won}},
+			statuses:   []Status{Won},
+		},
+		{
+			name: "won once every objective is met",
+			objectives: []Objective{
+				rich,
+				{Conditions: []Condition{{Metric: "years", Min: value(2)}}},
+			},
+			// The balance falling again doesn't undo the first
+			steps:    []step{{1990, 2000000, Playing}, {1991, 0, Playing}, {1992, 0, Won}},
+			statuses: []Status{Won, Won},
+		},
+		{
+			name:       "met by the deadline",
+			objectives: []Objective{{Conditions: rich.Conditions, By: 1991}},
+			steps:      []step{{1990, 0, Playing}, {1991, 2000000, Won}},
+			statuses:   []Status{Won},
+		},
+		{
+			name:       "lost past the deadline",
+			objectives: []Objective{{Conditions: rich.Conditions, By: 1991}},
+			// The deadline year itself is still in time
+			steps:    []step{{1991, 0, Playing}, {1992, 0, Lost}, {1993, 2000000, Lost}},
+			statuses: []Status{Lost},
+		},
+		{
+			name:       "lost when a lose objective is met",
+			objectives: []Objective{rich, bankrupt},
+			steps:      []step{{1990, 100, Playing}, {1990, -100, Lost}, {1991, 2000000, Lost}},
+			statuses:   []Status{Playing, Lost},
+		},
+		{
+			name:       "won without meeting a lose objective",
+			objectives: []Objective{rich, bankrupt},
+			steps:      []step{{1990, 100, Playing}, {1991, 2000000, Won}},
+			statuses:   []Status{Won, Playing},
+		},
+		{
+			name:       "never won with only open lose objectives",
+			objectives: []Objective{bankrupt},
+			steps:      []step{{1990, 100, Playing}, {2050, 100, Playing}},
+			statuses:   []Status{Playing},
+		},
+		{
+			name:       "won by surviving a lose objective",
+			objectives: []Objective{{Conditions: bankrupt.Conditions, By: 1995, Lose: true}},
+			steps:      []step{{1995, 100, Playing}, {1996, 100, Won}},
+			statuses:   []Status{Won},
+		},
+		{
+			name:       "lost before surviving a lose objective",
+			objectives: []Objective{{Conditions: bankrupt.Conditions, By: 1995, Lose: true}},
+			steps:      []step{{1994, -100, Lost}, {1996, 100, Lost}},
+			statuses:   []Status{Lost},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := sim.NewWorld(1)
+			r := NewRun("test", &Scenario{Name: "Test", Objectives: tt.objectives})
+			r.Events = events.NewBus()
+			ended := []events.ScenarioEnded{}
+			r.Events.Subscribe(events.ScenarioEndedType, func(e events.Event) {
+				ended = append(ended, e.(events.ScenarioEnded))
+			})
+
+			for i, s := range tt.steps {
+				setDate(w, s.year, 0)
+				w.Economy.Balance = s.balance
+				r.Update(w)
+				if r.Status != s.want {
+					t.Fatalf("step %d: Status = %q, want %q", i, r.Status, s.want)
+				}
+			}
+			for i, want := range tt.statuses {
+				if r.Objectives[i] != want {
+					t.Errorf("objective %d is %q, want %q", i, r.Objectives[i], want)
+				}
+			}
+
+			r.Events.Drain()
+			switch {
+			case r.Status == Playing && len(ended) != 0:
+				t.Errorf("published %v while still playing", ended)
+			case r.Status != Playing && len(ended) != 1:
+				t.Errorf("published %d ScenarioEnded, want 1", len(ended))
+			case r.Status != Playing && ended[0].Won != (r.Status == Won):
+				t.Errorf("published Won = %v for a Run that is %q", ended[0].Won, r.Status)
+			}
+		})
+	}
+}
+
+func TestBlockingLastMonth(t *testing.T) {
+	w := sim.NewWorld(1)
+	r := NewRun("test", &Scenario{Objectives: []Objective{
+		{Conditions: []Condition{{Metric: "blocking", Max: value(0.1)}}},
+	}})
+
+	// In the first month the calls so far are all there is
+	w.Traffic.Stats = traffic.Stats{Attempted: 100, Blocked: 50}
+	r.Update(w)
+	if r.Status != Playing || r.LastMonth.Attempted != 0 {
+		t.Fatalf("first month is %q with %d calls last month, want playing with none", r.Status, r.LastMonth.Attempted)
+	}
+
+	// The first month becomes the last, and still blocks too many
+	setDate(w, 1990, 1)
+	r.Update(w)
+	if r.LastMonth != w.Traffic.Stats || r.MonthStart != w.Traffic.Stats || r.Month != 1 {
+		t.Errorf("rolled over to month %d with last month %+v, want month 1 with %+v", r.Month, r.LastMonth, w.Traffic.Stats)
+	}
+	if r.Status != Playing {
+		t.Fatalf("won at a blocking rate of 0.5")
+	}
+
+	// Only the calls of the second month count once it ends, not the first
+	w.Traffic.Stats = traffic.Stats{Attempted: 1100, Blocked: 60}
+	r.Update(w)
+	if r.Status != Playing {
+		t.Fatalf("won before the month ended")
+	}
+	setDate(w, 1990, 2)
+	r.Update(w)
+	if want := (traffic.Stats{Attempted: 1000, Blocked: 10}); r.LastMonth != want {
+		t.Errorf("last month = %+v, want %+v", r.LastMonth, want)
+	}
+	if r.Status != Won {
+		t.Errorf("Status = %q, want won at a blocking rate of 0.01 last month", r.Status)
+	}
+}
+
+func TestGetNext(t *testing.T) {
+	campaign := &Campaign{Name: "Test", Scenarios: []string{"first", "second", "third"}}
+
+	tests := []struct {
+		name     string
+		campaign *Campaign
+		stage    int
+		status   Status
+		want     string
+	}{
+		{name: "won", campaign: campaign, stage: 0, status: Won, want: "second"},
+		{name: "won later", campaign: campaign, stage: 1, status: Won, want: "third"},
+		{name: "won the last", campaign: campaign, stage: 2, status: Won, want: ""},
+		{name: "playing", campaign: campaign, stage: 0, status: Playing, want: ""},
+		{name: "lost", campaign: campaign, stage: 0, status: Lost, want: ""},
+		{name: "without a campaign", campaign: nil, stage: 0, status: Won, want: ""},
+	}
+
+	for _, tt := range tests {
+		r := NewRun("first", &Scenario{})
+		r.Campaign = tt.campaign
+		r.Stage = tt.stage
+		r.Status = tt.status
+		if got := r.GetNext(); got != tt.want {
+			t.Errorf("%v: GetNext() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package scenario
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/WhoBrokeTheBuild/TelcomSim/network"
+	"github.com/WhoBrokeTheBuild/TelcomSim/sim"
+	"github.com/WhoBrokeTheBuild/TelcomSim/tech"
+	"github.com/WhoBrokeTheBuild/TelcomSim/terrain"
+	"github.com/WhoBrokeTheBuild/TelcomSim/traffic"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// Site is an Exchange or Tower a Scenario starts with
+type Site struct {
+	Name     string
+	Position mgl32.Vec2
+}
+
+// LinkSpec is a Link a Scenario starts with, between two Sites by name
+type LinkSpec struct {
+	A    string
+	B    string
+	Type string
+}
+
+// NetworkSpec is the Network a Scenario starts with
+type NetworkSpec struct {
+	Exchanges []Site
+	Towers    []Site
+	Links     []LinkSpec
+
+	// FiberRange is how far from an Exchange a block starts with fiber, blocks
+	// further away start on copper if a Tower reaches them
+	FiberRange float32
+	// Adoption is the share of its demand each connected block starts with
+	Adoption float64
+}
+
+// Scenario is the starting state of a game and the Objectives that win or lose it
+type Scenario struct {
+	Name        string
+	Description string
+
+	// Map is the name of the map played on
+	Map     string
+	Seed    int64
+	Year    int
+	Balance float64
+	Network NetworkSpec
+	// Crews are the Exchanges a repair Crew starts at, one for each time listed
+	Crews []string `json:",omitempty"`
+	// Techs are researched from the start, with the Start Techs of the tree
+	Techs []string `json:",omitempty"`
+
+	Objectives []Objective `json:",omitempty"`
+}
+
+// Parse reads a Scenario from JSON
+func Parse(b []byte) (*Scenario, error) {
+	s := &Scenario{
+		Year:    sim.StartYear,
+		Balance: sim.StartingBalance,
+	}
+	err := json.Unmarshal(b, s)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse scenario [%v]", err)
+	}
+	for i, o := range s.Objectives {
+		for _, c := range o.Conditions {
+			if _, found := metrics[c.Metric]; !found {
+				return nil, fmt.Errorf("Objective [%d] of [%v] has unknown metric [%v]", i, s.Name, c.Metric)
+			}
+		}
+	}
+	return s, nil
+}
+
+// NewWorld returns a new World set up as the Scenario describes, on m with its
+// blocks spawned with spawn, researching through tree if it isn't nil
+func (s *Scenario) NewWorld(m *terrain.Map, spawn traffic.SpawnConfig, tree *tech.Tree) (*sim.World, error) {
+	w := sim.NewWorld(s.Seed)
+	w.Calendar = sim.NewCalendar(s.Year, sim.SecondsPerDay)
+	w.Economy.SetBalance(s.Balance)
+
+	if tree != nil {
+		w.SetTechTree(tree)
+		for _, id := range s.Techs {
+			err := w.LearnTech(id)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	err := s.buildNetwork(w, m, spawn)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to build the network of [%v] [%v]", s.Name, err)
+	}
+
+	for _, name := range s.Crews {
+		node := w.Network.FindNode(name)
+		if node == nil {
+			return nil, fmt.Errorf("No such exchange [%v]", name)
+		}
+		_, err = w.Maintenance.Hire(w.Entities, node.ID)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return w, nil
+}
+
+// buildNetwork adds the Sites and Links of the Scenario to w, then spawns the
+// blocks of m and connects them
+func (s *Scenario) buildNetwork(w *sim.World, m *terrain.Map, spawn traffic.SpawnConfig) error {
+	net := w.Network
+	for _, site := range s.Network.Exchanges {
+		net.AddExchange(site.Name, site.Position)
+	}
+	for _, site := range s.Network.Towers {
+		net.AddTower(site.Name, site.Position)
+	}
+
+	for _, l := range s.Network.Links {
+		a, b := net.FindNode(l.A), net.FindNode(l.B)
+		if a == nil || b == nil {
+			return fmt.Errorf("No such sites [%v] and [%v]", l.A, l.B)
+		}
+		t, err := parseLinkType(l.Type)
+		if err != nil {
+			return err
+		}
+		_, err = net.Connect(a.ID, b.ID, t)
+		if err != nil {
+			return err
+		}
+	}
+
+	blocks := w.SetMap(m, spawn)
+	if len(net.GetNodesOfType(network.Exchange)) == 0 {
+		return nil
+	}
+
+	// Blocks near an exchange start on fiber, the rest on copper if a tower reaches them
+	var err error
+	for _, block := range blocks {
+		if ex := net.GetNearest(block.Position, network.Exchange); ex.DistanceTo(block) <= s.Network.FiberRange {
+			_, err = net.Connect(block.ID, ex.ID, network.Fiber)
+		} else if t := net.GetNearest(block.Position, network.Tower); t != nil && t.DistanceTo(block) <= t.Range {
+			_, err = net.Connect(block.ID, t.ID, network.Copper)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	w.Traffic.GrowSubscribers(s.Network.Adoption)
+	return nil
+}
+
+// parseLinkType returns the LinkType named name, in any case
+func parseLinkType(name string) (network.LinkType, error) {
+	for _, t := range []network.LinkType{network.Copper, network.Fiber, network.Microwave} {
+		if strings.EqualFold(t.String(), name) {
+			return t, nil
+		}
+	}
+	return 0, fmt.Errorf("Unknown link type [%v]", name)
+}
@@ -0,0 +1,79 @@
+package scenario
+
+import (
+	"testing"
+
+	"github.com/WhoBrokeTheBuild/TelcomSim/sim"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		json    string
+		wantErr bool
+	}{
+		{
+			name: "valid",
+			json: `{"Name": "Test", "Objectives": [
+				{"Conditions": [{"Metric": "served", "Min": 0.5}], "By": 1995},
+				{"Conditions": [{"Metric": "balance", "Max": 0}], "Lose": true}
+			]}`,
+		},
+		{name: "without objectives", json: `{"Name": "Test"}`},
+		{
+			name:    "unknown metric",
+			json:    `{"Name": "Test", "Objectives": [{"Conditions": [{"Metric": "profit", "Min": 1}]}]}`,
+			wantErr: true,
+		},
+		{
+			name: "unknown metric after a known one",
+			json: `{"Name": "Test", "Objectives": [
+				{"Conditions": [{"Metric": "lines", "Min": 1}]},
+				{"Conditions": [{"Metric": "lines", "Min": 1}, {"Metric": "Lines", "Min": 1}]}
+			]}`,
+			wantErr: true,
+		},
+		{name: "invalid JSON", json: `{"Name": `, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		s, err := Parse([]byte(tt.json))
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%v: Parse() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if s.Name != "Test" {
+			t.Errorf("%v: Name = %q, want Test", tt.name, s.Name)
+		}
+		// What isn't set starts like a new game
+		if s.Year != sim.StartYear || s.Balance != sim.StartingBalance {
+			t.Errorf("%v: starts in %d with %v, want %d with %v", tt.name, s.Year, s.Balance, sim.StartYear, sim.StartingBalance)
+		}
+	}
+}
+
+func TestParseCampaign(t *testing.T) {
+	tests := []struct {
+		name    string
+		json    string
+		wantErr bool
+	}{
+		{name: "valid", json: `{"Name": "Test", "Scenarios": ["first", "second"]}`},
+		{name: "without scenarios", json: `{"Name": "Test", "Scenarios": []}`, wantErr: true},
+		{name: "invalid JSON", json: `{"Name": `, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		c, err := ParseCampaign([]byte(tt.json))
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%v: ParseCampaign() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if err == nil && len(c.Scenarios) != 2 {
+			t.Errorf("%v: got scenarios %v, want 2", tt.name, c.Scenarios)
+		}
+	}
+}
@@ -0,0 +1,268 @@
+package scene
+
+import (
+	"github.com/WhoBrokeTheBuild/TelcomSim/asset"
+	"github.com/WhoBrokeTheBuild/TelcomSim/camera"
+	"github.com/WhoBrokeTheBuild/TelcomSim/context"
+	"github.com/WhoBrokeTheBuild/TelcomSim/light"
+	"github.com/WhoBrokeTheBuild/TelcomSim/save"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// Node is an element of the scene graph, with a transform relative to its parent
+type Node struct {
+	Name     string
+	Parent   *Node
+	Children []*Node
+
+	// Model, Light and Camera are optional and follow the Node's world transform
+	Model  *asset.Model
+	Light  *light.Light
+	Camera *camera.Camera
+
+	// OnUpdate is called every update, before the Node's children are updated
+	OnUpdate func(*Node, *context.Update)
+
+	translation mgl32.Vec3
+	rotation    mgl32.Quat
+	scale       mgl32.Vec3
+
+	world mgl32.Mat4
+	dirty bool
+}
+
+// NewNode returns a new Node with an identity transform
+func NewNode(name string) *Node {
+	return &Node{
+		Name:     name,
+		Children: []*Node{},
+
+		translation: mgl32.Vec3{0, 0, 0},
+		rotation:    mgl32.QuatIdent(),
+		scale:       mgl32.Vec3{1, 1, 1},
+
+		world: mgl32.Ident4(),
+		dirty: true,
+	}
+}
+
+// Delete frees all resources owned by the Node and its children
+func (n *Node) Delete() {
+	for _, c := range n.Children {
+		c.Delete()
+	}
+	if n.Model != nil {
+		n.Model.Delete()
+		n.Model = nil
+	}
+}
+
+// AddChild attaches c to the Node, detaching it from its previous parent
+func (n *Node) AddChild(c *Node) {
+	if c.Parent != nil {
+		c.Parent.RemoveChild(c)
+	}
+	c.Parent = n
+	c.invalidate()
+	n.Children = append(n.Children, c)
+}
+
+// RemoveChild detaches c from the Node
+func (n *Node) RemoveChild(c *Node) {
+	for i := range n.Children {
+		if n.Children[i] == c {
+			n.Children = append(n.Children[:i], n.Children[i+1:]...)
+			c.Parent = nil
+			c.invalidate()
+			return
+		}
+	}
+}
+
+// Find returns the first Node in the subtree with the given name, or nil
+func (n *Node) Find(name string) *Node {
+	var found *Node
+	n.Walk(func(c *Node) bool {
+		if c.Name == name {
+			found = c
+		}
+		return found == nil
+	})
+	return found
+}
+
+// Walk calls fn for the Node and its descendants depth-first, stopping when fn returns false
+func (n *Node) Walk(fn func(*Node) bool) bool {
+	if !fn(n) {
+		return false
+	}
+	for _, c := range n.Children {
+		if !c.Walk(fn) {
+			return false
+		}
+	}
+	return true
+}
+
+// GetTranslation returns the Node's translation relative to its parent
+func (n *Node) GetTranslation() mgl32.Vec3 {
+	return n.translation
+}
+
+// SetTranslation sets the Node's translation relative to its parent
+func (n *Node) SetTranslation(t mgl32.Vec3) {
+	n.translation = t
+	n.invalidate()
+}
+
+// GetRotation returns the Node's rotation relative to its parent
+func (n *Node) GetRotation() mgl32.Quat {
+	return n.rotation
+}
+
+// SetRotation sets the Node's rotation relative to its parent
+func (n *Node) SetRotation(r mgl32.Quat) {
+	n.rotation = r.Normalize()
+	n.invalidate()
+}
+
+// GetScale returns the Node's scale relative to its parent
+func (n *Node) GetScale() mgl32.Vec3 {
+	return n.scale
+}
+
+// SetScale sets the Node's scale relative to its parent
+func (n *Node) SetScale(s mgl32.Vec3) {
+	n.scale = s
+	n.invalidate()
+}
+
+// LookAt moves the Node to eye and rotates it so -Z faces target, as a camera does
+func (n *Node) LookAt(eye, target, up mgl32.Vec3) {
+	// Work in world space, and convert to the parent's space
+	world := mgl32.LookAtV(eye, target, up).Inv()
+	if n.Parent != nil {
+		world = n.Parent.GetWorldTransform().Inv().Mul4(world)
+	}
+	n.translation = world.Col(3).Vec3()
+	n.rotation = mgl32.Mat4ToQuat(world).Normalize()
+	n.invalidate()
+}
+
+// GetLocalTransform returns the Node's transform relative to its parent
+func (n *Node) GetLocalTransform() mgl32.Mat4 {
+	t := mgl32.Translate3D(n.translation[0], n.translation[1], n.translation[2])
+	s := mgl32.Scale3D(n.scale[0], n.scale[1], n.scale[2])
+	return t.Mul4(n.rotation.Mat4()).Mul4(s)
+}
+
+// GetWorldTransform returns the Node's transform relative to the root, cached until it or a parent changes
+func (n *Node) GetWorldTransform() mgl32.Mat4 {
+	if n.dirty {
+		n.world = n.GetLocalTransform()
+		if n.Parent != nil {
+			n.world = n.Parent.GetWorldTransform().Mul4(n.world)
+		}
+		n.dirty = false
+	}
+	return n.world
+}
+
+// GetWorldPosition returns the Node's origin in world space
+func (n *Node) GetWorldPosition() mgl32.Vec3 {
+	return n.GetWorldTransform().Col(3).Vec3()
+}
+
+// invalidate marks the cached world transforms of the Node and its descendants as stale
+func (n *Node) invalidate() {
+	if n.dirty {
+		// Descendants are already dirty, as the flag is only cleared top down
+		return
+	}
+	n.dirty = true
+	for _, c := range n.Children {
+		c.invalidate()
+	}
+}
+
+// Update calls OnUpdate for the Node and its descendants, and moves attached Cameras
+// with their Controller, or to the Node's transform if they have none
+func (n *Node) Update(ctx *context.Update) {
+	if n.OnUpdate != nil {
+		n.OnUpdate(n, ctx)
+	}
+
+	for _, c := range n.Children {
+		c.Update(ctx)
+	}
+
+	if n.Camera != nil {
+		if n.Camera.Controller != nil {
+			n.Camera.Update(ctx)
+		} else {
+			n.Camera.SetTransform(n.GetWorldTransform())
+		}
+	}
+}
+
+// Draw renders the Models of the Node and its descendants
+func (n *Node) Draw(ctx *context.Render) {
+	if n.Model != nil {
+		n.Model.Transform = n.GetWorldTransform()
+		n.Model.Draw(ctx)
+	}
+
+	for _, c := range n.Children {
+		c.Draw(ctx)
+	}
+}
+
+// GetLights returns world space copies of the Lights attached to the Node and its descendants
+func (n *Node) GetLights() []*light.Light {
+	lights := []*light.Light{}
+	n.Walk(func(c *Node) bool {
+		if c.Light != nil {
+			world := c.GetWorldTransform()
+
+			l := *c.Light
+			l.Position = mgl32.TransformCoordinate(l.Position, world)
+			if l.Direction.Len() > 0 {
+				l.Direction = mgl32.TransformNormal(l.Direction, world).Normalize()
+			}
+			lights = append(lights, &l)
+		}
+		return true
+	})
+	return lights
+}
+
+// Save returns the transforms of the named Nodes in the subtree for a save file
+func (n *Node) Save() []save.Node {
+	nodes := []save.Node{}
+	n.Walk(func(c *Node) bool {
+		if c.Name != "" {
+			nodes = append(nodes, save.Node{
+				Name:        c.Name,
+				Translation: c.translation,
+				Rotation:    c.rotation,
+				Scale:       c.scale,
+			})
+		}
+		return true
+	})
+	return nodes
+}
+
+// Load restores the transforms of the Nodes in the subtree from a save file, matching them by name
+func (n *Node) Load(nodes []save.Node) {
+	for _, s := range nodes {
+		c := n.Find(s.Name)
+		if c == nil {
+			continue
+		}
+		c.translation = s.Translation
+		c.rotation = s.Rotation
+		c.scale = s.Scale
+		c.invalidate()
+	}
+}
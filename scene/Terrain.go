@@ -0,0 +1,133 @@
+package scene
+
+import (
+	"fmt"
+
+	"github.com/WhoBrokeTheBuild/TelcomSim/asset"
+	"github.com/WhoBrokeTheBuild/TelcomSim/terrain"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// DefaultChunkCells is the number of cells across a chunk of terrain
+const DefaultChunkCells = 32
+
+// NewTerrain returns a new Node with a child Node per chunk of the Map, each
+// with a Model of its cells. One unit in the scene is one kilometer, and the
+// Map's Y axis is the scene's Z axis.
+func NewTerrain(m *terrain.Map, chunkCells int) (*Node, error) {
+	return newDrape("terrain", m, chunkCells, 0, func() (*asset.Material, error) {
+		return asset.NewMaterial(&asset.MaterialData{
+			Ambient:  mgl32.Vec4{0.1, 0.15, 0.1, 1},
+			Diffuse:  mgl32.Vec4{0.35, 0.55, 0.3, 1},
+			Specular: mgl32.Vec4{0, 0, 0, 1},
+			Opacity:  1,
+		})
+	})
+}
+
+// NewOverlay returns a new Node like NewTerrain, lifted above the ground by lift
+// and showing tex unlit at opacity, with a texel per cell of the Map. The Node
+// takes ownership of tex, which can be reloaded to change what is shown.
+func NewOverlay(name string, m *terrain.Map, chunkCells int, lift, opacity float32, tex *asset.Texture) (*Node, error) {
+	tex.Options.Clamp = true
+	return newDrape(name, m, chunkCells, lift, func() (*asset.Material, error) {
+		mat, err := asset.NewMaterial(&asset.MaterialData{
+			Opacity: opacity,
+		})
+		if err != nil {
+			return nil, err
+		}
+		// Every chunk shares the Texture, which is only freed once
+		mat.EmissiveMap = tex
+		return mat, nil
+	})
+}
+
+// newDrape returns a new Node with a child Node per chunk of the Map, each with
+// a Material from newMaterial
+func newDrape(name string, m *terrain.Map, chunkCells int, lift float32, newMaterial func() (*asset.Material, error)) (*Node, error) {
+	if chunkCells <= 0 {
+		chunkCells = DefaultChunkCells
+	}
+
+	root := NewNode(name)
+	for z := 0; z < m.Depth-1; z += chunkCells {
+		for x := 0; x < m.Width-1; x += chunkCells {
+			mat, err := newMaterial()
+			if err != nil {
+				root.Delete()
+				return nil, err
+			}
+
+			model, err := newChunk(m, x, z, chunkCells, lift, mat)
+			if err != nil {
+				root.Delete()
+				return nil, err
+			}
+
+			// Chunks aren't named, so they aren't saved with the scene
+			chunk := NewNode("")
+			chunk.Model = model
+			root.AddChild(chunk)
+		}
+	}
+
+	return root, nil
+}
+
+// newChunk returns a Model of the cells from (x0, z0) to (x0 + size, z0 + size), clamped to the Map
+func newChunk(m *terrain.Map, x0, z0, size int, lift float32, mat *asset.Material) (*asset.Model, error) {
+	x1 := x0 + size
+	if x1 > m.Width-1 {
+		x1 = m.Width - 1
+	}
+	z1 := z0 + size
+	if z1 > m.Depth-1 {
+		z1 = m.Depth - 1
+	}
+
+	// Vertices are at the cell centers, so neighboring chunks share their edges
+	w := x1 - x0 + 1
+	d := z1 - z0 + 1
+	data := &asset.MeshData{
+		Material:  mat,
+		Vertices:  make([]mgl32.Vec3, 0, w*d),
+		Normals:   make([]mgl32.Vec3, 0, w*d),
+		TexCoords: make([]mgl32.Vec2, 0, w*d),
+		Indices:   make([]uint32, 0, (w-1)*(d-1)*6),
+	}
+
+	for z := z0; z <= z1; z++ {
+		for x := x0; x <= x1; x++ {
+			c := m.GetCellCenter(x, z)
+			data.Vertices = append(data.Vertices, mgl32.Vec3{c[0], m.GetCellHeight(x, z) + lift, c[1]})
+			data.Normals = append(data.Normals, m.GetNormal(x, z))
+			// The shader flips V, so the first row of a texture is at the first row of cells
+			data.TexCoords = append(data.TexCoords, mgl32.Vec2{
+				(float32(x) + 0.5) / float32(m.Width),
+				1 - (float32(z)+0.5)/float32(m.Depth),
+			})
+		}
+	}
+
+	for z := 0; z < d-1; z++ {
+		for x := 0; x < w-1; x++ {
+			i := uint32(z*w + x)
+			data.Indices = append(data.Indices,
+				i, i+uint32(w), i+1,
+				i+1, i+uint32(w), i+uint32(w)+1,
+			)
+		}
+	}
+
+	mesh, err := asset.NewMesh(data)
+	if err != nil {
+		mat.Delete()
+		return nil, fmt.Errorf("Failed to create terrain chunk at %d,%d [%v]", x0, z0, err)
+	}
+
+	return &asset.Model{
+		Transform: mgl32.Ident4(),
+		Meshes:    []*asset.Mesh{mesh},
+	}, nil
+}
@@ -0,0 +1,51 @@
+package sim
+
+import (
+	"time"
+)
+
+// Calendar maps simulated seconds onto game dates
+type Calendar struct {
+	StartYear int
+	// SecondsPerDay is the simulated seconds in a game day
+	SecondsPerDay float64
+	// Time is the simulated seconds since the start
+	Time float64
+}
+
+// NewCalendar returns a new Calendar starting on January 1st of startYear
+func NewCalendar(startYear int, secondsPerDay float64) *Calendar {
+	return &Calendar{
+		StartYear:     startYear,
+		SecondsPerDay: secondsPerDay,
+	}
+}
+
+// GetDate returns the current game date
+func (c *Calendar) GetDate() time.Time {
+	days := int(c.Time / c.SecondsPerDay)
+	return time.Date(c.StartYear, time.January, 1+days, 0, 0, 0, 0, time.UTC)
+}
+
+// GetYear returns the current game year
+func (c *Calendar) GetYear() int {
+	return c.GetDate().Year()
+}
+
+// GetMonth returns the number of whole months since the start
+func (c *Calendar) GetMonth() int {
+	date := c.GetDate()
+	return (date.Year()-c.StartYear)*12 + int(date.Month()) - 1
+}
+
+// Advance adds dt simulated seconds, returning how many months ended
+func (c *Calendar) Advance(dt float64) int {
+	before := c.GetMonth()
+	c.Time += dt
+	return c.GetMonth() - before
+}
+
+// String returns the current game date, like "Mar 1990"
+func (c *Calendar) String() string {
+	return c.GetDate().Format("Jan 2006")
+}
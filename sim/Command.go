@@ -0,0 +1,202 @@
+package sim
+
+import (
+	"fmt"
+
+	"github.com/WhoBrokeTheBuild/TelcomSim/economy"
+	"github.com/WhoBrokeTheBuild/TelcomSim/ecs"
+	"github.com/WhoBrokeTheBuild/TelcomSim/maintenance"
+	"github.com/WhoBrokeTheBuild/TelcomSim/network"
+	"github.com/WhoBrokeTheBuild/TelcomSim/traffic"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// CommandType is the kind of a Command
+type CommandType string
+
+const (
+	// BuildExchange adds an Exchange named Name at Position
+	BuildExchange CommandType = "build_exchange"
+	// BuildTower adds a Tower named Name at Position
+	BuildTower CommandType = "build_tower"
+	// BuildLink connects A and B with a Link of LinkType
+	BuildLink CommandType = "build_link"
+	// RemoveLink removes Link
+	RemoveLink CommandType = "remove_link"
+	// UpgradeExchange adds ExchangeUpgradeCapacity to the Capacity of Node
+	UpgradeExchange CommandType = "upgrade_exchange"
+	// SetTariff sets the PerMinute and Subscription prices
+	SetTariff CommandType = "set_tariff"
+	// SetPolicy sets the routing Policy of Node, by traffic.NewRouter name
+	SetPolicy CommandType = "set_policy"
+	// Borrow takes out a loan of Amount
+	Borrow CommandType = "borrow"
+	// SetAntenna replaces the Antenna of the Tower Node with Antenna
+	SetAntenna CommandType = "set_antenna"
+	// HireCrew hires a repair Crew based at the Exchange Node
+	HireCrew CommandType = "hire_crew"
+	// DispatchCrew sends Crew to repair Fault
+	DispatchCrew CommandType = "dispatch_crew"
+	// StartResearch pays for and starts researching the Tech with the ID Name
+	StartResearch CommandType = "start_research"
+)
+
+const (
+	// ExchangeUpgradeCapacity is the Capacity an UpgradeExchange adds
+	ExchangeUpgradeCapacity = 5000
+	// ExchangeUpgradeCost is what an UpgradeExchange costs
+	ExchangeUpgradeCost = 100000.0
+)
+
+// Command is a player action, applied to the World at the start of a tick. Every
+// change the player makes to a World goes through a Command, so a game can be
+// replayed exactly from its starting state and its Commands.
+type Command struct {
+	// Tick is the tick the Command was applied on
+	Tick uint64
+	Type CommandType
+
+	Name     string
+	Position mgl32.Vec2
+	Node     network.NodeID
+	A        network.NodeID
+	B        network.NodeID
+	Link     network.LinkID
+	LinkType network.LinkType
+	Policy   string
+	Antenna  *network.Antenna    `json:",omitempty"`
+	Crew     ecs.Entity          `json:",omitempty"`
+	Fault    maintenance.FaultID `json:",omitempty"`
+
+	Amount       float64
+	PerMinute    float64
+	Subscription float64
+}
+
+// apply performs the Command, leaving the World unchanged if it fails
+func (w *World) apply(c Command) error {
+	switch c.Type {
+	case BuildExchange, BuildTower:
+		t := network.Exchange
+		if c.Type == BuildTower {
+			t = network.Tower
+		}
+		e := maintenance.GetNodeTypeEquipment(t)
+		if !w.Research.IsUnlocked(e) {
+			return fmt.Errorf("[%v] hasn't been researched", t)
+		}
+		mod := w.Research.GetModifier(e)
+		if !w.Economy.CanAfford(float64(network.NodeCosts[t]) * mod.Cost) {
+			return fmt.Errorf("Insufficient funds to build [%v]", c.Name)
+		}
+
+		var node *network.Node
+		if t == network.Tower {
+			node = w.Network.AddTower(c.Name, c.Position)
+		} else {
+			node = w.Network.AddExchange(c.Name, c.Position)
+		}
+		node.Capacity = int(float64(node.Capacity) * mod.Capacity)
+		node.Cost *= float32(mod.Cost)
+		err := w.Economy.BuildNode(node)
+		if err != nil {
+			w.Network.RemoveNode(node.ID)
+			return err
+		}
+		return nil
+
+	case BuildLink:
+		e := maintenance.GetLinkTypeEquipment(c.LinkType)
+		if !w.Research.IsUnlocked(e) {
+			return fmt.Errorf("[%v] hasn't been researched", c.LinkType)
+		}
+		link, err := w.Network.Connect(c.A, c.B, c.LinkType)
+		if err != nil {
+			return err
+		}
+		mod := w.Research.GetModifier(e)
+		link.Capacity = int(float64(link.Capacity) * mod.Capacity)
+		link.Cost *= float32(mod.Cost)
+		err = w.Economy.BuildLink(link)
+		if err != nil {
+			w.Network.RemoveLink(link.ID)
+			return err
+		}
+		return nil
+
+	case RemoveLink:
+		return w.Network.RemoveLink(c.Link)
+
+	case UpgradeExchange:
+		node := w.Network.GetNode(c.Node)
+		if node == nil || node.Type != network.Exchange {
+			return fmt.Errorf("No such exchange [%v]", c.Node)
+		}
+		err := w.Economy.Spend(ExchangeUpgradeCost, fmt.Sprintf("Upgraded %v", node.Name))
+		if err != nil {
+			return err
+		}
+		node.Capacity += ExchangeUpgradeCapacity
+		return nil
+
+	case SetTariff:
+		if c.PerMinute < 0 || c.Subscription < 0 {
+			return fmt.Errorf("Invalid tariff [%v] per minute, [%v] per month", c.PerMinute, c.Subscription)
+		}
+		w.Economy.Rates.PerMinute = c.PerMinute
+		w.Economy.Rates.Subscription = c.Subscription
+		return nil
+
+	case SetPolicy:
+		if node := w.Network.GetNode(c.Node); node == nil || node.Type == network.Subscriber {
+			return fmt.Errorf("No such exchange [%v]", c.Node)
+		}
+		r, err := traffic.NewRouter(c.Policy)
+		if err != nil {
+			return err
+		}
+		w.Traffic.SetPolicy(c.Node, r)
+		return nil
+
+	case Borrow:
+		_, err := w.Economy.Borrow(c.Amount)
+		return err
+
+	case SetAntenna:
+		node := w.Network.GetNode(c.Node)
+		if node == nil || node.Type != network.Tower {
+			return fmt.Errorf("No such tower [%v]", c.Node)
+		}
+		if c.Antenna == nil || c.Antenna.Height <= 0 || c.Antenna.Beamwidth < 0 {
+			return fmt.Errorf("Invalid antenna for tower [%v]", node.Name)
+		}
+		antenna := *c.Antenna
+		node.Antenna = &antenna
+		return nil
+
+	case HireCrew:
+		_, err := w.Maintenance.Hire(w.Entities, c.Node)
+		return err
+
+	case DispatchCrew:
+		return w.Maintenance.Dispatch(c.Crew, c.Fault)
+
+	case StartResearch:
+		err := w.Research.CanResearch(c.Name, w.Calendar.GetYear())
+		if err != nil {
+			return err
+		}
+		t := w.Research.Tree.GetTech(c.Name)
+		if !w.Economy.CanAfford(t.Cost) {
+			return fmt.Errorf("Insufficient funds to research [%v]", t.Name)
+		}
+		err = w.Research.Start(c.Name, w.Calendar.GetYear())
+		if err != nil {
+			return err
+		}
+		w.Economy.Record(economy.Research, -t.Cost, fmt.Sprintf("Researching %v", t.Name))
+		return nil
+	}
+
+	return fmt.Errorf("Unknown command [%v]", c.Type)
+}
@@ -0,0 +1,263 @@
+package sim
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+
+	"github.com/WhoBrokeTheBuild/TelcomSim/economy"
+	"github.com/WhoBrokeTheBuild/TelcomSim/ecs"
+	"github.com/WhoBrokeTheBuild/TelcomSim/events"
+	"github.com/WhoBrokeTheBuild/TelcomSim/maintenance"
+	"github.com/WhoBrokeTheBuild/TelcomSim/network"
+	"github.com/WhoBrokeTheBuild/TelcomSim/radio"
+	"github.com/WhoBrokeTheBuild/TelcomSim/tech"
+	"github.com/WhoBrokeTheBuild/TelcomSim/terrain"
+	"github.com/WhoBrokeTheBuild/TelcomSim/traffic"
+)
+
+const (
+	// StartYear is the year a new game starts in
+	StartYear = 1990
+	// SecondsPerDay is the simulated seconds in a game day
+	SecondsPerDay = 20.0
+	// StartingBalance is the money a new game starts with
+	StartingBalance = 500000.0
+	// SubscriberGrowth is the share of its missing lines a connected Subscriber node gains each month
+	SubscriberGrowth = 0.1
+	// MobilesPerPerson is the share of the people on a Map travelling with a phone
+	MobilesPerPerson = 0.01
+)
+
+// World is the whole simulation state, advanced one tick at a time
+type World struct {
+	Seed     int64
+	Calendar *Calendar
+	Network  *network.Network
+	Traffic  *traffic.Engine
+	Economy  *economy.Economy
+	// Map is the terrain and population the Network is built on, if any
+	Map *terrain.Map `json:",omitempty"`
+	// Mobiles travel the Map, served by the Towers of the Network, as Entities
+	Mobiles *radio.Mobiles
+	// PathLoss is the name of the radio.PathLoss the Coverage is computed with
+	PathLoss string
+	// Entities holds the things in the World that come in large numbers, its
+	// Systems run every tick after the traffic and economy
+	Entities *ecs.World
+	// Maintenance breaks equipment down and repairs it, as a System of Entities
+	Maintenance *maintenance.Manager
+	// Research gates what can be built and modifies it, by the Techs known
+	Research *tech.Research
+
+	// Tick is the number of ticks run
+	Tick uint64
+	// Pending holds the Commands to apply at the start of the next tick
+	Pending []Command
+	// OnCommand is called with every Command applied and its error, if any
+	OnCommand func(Command, error) `json:"-"`
+	// Events receives what happens in the World, if set with SetEvents
+	Events *events.Bus `json:"-"`
+
+	// coverage is computed from the Network when needed, and cleared when a Command changes it
+	coverage *radio.Coverage
+}
+
+// NewWorld returns a new empty World seeded with seed
+func NewWorld(seed int64) *World {
+	net := network.NewNetwork()
+
+	w := &World{
+		Seed:     seed,
+		Calendar: NewCalendar(StartYear, SecondsPerDay),
+		Network:  net,
+		Traffic:  traffic.NewEngine(net, seed),
+		Economy:  economy.NewEconomy(StartingBalance),
+		PathLoss: radio.DefaultPathLoss,
+		Entities: ecs.NewWorld(),
+		Research: tech.NewResearch(nil),
+	}
+	w.Maintenance = maintenance.NewManager(w.Entities, net, w.Economy, seed+1, SecondsPerDay)
+	w.Mobiles = radio.NewMobiles(w.Entities, seed)
+
+	// A game day passes in SecondsPerDay, so each simulated second of traffic stands for many real ones
+	w.Economy.Rates.TrafficScale = 24 * 60 * 60 / SecondsPerDay
+	return w
+}
+
+// SetMap sets the Map of the World, spawns a Subscriber node for each populated
+// block of it and places Mobiles where people live
+func (w *World) SetMap(m *terrain.Map, cfg traffic.SpawnConfig) []*network.Node {
+	w.Map = m
+	w.Mobiles.Spawn(w.Entities, int(m.GetTotalPopulation()*MobilesPerPerson), m)
+	w.coverage = nil
+	return traffic.SpawnSubscribers(w.Network, m, cfg)
+}
+
+// SetTechTree starts the World's Research through tree, knowing its Start Techs
+func (w *World) SetTechTree(tree *tech.Tree) {
+	w.Research = tech.NewResearch(tree)
+	for _, t := range w.Research.GetKnown() {
+		w.applyTech(t)
+	}
+}
+
+// LearnTech makes the Tech with id known without researching it
+func (w *World) LearnTech(id string) error {
+	if w.Research.Tree == nil || w.Research.Tree.GetTech(id) == nil {
+		return fmt.Errorf("No such tech [%v]", id)
+	}
+	if !w.Research.Known[id] {
+		w.Research.Known[id] = true
+		w.applyTech(w.Research.Tree.GetTech(id))
+	}
+	return nil
+}
+
+// applyTech applies the reliability Modifiers of a newly known Tech to the equipment
+// already built. Capacity and cost only change equipment built after it.
+func (w *World) applyTech(t *tech.Tech) {
+	for _, m := range t.Modifiers {
+		if m.MTBF == 0 {
+			continue
+		}
+		r := w.Maintenance.Reliability[m.Equipment]
+		r.MTBF *= m.MTBF
+		w.Maintenance.Reliability[m.Equipment] = r
+	}
+}
+
+// updateResearch advances the Research by dt, applying the Tech completed if
+// any, and announces the Techs a new year makes available
+func (w *World) updateResearch(dt float64, year int) {
+	if t := w.Research.Update(dt / SecondsPerDay); t != nil {
+		w.applyTech(t)
+		w.Events.Publish(events.ResearchCompleted{Tech: t.ID})
+	}
+
+	now := w.Calendar.GetYear()
+	if now == year || w.Research.Tree == nil {
+		return
+	}
+	for _, t := range w.Research.Tree.Techs {
+		if t.Year > year && t.Year <= now && !w.Research.Known[t.ID] {
+			w.Events.Publish(events.TechAvailable{Tech: t.ID, Year: t.Year})
+		}
+	}
+}
+
+// GetCoverage returns the radio Coverage of the Towers over the Map, or nil without a Map
+func (w *World) GetCoverage() *radio.Coverage {
+	if w.coverage == nil && w.Map != nil {
+		model, err := radio.NewPathLoss(w.PathLoss)
+		if err != nil {
+			model, _ = radio.NewPathLoss(radio.DefaultPathLoss)
+		}
+		w.coverage = radio.NewCoverage(w.Network, w.Map, model)
+	}
+	return w.coverage
+}
+
+// SetEvents publishes what happens in the World to bus, which may be nil
+func (w *World) SetEvents(bus *events.Bus) {
+	w.Events = bus
+	w.Traffic.Events = bus
+	w.Maintenance.Events = bus
+}
+
+// Queue adds a Command to apply at the start of the next tick
+func (w *World) Queue(c Command) {
+	w.Pending = append(w.Pending, c)
+}
+
+// Update applies the Pending Commands, then advances the World by one tick of dt simulated seconds
+func (w *World) Update(dt float64) {
+	balance := w.Economy.Balance
+
+	pending := w.Pending
+	w.Pending = nil
+	for _, c := range pending {
+		c.Tick = w.Tick
+		err := w.apply(c)
+		w.coverage = nil
+		if w.OnCommand != nil {
+			w.OnCommand(c, err)
+		}
+	}
+
+	w.Traffic.Update(dt)
+	w.Economy.Update(w.Traffic)
+	if w.Map != nil {
+		w.Mobiles.Update(dt, w.Map, w.Network, w.GetCoverage())
+	}
+	w.Entities.Update(dt)
+	if w.Maintenance.Changed {
+		w.Maintenance.Changed = false
+		w.coverage = nil
+	}
+
+	year := w.Calendar.GetYear()
+	for months := w.Calendar.Advance(dt); months > 0; months-- {
+		w.Economy.EndMonth(w.Network, w.Traffic)
+		w.Traffic.GrowSubscribers(SubscriberGrowth)
+	}
+	w.updateResearch(dt, year)
+
+	if balance >= 0 && w.Economy.Balance < 0 {
+		w.Events.Publish(events.BudgetNegative{Balance: w.Economy.Balance})
+	}
+
+	w.Tick++
+}
+
+// Hash is the SHA-256 hash of part of a World, in hex
+type Hash struct {
+	Name string
+	Sum  string
+}
+
+// GetHashes returns a Hash of the state of each part of the World, and of the
+// whole, in a fixed order. Identical runs produce identical hashes.
+func (w *World) GetHashes() ([]Hash, error) {
+	parts := []struct {
+		name  string
+		value interface{}
+	}{
+		{"calendar", w.Calendar},
+		{"network", w.Network},
+		{"traffic", w.Traffic},
+		{"economy", w.Economy},
+		{"mobiles", w.Mobiles},
+		{"entities", w.Entities},
+		{"maintenance", w.Maintenance},
+		{"research", w.Research},
+		{"world", w},
+	}
+
+	hashes := make([]Hash, 0, len(parts))
+	for _, p := range parts {
+		b, err := json.Marshal(p.value)
+		if err != nil {
+			return nil, err
+		}
+		hashes = append(hashes, Hash{Name: p.name, Sum: fmt.Sprintf("%x", sha256.Sum256(b))})
+	}
+	return hashes, nil
+}
+
+// UnmarshalJSON replaces the World with one written by json.Marshal, linking the
+// traffic Engine and maintenance Manager to the loaded Network
+func (w *World) UnmarshalJSON(data []byte) error {
+	type world World
+	wj := world(*NewWorld(0))
+	err := json.Unmarshal(data, &wj)
+	if err != nil {
+		return err
+	}
+
+	*w = World(wj)
+	w.Traffic.Network = w.Network
+	w.Maintenance.Network = w.Network
+	w.Maintenance.Economy = w.Economy
+	return nil
+}
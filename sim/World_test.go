@@ -0,0 +1,144 @@
+package sim
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/WhoBrokeTheBuild/TelcomSim/economy"
+	"github.com/WhoBrokeTheBuild/TelcomSim/events"
+	"github.com/WhoBrokeTheBuild/TelcomSim/maintenance"
+	"github.com/WhoBrokeTheBuild/TelcomSim/network"
+	"github.com/WhoBrokeTheBuild/TelcomSim/tech"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// testTree has a Start Tech and one to research, both modifying Exchanges
+const testTree = `{"Techs": [
+	{
+		"ID": "switching", "Name": "Switching", "Year": 1990, "Start": true,
+		"Modifiers": [{"Equipment": "exchange", "MTBF": 2}]
+	},
+	{
+		"ID": "digital", "Name": "Digital Switching", "Year": 1990, "Cost": 50000, "Days": 10,
+		"Requires": ["switching"],
+		"Modifiers": [{"Equipment": "exchange", "Capacity": 2, "Cost": 0.5, "MTBF": 3}]
+	}
+]}`
+
+// newTestWorld returns a World researching testTree, with the money to build plenty
+func newTestWorld(t *testing.T) *World {
+	tree, err := tech.ParseTree([]byte(testTree))
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := NewWorld(1)
+	w.SetTechTree(tree)
+	w.Economy.Balance = 10000000
+	return w
+}
+
+// getExchangeMTBF returns the MTBF of Exchanges in w
+func getExchangeMTBF(w *World) float64 {
+	return w.Maintenance.Reliability[maintenance.ExchangeEquipment].MTBF
+}
+
+// buildExchange builds an Exchange in w, returning it
+func buildExchange(t *testing.T, w *World) *network.Node {
+	var err error
+	w.OnCommand = func(c Command, e error) { err = e }
+	w.Queue(Command{Type: BuildExchange, Name: "A", Position: mgl32.Vec2{1, 1}})
+	w.Update(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nodes := w.Network.GetNodes()
+	return nodes[len(nodes)-1]
+}
+
+func TestResearchCompleted(t *testing.T) {
+	w := newTestWorld(t)
+	bus := events.NewBus()
+	w.SetEvents(bus)
+	completed := []string{}
+	bus.Subscribe(events.ResearchCompletedType, func(e events.Event) {
+		completed = append(completed, e.(events.ResearchCompleted).Tech)
+	})
+
+	w.Queue(Command{Type: StartResearch, Name: "digital"})
+	// Digital takes 10 days, each tick of a day
+	for day := 1; day <= 12; day++ {
+		w.Update(SecondsPerDay)
+		bus.Drain()
+		if want := day >= 10; w.Research.Known["digital"] != want {
+			t.Fatalf("after %d days digital known is %v, want %v", day, !want, want)
+		}
+	}
+
+	if len(completed) != 1 || completed[0] != "digital" {
+		t.Errorf("completed %v, want digital once", completed)
+	}
+	if got := w.Economy.Ledger.GetTotal(0, economy.Research); got != -50000 {
+		t.Errorf("Research total = %v, want the cost -50000", got)
+	}
+	if got, want := getExchangeMTBF(w), maintenance.DefaultReliability[maintenance.ExchangeEquipment].MTBF*6; got != want {
+		t.Errorf("exchange MTBF = %v, want %v", got, want)
+	}
+}
+
+func TestApplyTech(t *testing.T) {
+	mtbf := maintenance.DefaultReliability[maintenance.ExchangeEquipment].MTBF
+
+	w := newTestWorld(t)
+	if got := getExchangeMTBF(w); got != mtbf*2 {
+		t.Fatalf("exchange MTBF = %v, want the start tech's %v", got, mtbf*2)
+	}
+	// Only MTBF changes what is already built
+	before := buildExchange(t, w)
+
+	for i := 0; i < 2; i++ {
+		if err := w.LearnTech("digital"); err != nil {
+			t.Fatal(err)
+		}
+		if got := getExchangeMTBF(w); got != mtbf*6 {
+			t.Errorf("exchange MTBF = %v after learning %d times, want %v", got, i+1, mtbf*6)
+		}
+	}
+	if err := w.LearnTech("satellite"); err == nil {
+		t.Errorf("learnt a tech that isn't in the tree")
+	}
+	if before.Capacity != network.DefaultExchangeCapacity || before.Cost != network.NodeCosts[network.Exchange] {
+		t.Errorf("exchange built before has capacity %v and cost %v, want them unchanged", before.Capacity, before.Cost)
+	}
+
+	checkBuilt := func(w *World, when string) {
+		for i := 0; i < 2; i++ {
+			n := buildExchange(t, w)
+			if n.Capacity != network.DefaultExchangeCapacity*2 || n.Cost != network.NodeCosts[network.Exchange]/2 {
+				t.Errorf("exchange %d built %v has capacity %v and cost %v, want %v and %v", i, when,
+					n.Capacity, n.Cost, network.DefaultExchangeCapacity*2, network.NodeCosts[network.Exchange]/2)
+			}
+		}
+	}
+	checkBuilt(w, "after learning")
+
+	// Loading keeps what was applied without applying it again
+	b, err := json.Marshal(w)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var loaded World
+	err = json.Unmarshal(b, &loaded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := getExchangeMTBF(&loaded); got != mtbf*6 {
+		t.Errorf("exchange MTBF = %v after loading, want %v", got, mtbf*6)
+	}
+	if err := loaded.LearnTech("digital"); err != nil {
+		t.Fatal(err)
+	}
+	if got := getExchangeMTBF(&loaded); got != mtbf*6 {
+		t.Errorf("exchange MTBF = %v after learning again once loaded, want %v", got, mtbf*6)
+	}
+	checkBuilt(&loaded, "after loading")
+}
@@ -0,0 +1,141 @@
+package tech
+
+import (
+	"fmt"
+
+	"github.com/WhoBrokeTheBuild/TelcomSim/maintenance"
+)
+
+// Research is the progress of a game through a Tree. Without a Tree every
+// Equipment is unlocked and nothing is modified.
+type Research struct {
+	Tree  *Tree `json:",omitempty"`
+	Known map[string]bool
+	// Current is the Tech being researched, "" if none
+	Current string `json:",omitempty"`
+	// Remaining is the game days left on Current
+	Remaining float64 `json:",omitempty"`
+}
+
+// NewResearch returns the Research of a new game through tree, which may be nil,
+// knowing its Start Techs
+func NewResearch(tree *Tree) *Research {
+	r := &Research{
+		Tree:  tree,
+		Known: map[string]bool{},
+	}
+	if tree != nil {
+		for _, tech := range tree.Techs {
+			if tech.Start {
+				r.Known[tech.ID] = true
+			}
+		}
+	}
+	return r
+}
+
+// GetKnown returns the known Techs, in the order of the Tree
+func (r *Research) GetKnown() []*Tech {
+	known := []*Tech{}
+	if r.Tree != nil {
+		for _, tech := range r.Tree.Techs {
+			if r.Known[tech.ID] {
+				known = append(known, tech)
+			}
+		}
+	}
+	return known
+}
+
+// CanResearch returns nil if the Tech with id can be researched in year, or why not
+func (r *Research) CanResearch(id string, year int) error {
+	if r.Tree == nil {
+		return fmt.Errorf("No tech tree")
+	}
+	tech := r.Tree.GetTech(id)
+	if tech == nil {
+		return fmt.Errorf("No such tech [%v]", id)
+	}
+	if r.Known[id] {
+		return fmt.Errorf("[%v] is already researched", tech.Name)
+	}
+	if tech.Year > year {
+		return fmt.Errorf("[%v] can't be researched until %d", tech.Name, tech.Year)
+	}
+	for _, req := range tech.Requires {
+		if !r.Known[req] {
+			return fmt.Errorf("[%v] requires [%v]", tech.Name, r.Tree.GetTech(req).Name)
+		}
+	}
+	return nil
+}
+
+// Start starts researching the Tech with id, failing if it can't be researched
+// in year or another Tech is being researched. The Cost is the caller's to pay.
+func (r *Research) Start(id string, year int) error {
+	err := r.CanResearch(id, year)
+	if err != nil {
+		return err
+	}
+	if r.Current != "" {
+		return fmt.Errorf("Already researching [%v]", r.Tree.GetTech(r.Current).Name)
+	}
+	r.Current = id
+	r.Remaining = r.Tree.GetTech(id).Days
+	return nil
+}
+
+// Update advances the Current research by days, returning the Tech if it was completed
+func (r *Research) Update(days float64) *Tech {
+	if r.Current == "" {
+		return nil
+	}
+	r.Remaining -= days
+	if r.Remaining > 0 {
+		return nil
+	}
+
+	tech := r.Tree.GetTech(r.Current)
+	r.Known[r.Current] = true
+	r.Current = ""
+	r.Remaining = 0
+	return tech
+}
+
+// IsUnlocked returns whether e can be built
+func (r *Research) IsUnlocked(e maintenance.Equipment) bool {
+	if r.Tree == nil || !r.Tree.IsGated(e) {
+		return true
+	}
+	for _, tech := range r.GetKnown() {
+		for _, u := range tech.Unlocks {
+			if u == e {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// GetModifier returns the Modifier of every known Tech for e combined
+func (r *Research) GetModifier(e maintenance.Equipment) Modifier {
+	m := Modifier{Equipment: e, Capacity: 1, Cost: 1, MTBF: 1}
+	for _, tech := range r.GetKnown() {
+		for _, tm := range tech.Modifiers {
+			if tm.Equipment == e {
+				m.Capacity *= getFactor(tm.Capacity)
+				m.Cost *= getFactor(tm.Cost)
+				m.MTBF *= getFactor(tm.MTBF)
+			}
+		}
+	}
+	return m
+}
+
+// getFactor returns f, or 1 if it is unset
+func getFactor(f float64) float64 {
+	if f == 0 {
+		return 1
+	}
+	return f
+}
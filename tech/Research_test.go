@@ -0,0 +1,151 @@
+package tech
+
+import (
+	"testing"
+
+	"github.com/WhoBrokeTheBuild/TelcomSim/maintenance"
+)
+
+func TestCanResearch(t *testing.T) {
+	tests := []struct {
+		name    string
+		known   []string
+		id      string
+		year    int
+		wantErr bool
+	}{
+		{name: "available", id: "digital", year: 1990},
+		{name: "before its year", id: "digital", year: 1989, wantErr: true},
+		{name: "missing a requirement", id: "fiber", year: 1995, wantErr: true},
+		{name: "requirement known", known: []string{"digital"}, id: "fiber", year: 1995},
+		{name: "requirement known before its year", known: []string{"digital"}, id: "fiber", year: 1994, wantErr: true},
+		{name: "one of two requirements known", known: []string{"digital"}, id: "sdh", year: 2000, wantErr: true},
+		{name: "both requirements known", known: []string{"digital", "fiber"}, id: "sdh", year: 2000},
+		{name: "known from the start", id: "switching", year: 1990, wantErr: true},
+		{name: "missing", id: "satellite", year: 2000, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		r := NewResearch(newTestTree(t))
+		for _, id := range tt.known {
+			r.Known[id] = true
+		}
+		err := r.CanResearch(tt.id, tt.year)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%v: CanResearch error [%v], want error %v", tt.name, err, tt.wantErr)
+		}
+	}
+
+	if err := NewResearch(nil).CanResearch("digital", 2000); err == nil {
+		t.Errorf("researched without a tech tree")
+	}
+}
+
+func TestResearchUpdate(t *testing.T) {
+	r := NewResearch(newTestTree(t))
+	if got := r.Update(100); got != nil {
+		t.Errorf("Update finished %v with nothing being researched", got.ID)
+	}
+
+	err := r.Start("digital", 1990)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Start("digital", 1990); err == nil {
+		t.Errorf("started researching twice")
+	}
+
+	// Digital takes 10 days
+	if got := r.Update(4); got != nil || r.Remaining != 6 {
+		t.Errorf("after 4 days finished %v with %v days left, want nothing with 6", got, r.Remaining)
+	}
+	if r.Known["digital"] {
+		t.Errorf("known before it was finished")
+	}
+	got := r.Update(6)
+	if got == nil || got.ID != "digital" {
+		t.Fatalf("after 10 days finished %v, want digital", got)
+	}
+	if !r.Known["digital"] || r.Current != "" || r.Remaining != 0 {
+		t.Errorf("finished research left known %v, current %q and %v days", r.Known["digital"], r.Current, r.Remaining)
+	}
+	if got := r.Update(100); got != nil {
+		t.Errorf("Update finished %v a second time", got.ID)
+	}
+
+	// Another can start once it's done
+	if err := r.Start("fiber", 1995); err != nil {
+		t.Errorf("couldn't start the next tech [%v]", err)
+	}
+}
+
+func TestGetModifier(t *testing.T) {
+	tests := []struct {
+		name      string
+		known     []string
+		equipment maintenance.Equipment
+		want      Modifier
+	}{
+		{
+			name:      "none known",
+			equipment: maintenance.ExchangeEquipment,
+			want:      Modifier{Capacity: 1, Cost: 1, MTBF: 1},
+		},
+		{
+			name:      "one",
+			known:     []string{"digital"},
+			equipment: maintenance.ExchangeEquipment,
+			want:      Modifier{Capacity: 2, Cost: 0.5, MTBF: 1},
+		},
+		{
+			name:      "stacked",
+			known:     []string{"digital", "fiber", "sdh"},
+			equipment: maintenance.ExchangeEquipment,
+			want:      Modifier{Capacity: 3, Cost: 0.5, MTBF: 1},
+		},
+		{
+			name:      "stacked on another equipment",
+			known:     []string{"digital", "fiber", "sdh"},
+			equipment: maintenance.FiberEquipment,
+			want:      Modifier{Capacity: 4, Cost: 1, MTBF: 3},
+		},
+		{
+			name:      "unmodified equipment",
+			known:     []string{"digital", "fiber", "sdh"},
+			equipment: maintenance.CopperEquipment,
+			want:      Modifier{Capacity: 1, Cost: 1, MTBF: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		r := NewResearch(newTestTree(t))
+		for _, id := range tt.known {
+			r.Known[id] = true
+		}
+		tt.want.Equipment = tt.equipment
+		if got := r.GetModifier(tt.equipment); got != tt.want {
+			t.Errorf("%v: GetModifier() = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+
+	if got := NewResearch(nil).GetModifier(maintenance.FiberEquipment); got.Capacity != 1 || got.Cost != 1 || got.MTBF != 1 {
+		t.Errorf("GetModifier() without a tree = %+v, want no change", got)
+	}
+}
+
+func TestIsUnlocked(t *testing.T) {
+	r := NewResearch(newTestTree(t))
+	if r.IsUnlocked(maintenance.FiberEquipment) {
+		t.Errorf("fiber is unlocked before it is researched")
+	}
+	if !r.IsUnlocked(maintenance.CopperEquipment) {
+		t.Errorf("copper, which no tech unlocks, is locked")
+	}
+	r.Known["fiber"] = true
+	if !r.IsUnlocked(maintenance.FiberEquipment) {
+		t.Errorf("fiber is locked once researched")
+	}
+	if !NewResearch(nil).IsUnlocked(maintenance.FiberEquipment) {
+		t.Errorf("fiber is locked without a tech tree")
+	}
+}
@@ -0,0 +1,103 @@
+package tech
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/WhoBrokeTheBuild/TelcomSim/maintenance"
+)
+
+// Modifier changes the Equipment built after a Tech is researched. Factors of
+// 0 leave the property unchanged.
+type Modifier struct {
+	Equipment maintenance.Equipment
+	// Capacity multiplies the calls or channels of new equipment
+	Capacity float64 `json:",omitempty"`
+	// Cost multiplies what new equipment costs to build
+	Cost float64 `json:",omitempty"`
+	// MTBF multiplies the mean time between failures of all the equipment
+	MTBF float64 `json:",omitempty"`
+}
+
+// Tech is a technology that can be researched once its Year has come and the
+// Techs it Requires are known
+type Tech struct {
+	ID          string
+	Name        string
+	Description string
+	// Year is the first year it can be researched in
+	Year int
+	// Cost is paid when research starts, and Days is how long it takes
+	Cost float64
+	Days float64
+	// Start Techs are known from the beginning of a game
+	Start bool `json:",omitempty"`
+
+	Requires []string `json:",omitempty"`
+	// Unlocks is the Equipment that can't be built until the Tech is known
+	Unlocks   []maintenance.Equipment `json:",omitempty"`
+	Modifiers []Modifier              `json:",omitempty"`
+}
+
+// Tree is every Tech, each listed after the Techs it Requires
+type Tree struct {
+	Techs []*Tech
+}
+
+// ParseTree reads a Tree from JSON, checking that every Tech it Requires is listed before it
+func ParseTree(b []byte) (*Tree, error) {
+	t := &Tree{}
+	err := json.Unmarshal(b, t)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse tech tree [%v]", err)
+	}
+
+	seen := map[string]bool{}
+	for _, tech := range t.Techs {
+		if tech.ID == "" || seen[tech.ID] {
+			return nil, fmt.Errorf("Missing or duplicate tech ID [%v]", tech.ID)
+		}
+		for _, id := range tech.Requires {
+			if !seen[id] {
+				return nil, fmt.Errorf("Tech [%v] requires [%v], which isn't listed before it", tech.ID, id)
+			}
+		}
+		seen[tech.ID] = true
+	}
+	return t, nil
+}
+
+// GetTech returns the Tech with id, or nil
+func (t *Tree) GetTech(id string) *Tech {
+	for _, tech := range t.Techs {
+		if tech.ID == id {
+			return tech
+		}
+	}
+	return nil
+}
+
+// GetTier returns how many Techs deep the longest chain a Tech requires is, 0 for a Tech requiring none
+func (t *Tree) GetTier(id string) int {
+	tier := 0
+	if tech := t.GetTech(id); tech != nil {
+		for _, req := range tech.Requires {
+			if r := t.GetTier(req) + 1; r > tier {
+				tier = r
+			}
+		}
+	}
+	return tier
+}
+
+// IsGated returns whether any Tech Unlocks e, so it can't be built before it is known
+func (t *Tree) IsGated(e maintenance.Equipment) bool {
+	for _, tech := range t.Techs {
+		for _, u := range tech.Unlocks {
+			if u == e {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,72 @@
+package tech
+
+import (
+	"testing"
+)
+
+// testTree is a chain of Techs, each modifying what the ones before it did
+const testTree = `{"Techs": [
+	{"ID": "switching", "Name": "Switching", "Year": 1990, "Start": true},
+	{
+		"ID": "digital", "Name": "Digital Switching", "Year": 1990, "Cost": 50000, "Days": 10,
+		"Requires": ["switching"],
+		"Modifiers": [{"Equipment": "exchange", "Capacity": 2, "Cost": 0.5}]
+	},
+	{
+		"ID": "fiber", "Name": "Fiber Optics", "Year": 1995, "Cost": 80000, "Days": 20,
+		"Requires": ["digital"],
+		"Unlocks": ["fiber"],
+		"Modifiers": [{"Equipment": "fiber", "MTBF": 2}]
+	},
+	{
+		"ID": "sdh", "Name": "SDH", "Year": 1998, "Cost": 100000, "Days": 30,
+		"Requires": ["fiber", "digital"],
+		"Modifiers": [
+			{"Equipment": "fiber", "Capacity": 4, "MTBF": 1.5},
+			{"Equipment": "exchange", "Capacity": 1.5}
+		]
+	}
+]}`
+
+// newTestTree returns testTree parsed
+func newTestTree(t *testing.T) *Tree {
+	tree, err := ParseTree([]byte(testTree))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tree
+}
+
+func TestParseTree(t *testing.T) {
+	tests := []struct {
+		name    string
+		json    string
+		wantErr bool
+	}{
+		{name: "valid", json: testTree},
+		{name: "empty", json: `{"Techs": []}`},
+		{name: "duplicate ID", json: `{"Techs": [{"ID": "a"}, {"ID": "b"}, {"ID": "a"}]}`, wantErr: true},
+		{name: "missing ID", json: `{"Techs": [{"Name": "A"}]}`, wantErr: true},
+		{name: "unknown requirement", json: `{"Techs": [{"ID": "a", "Requires": ["z"]}]}`, wantErr: true},
+		{name: "requirement listed after", json: `{"Techs": [{"ID": "a", "Requires": ["b"]}, {"ID": "b"}]}`, wantErr: true},
+		{name: "requiring itself", json: `{"Techs": [{"ID": "a", "Requires": ["a"]}]}`, wantErr: true},
+		{name: "bad JSON", json: `{"Techs": [`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		_, err := ParseTree([]byte(tt.json))
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%v: ParseTree error [%v], want error %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestGetTier(t *testing.T) {
+	tree := newTestTree(t)
+	tests := map[string]int{"switching": 0, "digital": 1, "fiber": 2, "sdh": 3, "missing": 0}
+	for id, want := range tests {
+		if got := tree.GetTier(id); got != want {
+			t.Errorf("GetTier(%v) = %d, want %d", id, got, want)
+		}
+	}
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"strings"
+
+	"github.com/WhoBrokeTheBuild/TelcomSim/context"
+	"github.com/WhoBrokeTheBuild/TelcomSim/data"
+	"github.com/WhoBrokeTheBuild/TelcomSim/log"
+	"github.com/WhoBrokeTheBuild/TelcomSim/sim"
+	"github.com/WhoBrokeTheBuild/TelcomSim/tech"
+	"github.com/WhoBrokeTheBuild/TelcomSim/ui"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// techTreeFile is the tech tree every new game researches through
+const techTreeFile = "tech/tree.json"
+
+var (
+	techKnownColor     = color.RGBA{40, 120, 40, 230}
+	techCurrentColor   = color.RGBA{170, 130, 20, 230}
+	techAvailableColor = color.RGBA{40, 80, 160, 230}
+	techLockedColor    = color.RGBA{70, 70, 70, 230}
+)
+
+// techTreeOrigin is where the first Tech is shown, techTreeCell the space each takes
+var (
+	techTreeOrigin = mgl32.Vec2{20, 50}
+	techTreeCell   = mgl32.Vec2{190, 34}
+)
+
+// loadTechTree reads the tech tree from techTreeFile
+func loadTechTree() (*tech.Tree, error) {
+	log.Loadf("tech.Tree [%v]", techTreeFile)
+	b, err := data.Asset(techTreeFile)
+	if err != nil {
+		return nil, err
+	}
+	return tech.ParseTree(b)
+}
+
+// techTree shows the tech tree of a World as a Button for each Tech, in a column
+// for each tier. Clicking a Tech selects it and describes it, clicking it again
+// researches it.
+type techTree struct {
+	World *sim.World
+	Shown bool
+
+	Buttons map[string]*ui.Button
+	Info    *ui.Text
+
+	tree     *tech.Tree
+	selected string
+}
+
+// newTechTree returns a hidden techTree of world
+func newTechTree(world *sim.World) *techTree {
+	return &techTree{
+		World:   world,
+		Buttons: map[string]*ui.Button{},
+	}
+}
+
+// Delete frees all resources owned by the techTree
+func (c *techTree) Delete() {
+	for _, b := range c.Buttons {
+		b.Delete()
+	}
+	c.Buttons = map[string]*ui.Button{}
+	if c.Info != nil {
+		c.Info.Delete()
+		c.Info = nil
+	}
+	c.tree = nil
+}
+
+// build creates a Button for each Tech of the World's tree
+func (c *techTree) build() {
+	c.Delete()
+	c.tree = c.World.Research.Tree
+	c.selected = ""
+	if c.tree == nil {
+		return
+	}
+
+	rows := map[int]int{}
+	for _, t := range c.tree.Techs {
+		id := t.ID
+		b := ui.NewButton(fmt.Sprintf("%v %d", t.Name, t.Year), "ui/default.ttf", 16.0, color.White, techLockedColor)
+		if b == nil {
+			continue
+		}
+
+		tier := c.tree.GetTier(id)
+		b.SetPosition(techTreeOrigin.Add(mgl32.Vec2{float32(tier) * techTreeCell.X(), float32(rows[tier]) * techTreeCell.Y()}))
+		b.SetSize(mgl32.Vec2{techTreeCell.X() - 10, b.GetSize().Y()})
+		b.OnClick = func() { c.click(id) }
+		rows[tier]++
+		c.Buttons[id] = b
+	}
+
+	// Text can't render an empty string
+	c.Info = ui.NewText(" ", "ui/default.ttf", 16.0, color.White)
+	if c.Info != nil {
+		c.Info.SetPosition(mgl32.Vec2{techTreeOrigin.X(), float32(windowHeight) - 60})
+	}
+}
+
+// click selects a Tech, or researches it if it is already selected
+func (c *techTree) click(id string) {
+	if c.selected == id && c.World.Research.CanResearch(id, c.World.Calendar.GetYear()) == nil {
+		c.World.Queue(sim.Command{Type: sim.StartResearch, Name: id})
+		return
+	}
+	c.selected = id
+}
+
+// Update handles clicks while the techTree is shown, and keeps it up to date with the World
+func (c *techTree) Update(ctx *context.Update) {
+	if !c.Shown {
+		return
+	}
+	if c.tree != c.World.Research.Tree {
+		c.build()
+	}
+
+	r := c.World.Research
+	year := c.World.Calendar.GetYear()
+	for id, b := range c.Buttons {
+		switch {
+		case r.Known[id]:
+			b.SetColor(techKnownColor)
+		case r.Current == id:
+			b.SetColor(techCurrentColor)
+		case r.CanResearch(id, year) == nil:
+			b.SetColor(techAvailableColor)
+		default:
+			b.SetColor(techLockedColor)
+		}
+		b.Update(ctx)
+	}
+
+	if c.Info != nil {
+		if text := c.describe(c.selected); text != c.Info.Text {
+			c.Info.SetText(text)
+		}
+	}
+}
+
+// describe returns what a Tech does and whether it can be researched
+func (c *techTree) describe(id string) string {
+	t := c.tree.GetTech(id)
+	if t == nil {
+		return "Click a technology to see what it does"
+	}
+
+	parts := []string{t.Name + ": " + t.Description}
+	r := c.World.Research
+	switch {
+	case r.Known[id]:
+		parts = append(parts, "Researched")
+	case r.Current == id:
+		parts = append(parts, fmt.Sprintf("%.0f days left", r.Remaining))
+	default:
+		parts = append(parts, fmt.Sprintf("$%.0f over %.0f days", t.Cost, t.Days))
+		if err := r.CanResearch(id, c.World.Calendar.GetYear()); err != nil {
+			parts = append(parts, err.Error())
+		} else {
+			parts = append(parts, "Click again to research")
+		}
+	}
+	return strings.Join(parts, ". ")
+}
+
+// Draw renders the techTree, if it is shown
+func (c *techTree) Draw(ctx *context.Render) {
+	if !c.Shown || c.tree == nil {
+		return
+	}
+	for _, t := range c.tree.Techs {
+		if b, found := c.Buttons[t.ID]; found {
+			b.Draw(ctx)
+		}
+	}
+	if c.Info != nil {
+		c.Info.Draw(ctx)
+	}
+}
@@ -0,0 +1,186 @@
+package terrain
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	// Maps are authored as PNG
+	_ "image/png"
+	"math"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// Map is a grid of cells with a height and a population, read from grayscale
+// images. Positions on a Map are in kilometers, cell (0, 0) starting at the
+// origin, with X across and Y (Z in the scene) down the images.
+type Map struct {
+	// Width and Depth are the number of cells across and down
+	Width int
+	Depth int
+	// CellSize is the size of a cell in kilometers
+	CellSize float32
+	// MaxHeight is the height in kilometers of a white height pixel
+	MaxHeight float32
+	// MaxPopulation is the number of people in a cell of a white population pixel
+	MaxPopulation float32
+
+	// Heights and Population hold one gray level per cell, row by row. They are
+	// saved with the Map, so saves don't depend on the images it was read from.
+	Heights    []uint8
+	Population []uint8
+}
+
+// MapConfig is how the gray levels of a Map's images are scaled
+type MapConfig struct {
+	CellSize      float32
+	MaxHeight     float32
+	MaxPopulation float32
+}
+
+// NewMap returns a new Map from a height image and a population image of the
+// same size. The population image is optional, without one nobody lives there.
+func NewMap(heights, population image.Image, cfg MapConfig) (*Map, error) {
+	b := heights.Bounds()
+	m := &Map{
+		Width:         b.Dx(),
+		Depth:         b.Dy(),
+		CellSize:      cfg.CellSize,
+		MaxHeight:     cfg.MaxHeight,
+		MaxPopulation: cfg.MaxPopulation,
+	}
+	if m.Width < 2 || m.Depth < 2 {
+		return nil, fmt.Errorf("Height map is %dx%d, expected at least 2x2", m.Width, m.Depth)
+	}
+	if m.CellSize <= 0 {
+		return nil, fmt.Errorf("Invalid cell size [%v]", m.CellSize)
+	}
+
+	m.Heights = toGray(heights)
+	if population != nil {
+		pb := population.Bounds()
+		if pb.Dx() != m.Width || pb.Dy() != m.Depth {
+			return nil, fmt.Errorf("Population map is %dx%d, expected %dx%d like the height map", pb.Dx(), pb.Dy(), m.Width, m.Depth)
+		}
+		m.Population = toGray(population)
+	} else {
+		m.Population = make([]uint8, m.Width*m.Depth)
+	}
+
+	return m, nil
+}
+
+// DecodeMap returns a new Map from encoded height and population images, population may be nil
+func DecodeMap(heights, population []byte, cfg MapConfig) (*Map, error) {
+	himg, _, err := image.Decode(bytes.NewReader(heights))
+	if err != nil {
+		return nil, fmt.Errorf("Failed to decode height map: %v", err)
+	}
+
+	var pimg image.Image
+	if population != nil {
+		pimg, _, err = image.Decode(bytes.NewReader(population))
+		if err != nil {
+			return nil, fmt.Errorf("Failed to decode population map: %v", err)
+		}
+	}
+
+	return NewMap(himg, pimg, cfg)
+}
+
+// toGray returns the gray level of every pixel, row by row
+func toGray(img image.Image) []uint8 {
+	b := img.Bounds()
+	gray := make([]uint8, 0, b.Dx()*b.Dy())
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			r, g, bl, _ := img.At(x, y).RGBA()
+			// Rec. 601 luma, for images that aren't saved as grayscale
+			gray = append(gray, uint8((299*r+587*g+114*bl)/1000>>8))
+		}
+	}
+	return gray
+}
+
+// GetSize returns the size of the Map in kilometers
+func (m *Map) GetSize() mgl32.Vec2 {
+	return mgl32.Vec2{float32(m.Width) * m.CellSize, float32(m.Depth) * m.CellSize}
+}
+
+// Contains returns whether pos is on the Map
+func (m *Map) Contains(pos mgl32.Vec2) bool {
+	size := m.GetSize()
+	return pos[0] >= 0 && pos[1] >= 0 && pos[0] < size[0] && pos[1] < size[1]
+}
+
+// GetCell returns the cell containing pos, clamped to the Map
+func (m *Map) GetCell(pos mgl32.Vec2) (int, int) {
+	x := clampInt(int(pos[0]/m.CellSize), 0, m.Width-1)
+	z := clampInt(int(pos[1]/m.CellSize), 0, m.Depth-1)
+	return x, z
+}
+
+// GetCellCenter returns the position of the center of a cell
+func (m *Map) GetCellCenter(x, z int) mgl32.Vec2 {
+	return mgl32.Vec2{(float32(x) + 0.5) * m.CellSize, (float32(z) + 0.5) * m.CellSize}
+}
+
+// GetCellHeight returns the height of a cell in kilometers, cells off the Map take the nearest edge
+func (m *Map) GetCellHeight(x, z int) float32 {
+	x = clampInt(x, 0, m.Width-1)
+	z = clampInt(z, 0, m.Depth-1)
+	return float32(m.Heights[z*m.Width+x]) / 255 * m.MaxHeight
+}
+
+// GetCellPopulation returns the number of people in a cell, 0 off the Map
+func (m *Map) GetCellPopulation(x, z int) float32 {
+	if x < 0 || z < 0 || x >= m.Width || z >= m.Depth {
+		return 0
+	}
+	return float32(m.Population[z*m.Width+x]) / 255 * m.MaxPopulation
+}
+
+// GetHeight returns the height at pos, interpolated between the cell centers
+func (m *Map) GetHeight(pos mgl32.Vec2) float32 {
+	fx := pos[0]/m.CellSize - 0.5
+	fz := pos[1]/m.CellSize - 0.5
+	x0 := int(math.Floor(float64(fx)))
+	z0 := int(math.Floor(float64(fz)))
+	tx := fx - float32(x0)
+	tz := fz - float32(z0)
+
+	h00 := m.GetCellHeight(x0, z0)
+	h10 := m.GetCellHeight(x0+1, z0)
+	h01 := m.GetCellHeight(x0, z0+1)
+	h11 := m.GetCellHeight(x0+1, z0+1)
+
+	top := h00 + (h10-h00)*tx
+	bottom := h01 + (h11-h01)*tx
+	return top + (bottom-top)*tz
+}
+
+// GetNormal returns the surface normal at a cell center
+func (m *Map) GetNormal(x, z int) mgl32.Vec3 {
+	dx := m.GetCellHeight(x+1, z) - m.GetCellHeight(x-1, z)
+	dz := m.GetCellHeight(x, z+1) - m.GetCellHeight(x, z-1)
+	return mgl32.Vec3{-dx, 2 * m.CellSize, -dz}.Normalize()
+}
+
+// GetTotalPopulation returns the number of people on the Map
+func (m *Map) GetTotalPopulation() float32 {
+	total := float32(0)
+	for _, p := range m.Population {
+		total += float32(p)
+	}
+	return total / 255 * m.MaxPopulation
+}
+
+func clampInt(v, min, max int) int {
+	if v < min {
+		return min
+	}
+	if v > max {
+		return max
+	}
+	return v
+}
@@ -0,0 +1,116 @@
+package terrain
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+	"testing"
+)
+
+// encodePNG returns img encoded as a PNG
+func encodePNG(t *testing.T, img image.Image) []byte {
+	buf := &bytes.Buffer{}
+	if err := png.Encode(buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// newGray returns a width by depth image with the gray level of each pixel from levels, row by row
+func newGray(width, depth int, levels ...uint8) *image.Gray {
+	img := image.NewGray(image.Rect(0, 0, width, depth))
+	copy(img.Pix, levels)
+	return img
+}
+
+func TestDecodeMap(t *testing.T) {
+	heights := encodePNG(t, newGray(3, 2,
+		0, 51, 102,
+		153, 204, 255,
+	))
+	population := encodePNG(t, newGray(3, 2,
+		255, 0, 0,
+		0, 0, 51,
+	))
+	cfg := MapConfig{CellSize: 0.5, MaxHeight: 2, MaxPopulation: 100}
+
+	m, err := DecodeMap(heights, population, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Width != 3 || m.Depth != 2 {
+		t.Fatalf("map is %dx%d, want 3x2", m.Width, m.Depth)
+	}
+	if size := m.GetSize(); size[0] != 1.5 || size[1] != 1 {
+		t.Errorf("GetSize() = %v, want [1.5 1]", size)
+	}
+
+	cells := []struct {
+		x, z       int
+		height     float32
+		population float32
+	}{
+		{x: 0, z: 0, height: 0, population: 100},
+		{x: 1, z: 0, height: 0.4, population: 0},
+		{x: 2, z: 1, height: 2, population: 20},
+		// Off the Map, heights take the nearest edge and nobody lives
+		{x: 3, z: 1, height: 2, population: 0},
+		{x: -1, z: 0, height: 0, population: 0},
+	}
+	for _, c := range cells {
+		if h := m.GetCellHeight(c.x, c.z); math.Abs(float64(h-c.height)) > 1e-6 {
+			t.Errorf("GetCellHeight(%d, %d) = %v, want %v", c.x, c.z, h, c.height)
+		}
+		if p := m.GetCellPopulation(c.x, c.z); math.Abs(float64(p-c.population)) > 1e-4 {
+			t.Errorf("GetCellPopulation(%d, %d) = %v, want %v", c.x, c.z, p, c.population)
+		}
+	}
+	if total := m.GetTotalPopulation(); math.Abs(float64(total-120)) > 1e-4 {
+		t.Errorf("GetTotalPopulation() = %v, want 120", total)
+	}
+}
+
+func TestDecodeMapColor(t *testing.T) {
+	// Color images are read by their luma
+	img := image.NewRGBA(image.Rect(0, 0, 2, 2))
+	img.Set(0, 0, color.RGBA{255, 255, 255, 255})
+	img.Set(1, 0, color.RGBA{0, 255, 0, 255})
+
+	m, err := DecodeMap(encodePNG(t, img), nil, MapConfig{CellSize: 1, MaxHeight: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []uint8{255, 150, 0, 0}; !bytes.Equal(m.Heights, want) {
+		t.Errorf("Heights = %v, want %v", m.Heights, want)
+	}
+	// Without a population map nobody lives there
+	if len(m.Population) != 4 || m.GetTotalPopulation() != 0 {
+		t.Errorf("Population = %v, want 4 empty cells", m.Population)
+	}
+}
+
+func TestDecodeMapErrors(t *testing.T) {
+	heights := encodePNG(t, newGray(3, 2))
+	cfg := MapConfig{CellSize: 1, MaxHeight: 1, MaxPopulation: 1}
+
+	tests := []struct {
+		name       string
+		heights    []byte
+		population []byte
+		cfg        MapConfig
+	}{
+		{name: "invalid heights", heights: []byte("not a png"), cfg: cfg},
+		{name: "invalid population", heights: heights, population: []byte("not a png"), cfg: cfg},
+		{name: "population of another size", heights: heights, population: encodePNG(t, newGray(2, 3)), cfg: cfg},
+		{name: "too small", heights: encodePNG(t, newGray(1, 4)), cfg: cfg},
+		{name: "no cell size", heights: heights, cfg: MapConfig{MaxHeight: 1}},
+	}
+
+	for _, tt := range tests {
+		if _, err := DecodeMap(tt.heights, tt.population, tt.cfg); err == nil {
+			t.Errorf("%v: DecodeMap() returned no error", tt.name)
+		}
+	}
+}
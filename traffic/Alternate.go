@@ -0,0 +1,158 @@
+package traffic
+
+import (
+	"sort"
+
+	"github.com/WhoBrokeTheBuild/TelcomSim/network"
+)
+
+// Alternate is a Router using classic hierarchical alternate routing. Each
+// exchange tries the direct trunk to the destination first, then overflows to
+// alternate trunks that bring the call closer to it, in order. When the call
+// reaches an exchange with no way onward it cranks back to the previous exchange,
+// which tries its next alternate.
+type Alternate struct {
+	// MaxAlternates is how many trunks each exchange tries, including the direct one
+	MaxAlternates int
+	// MaxCrankbacks is how many times a call may back out before it is blocked
+	MaxCrankbacks int
+}
+
+// NewAlternate returns a new Alternate Router
+func NewAlternate() *Alternate {
+	return &Alternate{
+		MaxAlternates: 3,
+		MaxCrankbacks: 4,
+	}
+}
+
+// GetName returns the name NewRouter creates the Router from
+func (a *Alternate) GetName() string {
+	return "alternate"
+}
+
+// Route returns the first Route found trying trunks in order, or nil if the call is blocked
+func (a *Alternate) Route(net *network.Network, usage Usage, from, to network.NodeID) *Route {
+	if usage.GetFreeNodeCapacity(from) <= 0 || usage.GetFreeNodeCapacity(to) <= 0 {
+		return nil
+	}
+
+	s := &alternateSearch{
+		Alternate: a,
+		net:       net,
+		usage:     usage,
+		to:        to,
+		hops:      getHopCounts(net, to),
+		visited:   map[network.NodeID]bool{from: true},
+		via:       map[network.NodeID]network.LinkID{},
+	}
+	if _, reachable := s.hops[from]; !reachable {
+		return nil
+	}
+
+	if !s.search(from) {
+		return nil
+	}
+	r := buildRoute(net, s.via, from, to)
+	r.Crankbacks = s.crankbacks
+	return r
+}
+
+// alternateSearch is the state of one call setup by an Alternate Router
+type alternateSearch struct {
+	*Alternate
+	net   *network.Network
+	usage Usage
+	to    network.NodeID
+	// hops is the routing table, the fewest hops from each Node to the destination
+	hops map[network.NodeID]int
+	// visited holds the Nodes of the path being tried, so it never loops
+	visited    map[network.NodeID]bool
+	via        map[network.NodeID]network.LinkID
+	crankbacks int
+}
+
+func (s *alternateSearch) search(id network.NodeID) bool {
+	if id == s.to {
+		return true
+	}
+
+	for _, link := range s.getCandidates(id) {
+		other := link.Other(id)
+		if !tryEnter(s.net, s.usage, link, other, s.to) {
+			continue
+		}
+
+		s.visited[other] = true
+		s.via[other] = link.ID
+		if s.search(other) {
+			return true
+		}
+		// Only the current path is visited, so a later alternate may pass through other
+		delete(s.visited, other)
+		delete(s.via, other)
+
+		s.crankbacks++
+		if s.crankbacks > s.MaxCrankbacks {
+			return false
+		}
+	}
+	return false
+}
+
+// getCandidates returns the trunks an exchange tries in order: those reaching
+// the destination in the fewest hops first, then the lowest latency
+func (s *alternateSearch) getCandidates(id network.NodeID) []*network.Link {
+	candidates := []*network.Link{}
+	for _, link := range s.net.GetLinksOf(id) {
+		other := link.Other(id)
+		if s.visited[other] {
+			continue
+		}
+		// Only trunks that get no farther from the destination are alternates
+		if h, found := s.hops[other]; !found || h > s.hops[id] {
+			continue
+		}
+		candidates = append(candidates, link)
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		hi, hj := s.hops[candidates[i].Other(id)], s.hops[candidates[j].Other(id)]
+		if hi != hj {
+			return hi < hj
+		}
+		return candidates[i].Latency < candidates[j].Latency
+	})
+
+	if s.MaxAlternates > 0 && len(candidates) > s.MaxAlternates {
+		candidates = candidates[:s.MaxAlternates]
+	}
+	return candidates
+}
+
+// getHopCounts returns the fewest hops from every Node to `to`, ignoring capacity.
+// Equipment that is down and subscribers other than `to` are left out, as calls
+// never pass through them.
+func getHopCounts(net *network.Network, to network.NodeID) map[network.NodeID]int {
+	hops := map[network.NodeID]int{to: 0}
+	queue := []network.NodeID{to}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+
+		if id != to && net.GetNode(id).Type == network.Subscriber {
+			continue
+		}
+		for _, link := range net.GetLinksOf(id) {
+			other := link.Other(id)
+			if link.Down || net.GetNode(other).Down {
+				continue
+			}
+			if _, found := hops[other]; !found {
+				hops[other] = hops[id] + 1
+				queue = append(queue, other)
+			}
+		}
+	}
+	return hops
+}
@@ -0,0 +1,119 @@
+package traffic
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/WhoBrokeTheBuild/TelcomSim/network"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// testUsage has one circuit free on every Link but those in full
+type testUsage struct {
+	full map[network.LinkID]bool
+}
+
+func (u testUsage) GetFreeLinkCapacity(id network.LinkID) int {
+	if u.full[id] {
+		return 0
+	}
+	return 1
+}
+
+func (u testUsage) GetFreeNodeCapacity(id network.NodeID) int {
+	return 1
+}
+
+func (u testUsage) Offer(id network.LinkID) {
+}
+
+// TestAlternateDiamond routes from A to Z over a diamond through R and L, with
+// the first choice through R full. R also reaches Z through the tandem T.
+func TestAlternateDiamond(t *testing.T) {
+	tests := []struct {
+		name          string
+		maxAlternates int
+		// full are the Links with no free circuit, by the names of their ends
+		full []string
+		want []string
+	}{
+		{
+			name:          "overflows across the diamond",
+			maxAlternates: 3,
+			full:          []string{"RZ"},
+			want:          []string{"A", "R", "L", "Z"},
+		},
+		{
+			name:          "overflows to the tandem",
+			maxAlternates: 3,
+			full:          []string{"RZ", "LZ"},
+			want:          []string{"A", "R", "T", "Z"},
+		},
+		{
+			// R only tries Z and L, so the tandem is reached by cranking back to
+			// A and passing through R again from L
+			name:          "passes through a node of a failed branch",
+			maxAlternates: 2,
+			full:          []string{"RZ", "LZ"},
+			want:          []string{"A", "L", "R", "T", "Z"},
+		},
+		{
+			name:          "blocks when every trunk to Z is full",
+			maxAlternates: 3,
+			full:          []string{"RZ", "LZ", "TZ"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			net := network.NewNetwork()
+			nodes := map[string]*network.Node{}
+			for _, n := range []struct {
+				name string
+				pos  mgl32.Vec2
+			}{
+				{"A", mgl32.Vec2{0, 0}},
+				{"R", mgl32.Vec2{5, 1}},
+				{"L", mgl32.Vec2{5, -3}},
+				{"T", mgl32.Vec2{9, 4}},
+				{"Z", mgl32.Vec2{10, 0}},
+			} {
+				nodes[n.name] = net.AddExchange(n.name, n.pos)
+			}
+
+			links := map[string]network.LinkID{}
+			for _, ends := range []string{"AR", "AL", "RL", "RT", "RZ", "LZ", "TZ"} {
+				l, err := net.Connect(nodes[ends[:1]].ID, nodes[ends[1:]].ID, network.Fiber)
+				if err != nil {
+					t.Fatal(err)
+				}
+				links[ends] = l.ID
+			}
+			usage := testUsage{full: map[network.LinkID]bool{}}
+			for _, ends := range tt.full {
+				usage.full[links[ends]] = true
+			}
+
+			a := NewAlternate()
+			a.MaxAlternates = tt.maxAlternates
+			r := a.Route(net, usage, nodes["A"].ID, nodes["Z"].ID)
+
+			if tt.want == nil {
+				if r != nil {
+					t.Fatalf("routed a call that should be blocked")
+				}
+				return
+			}
+			if r == nil {
+				t.Fatalf("blocked a call with a free route through %v", tt.want)
+			}
+			got := []string{}
+			for _, id := range r.Nodes {
+				got = append(got, net.GetNode(id).Name)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("routed through %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
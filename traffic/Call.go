@@ -0,0 +1,87 @@
+package traffic
+
+import (
+	"github.com/WhoBrokeTheBuild/TelcomSim/network"
+)
+
+// Call is a call in progress between two Subscriber nodes
+type Call struct {
+	ID   uint64
+	From network.NodeID
+	To   network.NodeID
+	// Home is the exchange the Call originated at, whose routing policy chose its route
+	Home network.NodeID
+	// Links and Nodes are the route of the Call, Nodes including both ends
+	Links []network.LinkID
+	Nodes []network.NodeID
+
+	// Start and End are seconds of simulated time
+	Start float64
+	End   float64
+}
+
+// callQueue orders active Calls by when they end, as a container/heap
+type callQueue []*Call
+
+func (q callQueue) Len() int {
+	return len(q)
+}
+
+func (q callQueue) Less(i, j int) bool {
+	if q[i].End == q[j].End {
+		return q[i].ID < q[j].ID
+	}
+	return q[i].End < q[j].End
+}
+
+func (q callQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+}
+
+func (q *callQueue) Push(x interface{}) {
+	*q = append(*q, x.(*Call))
+}
+
+func (q *callQueue) Pop() interface{} {
+	old := *q
+	c := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return c
+}
+
+// arrival is when the next call from a region arrives
+type arrival struct {
+	Time   float64
+	Region *network.Node
+	// Order is the index of the region in the Network, which arrives first on a tie
+	Order int
+}
+
+// arrivalQueue orders regions by their next arrival, as a container/heap
+type arrivalQueue []arrival
+
+func (q arrivalQueue) Len() int {
+	return len(q)
+}
+
+func (q arrivalQueue) Less(i, j int) bool {
+	if q[i].Time == q[j].Time {
+		return q[i].Order < q[j].Order
+	}
+	return q[i].Time < q[j].Time
+}
+
+func (q arrivalQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+}
+
+func (q *arrivalQueue) Push(x interface{}) {
+	*q = append(*q, x.(arrival))
+}
+
+func (q *arrivalQueue) Pop() interface{} {
+	old := *q
+	a := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return a
+}
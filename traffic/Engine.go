@@ -0,0 +1,576 @@
+package traffic
+
+import (
+	"container/heap"
+	"encoding/json"
+	"fmt"
+	"math"
+
+	"github.com/WhoBrokeTheBuild/TelcomSim/events"
+	"github.com/WhoBrokeTheBuild/TelcomSim/network"
+	"github.com/WhoBrokeTheBuild/TelcomSim/rng"
+)
+
+// RegionConfig is the calling behavior of the lines of a Subscriber node
+type RegionConfig struct {
+	// CallsPerLineHour is the mean rate each line places calls, arrivals are Poisson
+	CallsPerLineHour float64
+	// HoldingTime is the mean length of a call in seconds, lengths are exponential
+	HoldingTime float64
+}
+
+// DefaultRegionConfig is a busy hour load of 0.1 erlangs per line
+var DefaultRegionConfig = RegionConfig{
+	CallsPerLineHour: 2,
+	HoldingTime:      180,
+}
+
+// Engine generates calls between the Subscriber nodes of a Network and routes
+// them over the capacity of its Links and Nodes. Given the same Network and seed
+// it always produces the same results.
+type Engine struct {
+	Network *network.Network
+	Rand    *rng.Rand
+	// Default is used by every Subscriber node without a RegionConfig in Regions
+	Default RegionConfig
+	Regions map[network.NodeID]RegionConfig
+
+	// Router routes the calls of every exchange without a Router in Policies
+	Router   Router
+	Policies map[network.NodeID]Router
+
+	Stats Stats
+	// Time is the seconds simulated
+	Time float64
+
+	// Events receives a CallBlocked for every blocked call and a LinkSaturated
+	// whenever a Link's last circuit is seized, if set
+	Events *events.Bus
+
+	active        callQueue
+	nextCallID    uint64
+	nextArrival   map[network.NodeID]float64
+	linkStats     map[network.LinkID]*LinkStats
+	nodeUsed      map[network.NodeID]int
+	exchangeStats map[network.NodeID]*Stats
+	regionStats   map[network.NodeID]*Stats
+
+	// offered holds the Links the call being routed tried to seize, nil between calls
+	offered map[network.LinkID]bool
+}
+
+// NewEngine returns a new Engine generating calls on net, seeded with seed
+func NewEngine(net *network.Network, seed int64) *Engine {
+	return &Engine{
+		Network:       net,
+		Rand:          rng.New(seed),
+		Default:       DefaultRegionConfig,
+		Regions:       map[network.NodeID]RegionConfig{},
+		Router:        NewShortestPath(ByLatency),
+		Policies:      map[network.NodeID]Router{},
+		nextCallID:    1,
+		nextArrival:   map[network.NodeID]float64{},
+		linkStats:     map[network.LinkID]*LinkStats{},
+		nodeUsed:      map[network.NodeID]int{},
+		exchangeStats: map[network.NodeID]*Stats{},
+		regionStats:   map[network.NodeID]*Stats{},
+	}
+}
+
+// GetRegionConfig returns the RegionConfig of a Subscriber node
+func (e *Engine) GetRegionConfig(id network.NodeID) RegionConfig {
+	if rc, found := e.Regions[id]; found {
+		return rc
+	}
+	return e.Default
+}
+
+// SetPolicy sets the Router used for calls originating at an exchange, nil restoring the default
+func (e *Engine) SetPolicy(exchange network.NodeID, r Router) {
+	if r == nil {
+		delete(e.Policies, exchange)
+		return
+	}
+	e.Policies[exchange] = r
+}
+
+// GetPolicy returns the Router used for calls originating at an exchange
+func (e *Engine) GetPolicy(exchange network.NodeID) Router {
+	if r, found := e.Policies[exchange]; found {
+		return r
+	}
+	return e.Router
+}
+
+// getCallStats returns the Stats a call counts towards: the totals, its home exchange's and its region's
+func (e *Engine) getCallStats(home, region network.NodeID) []*Stats {
+	hs, found := e.exchangeStats[home]
+	if !found {
+		hs = &Stats{}
+		e.exchangeStats[home] = hs
+	}
+	rs, found := e.regionStats[region]
+	if !found {
+		rs = &Stats{}
+		e.regionStats[region] = rs
+	}
+	return []*Stats{&e.Stats, hs, rs}
+}
+
+// GetExchangeStats returns the outcome of the calls originating at an exchange
+func (e *Engine) GetExchangeStats(exchange network.NodeID) Stats {
+	if s, found := e.exchangeStats[exchange]; found {
+		return *s
+	}
+	return Stats{}
+}
+
+// GetRegionStats returns the outcome of the calls placed by a Subscriber node
+func (e *Engine) GetRegionStats(region network.NodeID) Stats {
+	if s, found := e.regionStats[region]; found {
+		return *s
+	}
+	return Stats{}
+}
+
+// getHomeExchange returns the first exchange or tower a Subscriber node is linked to, or 0
+func (e *Engine) getHomeExchange(id network.NodeID) network.NodeID {
+	for _, link := range e.Network.GetLinksOf(id) {
+		other := e.Network.GetNode(link.Other(id))
+		if other.Type != network.Subscriber {
+			return other.ID
+		}
+	}
+	return 0
+}
+
+// Update simulates dt seconds, processing every call arrival and completion in order
+func (e *Engine) Update(dt float64) {
+	end := e.Time + dt
+
+	e.dropBrokenCalls()
+
+	regions := e.Network.GetNodesOfType(network.Subscriber)
+	arrivals := e.getArrivals(regions)
+	for {
+		// The next event is whichever comes first of a call ending or a call arriving
+		var region *network.Node
+		next := math.Inf(1)
+		if len(arrivals) > 0 {
+			next = arrivals[0].Time
+			region = arrivals[0].Region
+		}
+
+		if len(e.active) > 0 && e.active[0].End <= next {
+			if e.active[0].End > end {
+				break
+			}
+			c := heap.Pop(&e.active).(*Call)
+			e.Time = c.End
+			e.release(c)
+			for _, s := range e.getCallStats(c.Home, c.From) {
+				s.Completed++
+				s.CallSeconds += c.End - c.Start
+			}
+			continue
+		}
+
+		if region == nil || next > end {
+			break
+		}
+
+		e.Time = next
+		e.scheduleArrival(region)
+		arrivals[0].Time = e.nextArrival[region.ID]
+		heap.Fix(&arrivals, 0)
+		e.placeCall(region, regions)
+	}
+
+	e.Time = end
+}
+
+// getArrivals returns the next arrival of every region placing calls, as a container/heap
+func (e *Engine) getArrivals(regions []*network.Node) arrivalQueue {
+	q := make(arrivalQueue, 0, len(regions))
+	for i, r := range regions {
+		// Regions placing no calls are checked again, in case they gained lines
+		if t, found := e.nextArrival[r.ID]; !found || math.IsInf(t, 1) {
+			e.scheduleArrival(r)
+		}
+		if t := e.nextArrival[r.ID]; !math.IsInf(t, 1) {
+			q = append(q, arrival{Time: t, Region: r, Order: i})
+		}
+	}
+	heap.Init(&q)
+	return q
+}
+
+func (e *Engine) scheduleArrival(region *network.Node) {
+	rc := e.GetRegionConfig(region.ID)
+	rate := float64(region.Subscribers) * rc.CallsPerLineHour / 3600
+	if rate <= 0 {
+		e.nextArrival[region.ID] = math.Inf(1)
+		return
+	}
+	e.nextArrival[region.ID] = e.Time + e.Rand.ExpFloat64()/rate
+}
+
+// placeCall attempts a call from region to another region, chosen in proportion to their lines
+func (e *Engine) placeCall(from *network.Node, regions []*network.Node) {
+	total := 0
+	for _, r := range regions {
+		if r.ID != from.ID {
+			total += r.Subscribers
+		}
+	}
+	if total == 0 {
+		return
+	}
+
+	var to *network.Node
+	pick := e.Rand.Intn(total)
+	for _, r := range regions {
+		if r.ID == from.ID {
+			continue
+		}
+		pick -= r.Subscribers
+		if pick < 0 {
+			to = r
+			break
+		}
+	}
+
+	home := e.getHomeExchange(from.ID)
+	stats := e.getCallStats(home, from.ID)
+	for _, s := range stats {
+		s.Attempted++
+	}
+
+	rc := e.GetRegionConfig(from.ID)
+	e.offered = map[network.LinkID]bool{}
+	route := e.GetPolicy(home).Route(e.Network, e, from.ID, to.ID)
+	e.offer(route, rc.HoldingTime)
+
+	if route == nil {
+		for _, s := range stats {
+			s.Blocked++
+		}
+		e.Events.Publish(events.CallBlocked{From: from.ID, To: to.ID, Home: home, Time: e.Time})
+		return
+	}
+	for _, s := range stats {
+		s.Crankbacks += int64(route.Crankbacks)
+	}
+
+	c := &Call{
+		ID:    e.nextCallID,
+		From:  from.ID,
+		To:    to.ID,
+		Home:  home,
+		Links: route.Links,
+		Nodes: route.Nodes,
+		Start: e.Time,
+		End:   e.Time + e.Rand.ExpFloat64()*rc.HoldingTime,
+	}
+	e.nextCallID++
+
+	e.seize(c)
+	heap.Push(&e.active, c)
+}
+
+// offer counts a call as offered to every Link it was routed over or tried to
+// seize during setup, once each, ending the routing of the call
+func (e *Engine) offer(route *Route, holdingTime float64) {
+	offered := e.offered
+	e.offered = nil
+	if route != nil {
+		for _, id := range route.Links {
+			offered[id] = true
+		}
+	}
+	for id := range offered {
+		ls := e.getLinkStats(id)
+		ls.Offered++
+		ls.OfferedSeconds += holdingTime
+	}
+}
+
+// GetFreeLinkCapacity returns the number of calls a Link can still carry, 0 while it is down
+func (e *Engine) GetFreeLinkCapacity(id network.LinkID) int {
+	link := e.Network.GetLink(id)
+	if link == nil || link.Down {
+		return 0
+	}
+	return link.Capacity - e.getLinkStats(id).Used
+}
+
+// Offer records that the call being routed tried to seize a circuit on a Link
+func (e *Engine) Offer(id network.LinkID) {
+	if e.offered != nil {
+		e.offered[id] = true
+	}
+}
+
+// GetFreeNodeCapacity returns the number of calls a Node can still switch, or
+// for a Subscriber node the number of idle lines, 0 while it is down
+func (e *Engine) GetFreeNodeCapacity(id network.NodeID) int {
+	node := e.Network.GetNode(id)
+	if node == nil || node.Down {
+		return 0
+	}
+	capacity := node.Capacity
+	if node.Type == network.Subscriber {
+		capacity = node.Subscribers
+	}
+	return capacity - e.nodeUsed[id]
+}
+
+func (e *Engine) seize(c *Call) {
+	for _, id := range c.Links {
+		ls := e.getLinkStats(id)
+		e.accumulate(ls)
+		ls.Used++
+		ls.Carried++
+		if ls.Used > ls.Peak {
+			ls.Peak = ls.Used
+		}
+		if link := e.Network.GetLink(id); link != nil && ls.Used == link.Capacity {
+			e.Events.Publish(events.LinkSaturated{Link: id, Capacity: link.Capacity, Time: e.Time})
+		}
+	}
+	for _, id := range c.Nodes {
+		e.nodeUsed[id]++
+	}
+}
+
+func (e *Engine) release(c *Call) {
+	for _, id := range c.Links {
+		ls := e.getLinkStats(id)
+		e.accumulate(ls)
+		ls.Used--
+	}
+	for _, id := range c.Nodes {
+		e.nodeUsed[id]--
+		if e.nodeUsed[id] <= 0 {
+			delete(e.nodeUsed, id)
+		}
+	}
+}
+
+// dropBrokenCalls ends the active Calls whose route lost a Link or Node, or has one down
+func (e *Engine) dropBrokenCalls() {
+	kept := e.active[:0]
+	dropped := []*Call{}
+	for _, c := range e.active {
+		if e.isRouteIntact(c) {
+			kept = append(kept, c)
+		} else {
+			dropped = append(dropped, c)
+		}
+	}
+	if len(dropped) == 0 {
+		return
+	}
+
+	e.active = kept
+	heap.Init(&e.active)
+	for _, c := range dropped {
+		e.release(c)
+		for _, s := range e.getCallStats(c.Home, c.From) {
+			s.Dropped++
+		}
+	}
+}
+
+func (e *Engine) isRouteIntact(c *Call) bool {
+	for _, id := range c.Links {
+		if link := e.Network.GetLink(id); link == nil || link.Down {
+			return false
+		}
+	}
+	for _, id := range c.Nodes {
+		if node := e.Network.GetNode(id); node == nil || node.Down {
+			return false
+		}
+	}
+	return true
+}
+
+// accumulate adds the busy time of a Link since its last change
+func (e *Engine) accumulate(ls *LinkStats) {
+	ls.BusySeconds += float64(ls.Used) * (e.Time - ls.LastChange)
+	ls.LastChange = e.Time
+}
+
+func (e *Engine) getLinkStats(id network.LinkID) *LinkStats {
+	ls, found := e.linkStats[id]
+	if !found {
+		ls = &LinkStats{LastChange: e.Time}
+		e.linkStats[id] = ls
+	}
+	return ls
+}
+
+// GetActiveCalls returns the Calls in progress
+func (e *Engine) GetActiveCalls() []*Call {
+	return append([]*Call{}, e.active...)
+}
+
+// GetLinkStats returns the usage of a Link
+func (e *Engine) GetLinkStats(id network.LinkID) LinkStats {
+	ls := *e.getLinkStats(id)
+	e.accumulate(&ls)
+	return ls
+}
+
+// GetUtilization returns the fraction of a Link's capacity in use now
+func (e *Engine) GetUtilization(id network.LinkID) float64 {
+	link := e.Network.GetLink(id)
+	if link == nil || link.Capacity == 0 {
+		return 0
+	}
+	return float64(e.getLinkStats(id).Used) / float64(link.Capacity)
+}
+
+// GetCarriedTraffic returns the mean erlangs a Link has carried since the start
+func (e *Engine) GetCarriedTraffic(id network.LinkID) float64 {
+	if e.Time <= 0 {
+		return 0
+	}
+	return e.GetLinkStats(id).BusySeconds / e.Time
+}
+
+// GetOfferedLinkTraffic returns the mean erlangs offered to a Link since the start,
+// the calls offered to it times their mean holding time over the time elapsed
+func (e *Engine) GetOfferedLinkTraffic(id network.LinkID) float64 {
+	if e.Time <= 0 {
+		return 0
+	}
+	return e.getLinkStats(id).OfferedSeconds / e.Time
+}
+
+// GetGradeOfService returns the Erlang B blocking probability of a Link at the
+// traffic offered to it. The traffic it carried would understate it, as that
+// leaves out the calls it blocked.
+func (e *Engine) GetGradeOfService(id network.LinkID) float64 {
+	link := e.Network.GetLink(id)
+	if link == nil {
+		return 0
+	}
+	return ErlangB(e.GetOfferedLinkTraffic(id), link.Capacity)
+}
+
+// GetOfferedTraffic returns the erlangs the Subscriber nodes offer in total
+func (e *Engine) GetOfferedTraffic() float64 {
+	total := 0.0
+	for _, r := range e.Network.GetNodesOfType(network.Subscriber) {
+		rc := e.GetRegionConfig(r.ID)
+		total += float64(r.Subscribers) * rc.CallsPerLineHour / 3600 * rc.HoldingTime
+	}
+	return total
+}
+
+// engineJSON is the saved form of an Engine, Routers are saved by name
+type engineJSON struct {
+	Rand     *rng.Rand
+	Default  RegionConfig
+	Regions  map[network.NodeID]RegionConfig
+	Router   string
+	Policies map[network.NodeID]string
+
+	Stats         Stats
+	Time          float64
+	Active        []*Call
+	NextCallID    uint64
+	NextArrival   map[network.NodeID]float64
+	LinkStats     map[network.LinkID]*LinkStats
+	ExchangeStats map[network.NodeID]*Stats
+	RegionStats   map[network.NodeID]*Stats
+}
+
+// MarshalJSON writes everything needed to continue the simulation exactly, except the Network
+func (e *Engine) MarshalJSON() ([]byte, error) {
+	ej := engineJSON{
+		Rand:          e.Rand,
+		Default:       e.Default,
+		Regions:       e.Regions,
+		Router:        e.Router.GetName(),
+		Policies:      map[network.NodeID]string{},
+		Stats:         e.Stats,
+		Time:          e.Time,
+		Active:        e.active,
+		NextCallID:    e.nextCallID,
+		NextArrival:   map[network.NodeID]float64{},
+		LinkStats:     e.linkStats,
+		ExchangeStats: e.exchangeStats,
+		RegionStats:   e.regionStats,
+	}
+	for id, r := range e.Policies {
+		ej.Policies[id] = r.GetName()
+	}
+	// Regions placing no calls are never due, and JSON has no infinity
+	for id, t := range e.nextArrival {
+		if !math.IsInf(t, 1) {
+			ej.NextArrival[id] = t
+		}
+	}
+	return json.Marshal(ej)
+}
+
+// UnmarshalJSON replaces the Engine with one written by MarshalJSON. The Network
+// is not saved with the Engine, so it must be set before the next Update.
+func (e *Engine) UnmarshalJSON(data []byte) error {
+	ej := engineJSON{}
+	err := json.Unmarshal(data, &ej)
+	if err != nil {
+		return err
+	}
+
+	net, bus := e.Network, e.Events
+	*e = *NewEngine(net, 0)
+	e.Events = bus
+	if ej.Rand != nil {
+		e.Rand = ej.Rand
+	}
+	e.Default = ej.Default
+	if ej.Regions != nil {
+		e.Regions = ej.Regions
+	}
+
+	e.Router, err = NewRouter(ej.Router)
+	if err != nil {
+		return err
+	}
+	for id, name := range ej.Policies {
+		r, err := NewRouter(name)
+		if err != nil {
+			return fmt.Errorf("Invalid policy for exchange [%v]: %v", id, err)
+		}
+		e.Policies[id] = r
+	}
+
+	e.Stats = ej.Stats
+	e.Time = ej.Time
+	e.active = ej.Active
+	heap.Init(&e.active)
+	e.nextCallID = ej.NextCallID
+	if ej.NextArrival != nil {
+		e.nextArrival = ej.NextArrival
+	}
+	if ej.LinkStats != nil {
+		e.linkStats = ej.LinkStats
+	}
+	if ej.ExchangeStats != nil {
+		e.exchangeStats = ej.ExchangeStats
+	}
+	if ej.RegionStats != nil {
+		e.regionStats = ej.RegionStats
+	}
+
+	for _, c := range e.active {
+		for _, id := range c.Nodes {
+			e.nodeUsed[id]++
+		}
+	}
+	return nil
+}
@@ -0,0 +1,150 @@
+package traffic
+
+import (
+	"math"
+	"testing"
+
+	"github.com/WhoBrokeTheBuild/TelcomSim/network"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// TestGradeOfService checks the Erlang B blocking of a congested trunk against
+// the blocking it was seen to cause
+func TestGradeOfService(t *testing.T) {
+	net := network.NewNetwork()
+	a := net.AddExchange("A", mgl32.Vec2{0, 0})
+	b := net.AddExchange("B", mgl32.Vec2{4, 0})
+	sa := net.AddSubscriber("SA", mgl32.Vec2{0, 1}, 150)
+	sb := net.AddSubscriber("SB", mgl32.Vec2{4, 1}, 150)
+	trunk, _ := net.Connect(a.ID, b.ID, network.Copper)
+	net.Connect(sa.ID, a.ID, network.Fiber)
+	net.Connect(sb.ID, b.ID, network.Fiber)
+
+	// Every call crosses the trunk, offering it 30 erlangs over 24 circuits
+	e := NewEngine(net, 1)
+	e.Update(10 * 3600)
+
+	offered := e.GetOfferedTraffic()
+	if got := e.GetOfferedLinkTraffic(trunk.ID); math.Abs(got-offered)/offered > 0.05 {
+		t.Errorf("GetOfferedLinkTraffic() = %.2f, want about %.2f", got, offered)
+	}
+
+	seen := e.Stats.GetBlockingRate()
+	if got := e.GetGradeOfService(trunk.ID); math.Abs(got-seen) > 0.03 {
+		t.Errorf("GetGradeOfService() = %.3f, want about the %.3f seen", got, seen)
+	}
+	if carried := ErlangB(e.GetCarriedTraffic(trunk.ID), trunk.Capacity); carried >= seen-0.03 {
+		t.Errorf("blocking at the carried traffic %.3f should understate the %.3f seen", carried, seen)
+	}
+}
+
+// TestOfferedLinks checks that calls are only counted as offered to the Links
+// they were routed over or tried to seize. A and B are joined by a direct trunk
+// and by a side route through C, whose trunk to A is saturated.
+func TestOfferedLinks(t *testing.T) {
+	tests := []struct {
+		name   string
+		router Router
+		direct network.LinkType
+		// sideBlocked is whether every blocked call was refused by the side trunk
+		sideBlocked bool
+	}{
+		{name: "shortest path", router: NewShortestPath(ByLatency), direct: network.Fiber},
+		{name: "least loaded", router: NewLeastLoaded(), direct: network.Fiber},
+		{name: "least loaded congested", router: NewLeastLoaded(), direct: network.Copper},
+		{name: "alternate", router: NewAlternate(), direct: network.Fiber},
+		{name: "alternate congested", router: NewAlternate(), direct: network.Copper, sideBlocked: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			net := network.NewNetwork()
+			a := net.AddExchange("A", mgl32.Vec2{0, 0})
+			b := net.AddExchange("B", mgl32.Vec2{4, 0})
+			c := net.AddExchange("C", mgl32.Vec2{2, 3})
+			sa := net.AddSubscriber("SA", mgl32.Vec2{0, 1}, 150)
+			sb := net.AddSubscriber("SB", mgl32.Vec2{4, 1}, 150)
+			direct, _ := net.Connect(a.ID, b.ID, tt.direct)
+			side, _ := net.Connect(a.ID, c.ID, network.Copper)
+			side.Capacity = 0
+			net.Connect(c.ID, b.ID, network.Fiber)
+			net.Connect(sa.ID, a.ID, network.Fiber)
+			net.Connect(sb.ID, b.ID, network.Fiber)
+
+			e := NewEngine(net, 1)
+			e.Router = tt.router
+			e.Update(3600)
+
+			if got := e.GetLinkStats(direct.ID).Offered; got != e.Stats.Attempted {
+				t.Errorf("direct trunk offered %v calls, want all %v attempted", got, e.Stats.Attempted)
+			}
+			want := int64(0)
+			if tt.sideBlocked {
+				want = e.Stats.Blocked
+				if want == 0 {
+					t.Fatalf("no calls were blocked on the congested direct trunk")
+				}
+			}
+			if got := e.GetLinkStats(side.ID).Offered; got != want {
+				t.Errorf("side trunk offered %v calls, want %v", got, want)
+			}
+		})
+	}
+}
+
+// newSeedNetwork returns three exchanges in a line with a region each, the
+// trunks between them congested enough to block
+func newSeedNetwork() *network.Network {
+	net := network.NewNetwork()
+	exchanges := []*network.Node{
+		net.AddExchange("A", mgl32.Vec2{0, 0}),
+		net.AddExchange("B", mgl32.Vec2{4, 0}),
+		net.AddExchange("C", mgl32.Vec2{8, 0}),
+	}
+	net.Connect(exchanges[0].ID, exchanges[1].ID, network.Copper)
+	net.Connect(exchanges[1].ID, exchanges[2].ID, network.Copper)
+	for i, x := range exchanges {
+		region := net.AddSubscriber(x.Name+"1", x.Position.Add(mgl32.Vec2{0, 1}), 100+50*i)
+		net.Connect(region.ID, x.ID, network.Fiber)
+	}
+	return net
+}
+
+// TestSameSeed checks that Engines with the same seed play out the same calls,
+// whatever steps they are updated in
+func TestSameSeed(t *testing.T) {
+	a := NewEngine(newSeedNetwork(), 7)
+	b := NewEngine(newSeedNetwork(), 7)
+	other := NewEngine(newSeedNetwork(), 8)
+	for i := 0; i < 120; i++ {
+		a.Update(30)
+		other.Update(30)
+	}
+	for i := 0; i < 12; i++ {
+		b.Update(300)
+	}
+
+	if a.Stats.Attempted == 0 || a.Stats.Blocked == 0 {
+		t.Fatalf("Stats = %+v, want calls both completed and blocked", a.Stats)
+	}
+	if a.Stats != b.Stats {
+		t.Errorf("Stats = %+v and %+v, want the same for the same seed", a.Stats, b.Stats)
+	}
+	if a.Stats == other.Stats {
+		t.Errorf("Stats = %+v for seeds 7 and 8, want them to differ", a.Stats)
+	}
+
+	for _, n := range a.Network.GetNodesOfType(network.Subscriber) {
+		if sa, sb := a.GetRegionStats(n.ID), b.GetRegionStats(n.ID); sa != sb {
+			t.Errorf("%v: GetRegionStats() = %+v and %+v, want the same", n.Name, sa, sb)
+		}
+	}
+	for _, l := range a.Network.GetLinks() {
+		if la, lb := a.GetLinkStats(l.ID), b.GetLinkStats(l.ID); la != lb {
+			t.Errorf("link %v: GetLinkStats() = %+v and %+v, want the same", l.ID, la, lb)
+		}
+	}
+	if na, nb := len(a.GetActiveCalls()), len(b.GetActiveCalls()); na != nb {
+		t.Errorf("%d and %d active calls, want the same", na, nb)
+	}
+}
@@ -0,0 +1,35 @@
+package traffic
+
+// ErlangB returns the probability a call is blocked when traffic erlangs are
+// offered to the given number of circuits
+func ErlangB(traffic float64, circuits int) float64 {
+	if traffic <= 0 {
+		return 0
+	}
+	if circuits <= 0 {
+		return 1
+	}
+
+	// The recursive form avoids the factorials of the closed form
+	b := 1.0
+	for m := 1; m <= circuits; m++ {
+		b = traffic * b / (float64(m) + traffic*b)
+	}
+	return b
+}
+
+// RequiredCircuits returns the fewest circuits that carry traffic erlangs with
+// at most the given blocking probability
+func RequiredCircuits(traffic, blocking float64) int {
+	if traffic <= 0 {
+		return 0
+	}
+
+	b := 1.0
+	m := 0
+	for b > blocking {
+		m++
+		b = traffic * b / (float64(m) + traffic*b)
+	}
+	return m
+}
@@ -0,0 +1,57 @@
+package traffic
+
+import (
+	"math"
+	"testing"
+)
+
+func TestErlangB(t *testing.T) {
+	tests := []struct {
+		traffic  float64
+		circuits int
+		want     float64
+	}{
+		{traffic: 10, circuits: 10, want: 0.2146},
+		{traffic: 1, circuits: 1, want: 0.5},
+		{traffic: 5, circuits: 10, want: 0.0184},
+		{traffic: 2, circuits: 5, want: 0.0367},
+		{traffic: 30, circuits: 24, want: 0.2709},
+		{traffic: 100, circuits: 100, want: 0.0757},
+		{traffic: 0, circuits: 10, want: 0},
+		{traffic: 5, circuits: 0, want: 1},
+	}
+
+	for _, tt := range tests {
+		if got := ErlangB(tt.traffic, tt.circuits); math.Abs(got-tt.want) > 5e-5 {
+			t.Errorf("ErlangB(%v, %d) = %.4f, want %.4f", tt.traffic, tt.circuits, got, tt.want)
+		}
+	}
+}
+
+func TestRequiredCircuits(t *testing.T) {
+	tests := []struct {
+		traffic  float64
+		blocking float64
+		want     int
+	}{
+		{traffic: 1, blocking: 0.01, want: 5},
+		{traffic: 5, blocking: 0.01, want: 11},
+		{traffic: 10, blocking: 0.01, want: 18},
+		{traffic: 30, blocking: 0.01, want: 42},
+		{traffic: 100, blocking: 0.01, want: 117},
+		{traffic: 10, blocking: 0.001, want: 21},
+		{traffic: 10, blocking: 0.05, want: 15},
+		{traffic: 0, blocking: 0.01, want: 0},
+	}
+
+	for _, tt := range tests {
+		got := RequiredCircuits(tt.traffic, tt.blocking)
+		if got != tt.want {
+			t.Errorf("RequiredCircuits(%v, %v) = %d, want %d", tt.traffic, tt.blocking, got, tt.want)
+		}
+		// The fewest circuits, so one less would block too much
+		if tt.traffic > 0 && (ErlangB(tt.traffic, got) > tt.blocking || ErlangB(tt.traffic, got-1) <= tt.blocking) {
+			t.Errorf("RequiredCircuits(%v, %v) = %d isn't the fewest within the blocking", tt.traffic, tt.blocking, got)
+		}
+	}
+}
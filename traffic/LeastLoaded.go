@@ -0,0 +1,121 @@
+package traffic
+
+import (
+	"math"
+	"sort"
+
+	"github.com/WhoBrokeTheBuild/TelcomSim/network"
+)
+
+// LeastLoaded is a Router choosing the Route whose busiest Link has the most
+// free capacity, spreading calls away from congested trunks
+type LeastLoaded struct {
+	// MaxHops limits how far a call may detour, 0 meaning unlimited
+	MaxHops int
+}
+
+// NewLeastLoaded returns a new LeastLoaded Router
+func NewLeastLoaded() *LeastLoaded {
+	return &LeastLoaded{
+		MaxHops: 6,
+	}
+}
+
+// GetName returns the name NewRouter creates the Router from
+func (ll *LeastLoaded) GetName() string {
+	return "least_loaded"
+}
+
+// Route returns the widest Route within MaxHops, preferring fewer hops between
+// equally wide ones
+func (ll *LeastLoaded) Route(net *network.Network, usage Usage, from, to network.NodeID) *Route {
+	if usage.GetFreeNodeCapacity(from) <= 0 || usage.GetFreeNodeCapacity(to) <= 0 {
+		return nil
+	}
+
+	maxHops := ll.MaxHops
+	if maxHops <= 0 {
+		maxHops = len(net.GetNodes()) - 1
+	}
+
+	// The search relaxes by hops first and width second: layers[h] holds the
+	// widest way of reaching each Node in exactly h hops. Keeping only the widest
+	// arrival at each Node would prune a narrower path that fits within MaxHops.
+	layers := []map[network.NodeID]hopState{{from: {width: math.MaxInt32}}}
+	best := 0
+	for h := 1; h <= maxHops && len(layers[h-1]) > 0; h++ {
+		prev := layers[h-1]
+		next := map[network.NodeID]hopState{}
+		for _, id := range getSortedIDs(prev) {
+			if id == to {
+				continue
+			}
+			for _, link := range net.GetLinksOf(id) {
+				other := link.Other(id)
+				if other == from || !canEnter(net, usage, link, other, to) {
+					continue
+				}
+
+				w := usage.GetFreeLinkCapacity(link.ID)
+				if prev[id].width < w {
+					w = prev[id].width
+				}
+				if old, found := next[other]; !found || w > old.width {
+					next[other] = hopState{width: w, via: link.ID}
+				}
+			}
+		}
+		layers = append(layers, next)
+
+		if s, found := next[to]; found && (best == 0 || s.width > layers[best][to].width) {
+			best = h
+		}
+	}
+
+	if best > 0 {
+		// The fewest hops reaching the widest width never pass through a Node twice
+		r := &Route{
+			Links: []network.LinkID{},
+			Nodes: []network.NodeID{to},
+		}
+		id := to
+		for h := best; h > 0; h-- {
+			link := net.GetLink(layers[h][id].via)
+			r.Links = append(r.Links, link.ID)
+			id = link.Other(id)
+			r.Nodes = append(r.Nodes, id)
+		}
+		reverseLinks(r.Links)
+		reverseNodes(r.Nodes)
+		return r
+	}
+
+	// The search only looks at the trunks, so a blocked call is counted as
+	// refused by those of the route it would take with the network idle
+	if r := NewShortestPath(ByHops).findPath(net, from, to); r != nil {
+		for _, id := range r.Links {
+			usage.Offer(id)
+		}
+	}
+	return nil
+}
+
+// hopState is the widest way a LeastLoaded search reached a Node in some number of hops
+type hopState struct {
+	// width is the free capacity of the busiest Link on the way
+	width int
+	// via is the last Link on the way
+	via network.LinkID
+}
+
+// getSortedIDs returns the Nodes of a search layer in order, so searches are deterministic
+func getSortedIDs(layer map[network.NodeID]hopState) []network.NodeID {
+	ids := make([]network.NodeID, 0, len(layer))
+	for id := range layer {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		return ids[i] < ids[j]
+	})
+	return ids
+}
@@ -0,0 +1,113 @@
+package traffic
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/WhoBrokeTheBuild/TelcomSim/network"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// freeUsage has the free circuits of each Link in free
+type freeUsage struct {
+	free map[network.LinkID]int
+}
+
+func (u freeUsage) GetFreeLinkCapacity(id network.LinkID) int {
+	return u.free[id]
+}
+
+func (u freeUsage) GetFreeNodeCapacity(id network.NodeID) int {
+	return 1
+}
+
+func (u freeUsage) Offer(id network.LinkID) {
+}
+
+// TestLeastLoadedMaxHops routes from A to Z through M, which A reaches over a
+// narrow direct trunk or a wide detour through B and C
+func TestLeastLoadedMaxHops(t *testing.T) {
+	tests := []struct {
+		name    string
+		maxHops int
+		want    []string
+	}{
+		{
+			name: "takes the widest route without a limit",
+			want: []string{"A", "B", "C", "M", "Z"},
+		},
+		{
+			name:    "takes the widest route within the limit",
+			maxHops: 4,
+			want:    []string{"A", "B", "C", "M", "Z"},
+		},
+		{
+			// The widest way to M already uses up the hops
+			name:    "takes a narrower route when the widest is too long",
+			maxHops: 3,
+			want:    []string{"A", "M", "Z"},
+		},
+		{
+			name:    "blocks when no route fits",
+			maxHops: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			net := network.NewNetwork()
+			nodes := map[string]*network.Node{}
+			for _, n := range []struct {
+				name string
+				pos  mgl32.Vec2
+			}{
+				{"A", mgl32.Vec2{0, 0}},
+				{"B", mgl32.Vec2{2, 3}},
+				{"C", mgl32.Vec2{4, 3}},
+				{"M", mgl32.Vec2{5, 0}},
+				{"Z", mgl32.Vec2{10, 0}},
+			} {
+				nodes[n.name] = net.AddExchange(n.name, n.pos)
+			}
+
+			usage := freeUsage{free: map[network.LinkID]int{}}
+			for _, l := range []struct {
+				ends string
+				free int
+			}{
+				{"AM", 1},
+				{"AB", 10},
+				{"BC", 10},
+				{"CM", 10},
+				{"MZ", 10},
+			} {
+				link, err := net.Connect(nodes[l.ends[:1]].ID, nodes[l.ends[1:]].ID, network.Fiber)
+				if err != nil {
+					t.Fatal(err)
+				}
+				usage.free[link.ID] = l.free
+			}
+
+			ll := NewLeastLoaded()
+			ll.MaxHops = tt.maxHops
+			r := ll.Route(net, usage, nodes["A"].ID, nodes["Z"].ID)
+
+			if tt.want == nil {
+				if r != nil {
+					t.Fatalf("routed a call that should be blocked")
+				}
+				return
+			}
+			if r == nil {
+				t.Fatalf("blocked a call with a free route through %v", tt.want)
+			}
+			got := []string{}
+			for _, id := range r.Nodes {
+				got = append(got, net.GetNode(id).Name)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("routed through %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,145 @@
+package traffic
+
+import (
+	"fmt"
+
+	"github.com/WhoBrokeTheBuild/TelcomSim/network"
+)
+
+// Usage reports the capacity left in a Network to a Router
+type Usage interface {
+	// GetFreeLinkCapacity returns the number of calls a Link can still carry
+	GetFreeLinkCapacity(id network.LinkID) int
+	// GetFreeNodeCapacity returns the number of calls a Node can still switch
+	GetFreeNodeCapacity(id network.NodeID) int
+	// Offer records that the call being routed tried to seize a circuit on a
+	// Link, whether or not one was free. Links only looked at are not offered.
+	Offer(id network.LinkID)
+}
+
+// Route is the path a call takes, Nodes including both ends
+type Route struct {
+	Links []network.LinkID
+	Nodes []network.NodeID
+	// Crankbacks is the number of times setup backed out of a dead end
+	Crankbacks int
+}
+
+// Router chooses the Route of a call, returning nil when it is blocked
+type Router interface {
+	Route(net *network.Network, usage Usage, from, to network.NodeID) *Route
+	// GetName returns the name NewRouter creates the Router from
+	GetName() string
+}
+
+// RouterNames lists the names NewRouter accepts, in the order they are offered to the player
+var RouterNames = []string{
+	"shortest_latency",
+	"shortest_cost",
+	"shortest_hops",
+	"least_loaded",
+	"alternate",
+}
+
+// NewRouter returns a new Router with default settings by name
+func NewRouter(name string) (Router, error) {
+	switch name {
+	case "shortest_latency":
+		return NewShortestPath(ByLatency), nil
+	case "shortest_cost":
+		return NewShortestPath(ByCost), nil
+	case "shortest_hops":
+		return NewShortestPath(ByHops), nil
+	case "least_loaded":
+		return NewLeastLoaded(), nil
+	case "alternate":
+		return NewAlternate(), nil
+	}
+	return nil, fmt.Errorf("Unknown routing policy [%v]", name)
+}
+
+// tryEnter offers the call to link, returning whether it can pass through into node
+func tryEnter(net *network.Network, usage Usage, link *network.Link, node, to network.NodeID) bool {
+	usage.Offer(link.ID)
+	return canEnter(net, usage, link, node, to)
+}
+
+// canEnter returns whether a call to `to` can pass through link into node
+func canEnter(net *network.Network, usage Usage, link *network.Link, node, to network.NodeID) bool {
+	if usage.GetFreeLinkCapacity(link.ID) <= 0 {
+		return false
+	}
+	if usage.GetFreeNodeCapacity(node) <= 0 {
+		return false
+	}
+	// Calls pass through exchanges and towers, never through other subscribers
+	return node == to || net.GetNode(node).Type != network.Subscriber
+}
+
+// buildRoute follows via back from `to` to `from`, returning the Route in order
+func buildRoute(net *network.Network, via map[network.NodeID]network.LinkID, from, to network.NodeID) *Route {
+	r := &Route{
+		Links: []network.LinkID{},
+		Nodes: []network.NodeID{to},
+	}
+	for id := to; id != from; {
+		link := net.GetLink(via[id])
+		r.Links = append(r.Links, link.ID)
+		id = link.Other(id)
+		r.Nodes = append(r.Nodes, id)
+	}
+	reverseLinks(r.Links)
+	reverseNodes(r.Nodes)
+	return r
+}
+
+func reverseLinks(ids []network.LinkID) {
+	for i, j := 0, len(ids)-1; i < j; i, j = i+1, j-1 {
+		ids[i], ids[j] = ids[j], ids[i]
+	}
+}
+
+func reverseNodes(ids []network.NodeID) {
+	for i, j := 0, len(ids)-1; i < j; i, j = i+1, j-1 {
+		ids[i], ids[j] = ids[j], ids[i]
+	}
+}
+
+// searchQueue is a priority queue of Nodes for Dijkstra style searches, as a container/heap.
+// Ties are broken by hops then ID, so searches are deterministic.
+type searchQueue []searchItem
+
+type searchItem struct {
+	id   network.NodeID
+	key  float64
+	hops int
+}
+
+func (q searchQueue) Len() int {
+	return len(q)
+}
+
+func (q searchQueue) Less(i, j int) bool {
+	if q[i].key != q[j].key {
+		return q[i].key < q[j].key
+	}
+	if q[i].hops != q[j].hops {
+		return q[i].hops < q[j].hops
+	}
+	return q[i].id < q[j].id
+}
+
+func (q searchQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+}
+
+func (q *searchQueue) Push(x interface{}) {
+	*q = append(*q, x.(searchItem))
+}
+
+func (q *searchQueue) Pop() interface{} {
+	old := *q
+	item := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return item
+}
@@ -0,0 +1,108 @@
+package traffic
+
+import (
+	"container/heap"
+
+	"github.com/WhoBrokeTheBuild/TelcomSim/network"
+)
+
+// Weight is what a ShortestPath Router minimizes
+type Weight int
+
+const (
+	// ByLatency minimizes the total latency of the Links
+	ByLatency Weight = iota
+	// ByCost minimizes the total cost of the Links
+	ByCost
+	// ByHops minimizes the number of Links
+	ByHops
+)
+
+// ShortestPath is a Router using Dijkstra's algorithm. Like fixed routing in a
+// real network it ignores load, so a call is blocked when any trunk on the
+// shortest path is full, even if a longer path is free. Equipment that is down
+// is left out, as routing tables are updated when a failure is reported.
+type ShortestPath struct {
+	Weight Weight
+}
+
+// NewShortestPath returns a new ShortestPath Router minimizing weight
+func NewShortestPath(weight Weight) *ShortestPath {
+	return &ShortestPath{Weight: weight}
+}
+
+// GetName returns the name NewRouter creates the Router from
+func (sp *ShortestPath) GetName() string {
+	switch sp.Weight {
+	case ByCost:
+		return "shortest_cost"
+	case ByHops:
+		return "shortest_hops"
+	}
+	return "shortest_latency"
+}
+
+// Route returns the cheapest Route by Weight, or nil if it has no free capacity
+func (sp *ShortestPath) Route(net *network.Network, usage Usage, from, to network.NodeID) *Route {
+	if usage.GetFreeNodeCapacity(from) <= 0 || usage.GetFreeNodeCapacity(to) <= 0 {
+		return nil
+	}
+
+	r := sp.findPath(net, from, to)
+	if r == nil {
+		return nil
+	}
+	// The call seizes each trunk in turn, and is refused by the first one that is full
+	for i, id := range r.Links {
+		if !tryEnter(net, usage, net.GetLink(id), r.Nodes[i+1], to) {
+			return nil
+		}
+	}
+	return r
+}
+
+// findPath returns the cheapest Route by Weight ignoring load, or nil if there is none
+func (sp *ShortestPath) findPath(net *network.Network, from, to network.NodeID) *Route {
+	dist := map[network.NodeID]float64{from: 0}
+	via := map[network.NodeID]network.LinkID{}
+	done := map[network.NodeID]bool{}
+
+	queue := &searchQueue{{id: from}}
+	for queue.Len() > 0 {
+		item := heap.Pop(queue).(searchItem)
+		if done[item.id] {
+			continue
+		}
+		done[item.id] = true
+		if item.id == to {
+			return buildRoute(net, via, from, to)
+		}
+
+		for _, link := range net.GetLinksOf(item.id) {
+			other := link.Other(item.id)
+			if done[other] || link.Down || net.GetNode(other).Down ||
+				(other != to && net.GetNode(other).Type == network.Subscriber) {
+				continue
+			}
+
+			d := item.key + sp.getWeight(link)
+			if old, found := dist[other]; !found || d < old {
+				dist[other] = d
+				via[other] = link.ID
+				heap.Push(queue, searchItem{id: other, key: d, hops: item.hops + 1})
+			}
+		}
+	}
+
+	return nil
+}
+
+func (sp *ShortestPath) getWeight(link *network.Link) float64 {
+	switch sp.Weight {
+	case ByCost:
+		return float64(link.Cost)
+	case ByHops:
+		return 1
+	}
+	return float64(link.Latency)
+}
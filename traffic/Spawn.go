@@ -0,0 +1,77 @@
+package traffic
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/WhoBrokeTheBuild/TelcomSim/network"
+	"github.com/WhoBrokeTheBuild/TelcomSim/terrain"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// SpawnConfig controls how Subscriber nodes are placed from a Map's population
+type SpawnConfig struct {
+	// BlockCells is the number of cells across the square block each Subscriber node stands for
+	BlockCells int
+	// LinesPerPerson is the share of people who would take a line
+	LinesPerPerson float64
+	// MinDemand is the fewest lines a block needs to get a Subscriber node
+	MinDemand int
+}
+
+// DefaultSpawnConfig is used for Maps without a SpawnConfig of their own
+var DefaultSpawnConfig = SpawnConfig{
+	BlockCells:     8,
+	LinesPerPerson: 0.3,
+	MinDemand:      10,
+}
+
+// SpawnSubscribers adds a Subscriber node with no lines for each block of the
+// Map with enough people, at the center of its population, and returns them.
+// Their Demand comes from the population, and GrowSubscribers fills it in.
+func SpawnSubscribers(net *network.Network, m *terrain.Map, cfg SpawnConfig) []*network.Node {
+	if cfg.BlockCells <= 0 {
+		cfg.BlockCells = DefaultSpawnConfig.BlockCells
+	}
+
+	spawned := []*network.Node{}
+	for bz := 0; bz < m.Depth; bz += cfg.BlockCells {
+		for bx := 0; bx < m.Width; bx += cfg.BlockCells {
+			people := float32(0)
+			center := mgl32.Vec2{}
+			for z := bz; z < bz+cfg.BlockCells && z < m.Depth; z++ {
+				for x := bx; x < bx+cfg.BlockCells && x < m.Width; x++ {
+					p := m.GetCellPopulation(x, z)
+					people += p
+					center = center.Add(m.GetCellCenter(x, z).Mul(p))
+				}
+			}
+
+			demand := int(float64(people) * cfg.LinesPerPerson)
+			if demand < cfg.MinDemand || demand <= 0 {
+				continue
+			}
+
+			name := fmt.Sprintf("Block %d-%d", bx/cfg.BlockCells, bz/cfg.BlockCells)
+			node := net.AddSubscriber(name, center.Mul(1/people), 0)
+			node.Demand = demand
+			spawned = append(spawned, node)
+		}
+	}
+	return spawned
+}
+
+// GrowSubscribers adds a share of the missing lines to every connected
+// Subscriber node below its Demand, returning how many were added
+func (e *Engine) GrowSubscribers(share float64) int {
+	added := 0
+	for _, node := range e.Network.GetNodesOfType(network.Subscriber) {
+		if node.Subscribers >= node.Demand || e.getHomeExchange(node.ID) == 0 {
+			continue
+		}
+		n := int(math.Ceil(float64(node.Demand-node.Subscribers) * share))
+		node.Subscribers += n
+		added += n
+	}
+	return added
+}
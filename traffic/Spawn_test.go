@@ -0,0 +1,106 @@
+package traffic
+
+import (
+	"image"
+	"testing"
+
+	"github.com/WhoBrokeTheBuild/TelcomSim/network"
+	"github.com/WhoBrokeTheBuild/TelcomSim/terrain"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// newSpawnMap returns a flat 16x12 Map of half kilometer cells, with 200 people
+// in two cells near the top left, 20 in one near the top right, and 100 in one
+// of the short blocks along the bottom
+func newSpawnMap(t *testing.T) *terrain.Map {
+	population := image.NewGray(image.Rect(0, 0, 16, 12))
+	population.Pix[2*16+1] = 255
+	population.Pix[2*16+3] = 255
+	population.Pix[3*16+10] = 51
+	population.Pix[10*16+12] = 255
+
+	m, err := terrain.NewMap(image.NewGray(image.Rect(0, 0, 16, 12)), population, terrain.MapConfig{
+		CellSize:      0.5,
+		MaxHeight:     1,
+		MaxPopulation: 100,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func TestSpawnSubscribers(t *testing.T) {
+	// block is a Subscriber node expected to be spawned
+	type block struct {
+		name     string
+		position mgl32.Vec2
+		demand   int
+	}
+	tests := []struct {
+		name string
+		cfg  SpawnConfig
+		want []block
+	}{
+		{
+			name: "default",
+			cfg:  DefaultSpawnConfig,
+			want: []block{
+				{name: "Block 0-0", position: mgl32.Vec2{1.25, 1.25}, demand: 60},
+				{name: "Block 1-1", position: mgl32.Vec2{6.25, 5.25}, demand: 30},
+			},
+		},
+		{
+			name: "without a block size",
+			cfg:  SpawnConfig{LinesPerPerson: 0.3, MinDemand: 10},
+			want: []block{
+				{name: "Block 0-0", position: mgl32.Vec2{1.25, 1.25}, demand: 60},
+				{name: "Block 1-1", position: mgl32.Vec2{6.25, 5.25}, demand: 30},
+			},
+		},
+		{
+			name: "without a minimum demand",
+			cfg:  SpawnConfig{BlockCells: 8, LinesPerPerson: 0.3},
+			want: []block{
+				{name: "Block 0-0", position: mgl32.Vec2{1.25, 1.25}, demand: 60},
+				{name: "Block 1-0", position: mgl32.Vec2{5.25, 1.75}, demand: 6},
+				{name: "Block 1-1", position: mgl32.Vec2{6.25, 5.25}, demand: 30},
+			},
+		},
+		{
+			name: "small blocks",
+			cfg:  SpawnConfig{BlockCells: 4, LinesPerPerson: 0.5, MinDemand: 10},
+			want: []block{
+				{name: "Block 0-0", position: mgl32.Vec2{1.25, 1.25}, demand: 100},
+				{name: "Block 2-0", position: mgl32.Vec2{5.25, 1.75}, demand: 10},
+				{name: "Block 3-2", position: mgl32.Vec2{6.25, 5.25}, demand: 50},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			net := network.NewNetwork()
+			spawned := SpawnSubscribers(net, newSpawnMap(t), tt.cfg)
+			if len(spawned) != len(tt.want) {
+				t.Fatalf("spawned %d nodes, want %d", len(spawned), len(tt.want))
+			}
+			if n := len(net.GetNodesOfType(network.Subscriber)); n != len(tt.want) {
+				t.Errorf("network has %d Subscriber nodes, want %d", n, len(tt.want))
+			}
+
+			for i, want := range tt.want {
+				node := spawned[i]
+				if node.Name != want.name {
+					t.Errorf("node %d is %q, want %q", i, node.Name, want.name)
+				}
+				if !node.Position.ApproxEqualThreshold(want.position, 1e-5) {
+					t.Errorf("%v is at %v, want the center of its population %v", node.Name, node.Position, want.position)
+				}
+				if node.Demand != want.demand || node.Subscribers != 0 {
+					t.Errorf("%v has demand %d and %d lines, want demand %d and no lines", node.Name, node.Demand, node.Subscribers, want.demand)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package traffic
+
+// Stats counts the outcome of every call attempt
+type Stats struct {
+	Attempted int64
+	Completed int64
+	// Blocked calls found no route with free capacity
+	Blocked int64
+	// Dropped calls lost their route while in progress
+	Dropped int64
+	// Crankbacks counts the times call setup backed out of a dead end
+	Crankbacks int64
+	// CallSeconds is the total length of the completed calls
+	CallSeconds float64
+}
+
+// GetBlockingRate returns the fraction of attempts that were blocked
+func (s Stats) GetBlockingRate() float64 {
+	if s.Attempted == 0 {
+		return 0
+	}
+	return float64(s.Blocked) / float64(s.Attempted)
+}
+
+// Sub returns the difference between two Stats, for the calls in a period
+func (s Stats) Sub(o Stats) Stats {
+	return Stats{
+		Attempted:   s.Attempted - o.Attempted,
+		Completed:   s.Completed - o.Completed,
+		Blocked:     s.Blocked - o.Blocked,
+		Dropped:     s.Dropped - o.Dropped,
+		Crankbacks:  s.Crankbacks - o.Crankbacks,
+		CallSeconds: s.CallSeconds - o.CallSeconds,
+	}
+}
+
+// LinkStats is the usage of one Link
+type LinkStats struct {
+	// Used is the number of calls on the Link now, Peak the most there have been at once
+	Used int
+	Peak int
+	// Carried is the total calls routed over the Link
+	Carried int64
+	// Offered is the total calls offered to the Link, those Carried and those a
+	// Router found it full for, and OfferedSeconds their mean holding times summed
+	Offered        int64
+	OfferedSeconds float64
+
+	// BusySeconds is the integral of Used over time, up to LastChange
+	BusySeconds float64
+	LastChange  float64
+}
@@ -1 +1,102 @@
 package ui
+
+import (
+	"image/color"
+
+	"github.com/WhoBrokeTheBuild/TelcomSim/context"
+	"github.com/WhoBrokeTheBuild/TelcomSim/input"
+	gl "github.com/go-gl/gl/v4.1-core/gl"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// Button is a Component that draws a label on a box of color, and calls
+// OnClick when it is clicked
+type Button struct {
+	BaseComponent
+
+	Label      *Text
+	Background *Image
+	Color      color.Color
+	// Padding is the space between the edges of the Button and its Label
+	Padding mgl32.Vec2
+	OnClick func()
+}
+
+// NewButton returns a new Button sized to fit text in the given font, font size, and colors
+func NewButton(text string, font string, size float64, fg, bg color.Color) *Button {
+	label := NewText(text, font, size, fg)
+	if label == nil {
+		return nil
+	}
+
+	c := &Button{
+		Label:      label,
+		Background: &Image{},
+		Padding:    mgl32.Vec2{6, 4},
+	}
+	c.SetColor(bg)
+	c.SetSize(label.GetSize().Add(c.Padding.Mul(2)))
+	return c
+}
+
+// Delete frees all resources owned by the Button
+func (c *Button) Delete() {
+	c.Label.Delete()
+	c.Background.Delete()
+}
+
+// SetText sets the text of the Label, without resizing the Button
+func (c *Button) SetText(text string) {
+	if text != c.Label.Text {
+		c.Label.SetText(text)
+		c.Label.SetPosition(c.Position.Add(c.Padding))
+	}
+}
+
+// SetColor sets the color of the box behind the Label
+func (c *Button) SetColor(bg color.Color) {
+	if c.Color == bg {
+		return
+	}
+	c.Color = bg
+
+	r, g, b, a := bg.RGBA()
+	pixel := []uint8{uint8(r >> 8), uint8(g >> 8), uint8(b >> 8), uint8(a >> 8)}
+	c.Background.LoadFromData(pixel, gl.RGBA, gl.RGBA, 1, 1)
+	c.Background.SetPosition(c.Position)
+	c.Background.SetSize(c.Size)
+}
+
+// SetPosition sets the Button's position
+func (c *Button) SetPosition(pos mgl32.Vec2) {
+	c.BaseComponent.SetPosition(pos)
+	c.Background.SetPosition(pos)
+	c.Label.SetPosition(pos.Add(c.Padding))
+}
+
+// SetSize sets the Button's size
+func (c *Button) SetSize(size mgl32.Vec2) {
+	c.BaseComponent.SetSize(size)
+	c.Background.SetSize(size)
+}
+
+// Contains returns whether pos is inside the Button
+func (c *Button) Contains(pos mgl32.Vec2) bool {
+	max := c.Position.Add(c.Size)
+	return pos.X() >= c.Position.X() && pos.Y() >= c.Position.Y() &&
+		pos.X() < max.X() && pos.Y() < max.Y()
+}
+
+// Update calls OnClick if the Button was clicked
+func (c *Button) Update(ctx *context.Update) {
+	if c.OnClick != nil && ctx.Input.IsButtonPressed(input.MouseButtonLeft) && c.Contains(ctx.Input.Cursor) {
+		c.OnClick()
+	}
+}
+
+// Draw renders the box and the Label over it
+func (c *Button) Draw(ctx *context.Render) {
+	c.Background.Draw(ctx)
+	gl.Clear(gl.DEPTH_BUFFER_BIT)
+	c.Label.Draw(ctx)
+}
@@ -11,6 +11,11 @@ type Component interface {
 	Delete()
 }
 
+// Updater is implemented by Components that respond to input
+type Updater interface {
+	Update(*context.Update)
+}
+
 // BaseComponent is a stub Component made to be inherited from
 type BaseComponent struct {
 	Position mgl32.Vec2
@@ -0,0 +1,57 @@
+package ui
+
+import (
+	"image/color"
+
+	"github.com/WhoBrokeTheBuild/TelcomSim/context"
+)
+
+// Notice is a Text shown for a while each time it is given a message
+type Notice struct {
+	*Text
+
+	// Duration is the seconds a message is shown for
+	Duration float64
+
+	remaining float64
+}
+
+// NewNotice returns a new hidden Notice in the given font, font size, and color
+func NewNotice(font string, size float64, color color.Color, duration float64) *Notice {
+	// Text can't render an empty string, and isn't drawn until Show is called anyway
+	t := NewText(" ", font, size, color)
+	if t == nil {
+		return nil
+	}
+	return &Notice{
+		Text:     t,
+		Duration: duration,
+	}
+}
+
+// Show shows text for the Notice's Duration, replacing any message shown
+func (c *Notice) Show(text string) {
+	if text != c.Text.Text {
+		c.SetText(text)
+	}
+	c.remaining = c.Duration
+}
+
+// IsShown returns whether a message is being shown
+func (c *Notice) IsShown() bool {
+	return c.remaining > 0
+}
+
+// Update counts down the time left to show the message
+func (c *Notice) Update(ctx *context.Update) {
+	if c.remaining > 0 {
+		c.remaining -= ctx.ElapsedTime
+	}
+}
+
+// Draw renders the message, if it is shown
+func (c *Notice) Draw(ctx *context.Render) {
+	if c.IsShown() {
+		c.Text.Draw(ctx)
+	}
+}
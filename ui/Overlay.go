@@ -112,9 +112,13 @@ func (o *Overlay) Delete() {
 	}
 }
 
-// Update processes all events
+// Update passes the input to every Component that implements Updater
 func (o *Overlay) Update(ctx *context.Update) {
-
+	for _, c := range o.Components {
+		if u, ok := c.(Updater); ok {
+			u.Update(ctx)
+		}
+	}
 }
 
 // Draw renders the current buffer to the screen